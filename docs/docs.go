@@ -15,9 +15,9 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/books": {
+        "/admin/orders": {
             "get": {
-                "description": "Возвращает список книг с фильтрацией, сортировкой и пагинацией, с тайм-аутом на выполнение запроса.",
+                "description": "Возвращает заказы всех пользователей со строками, по умолчанию начиная с последних. Поддерживает фильтры по покупателю, статусу и дате оформления, сортировку и пагинацию.",
                 "consumes": [
                     "application/json"
                 ],
@@ -25,9 +25,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "books"
+                    "orders"
                 ],
-                "summary": "Получение книг с тайм-аутом",
+                "summary": "Заказы магазина",
                 "parameters": [
                     {
                         "type": "integer",
@@ -39,84 +39,72 @@ const docTemplate = `{
                     {
                         "type": "integer",
                         "default": 10,
-                        "description": "Количество книг на странице",
+                        "description": "Количество заказов на странице",
                         "name": "limit",
                         "in": "query"
                     },
                     {
                         "type": "string",
-                        "default": "id",
-                        "description": "Поле для сортировки",
-                        "name": "sort",
+                        "description": "Покупатель",
+                        "name": "username",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Статусы (через запятую или повтором параметра)",
+                        "name": "status",
                         "in": "query"
                     },
                     {
                         "type": "string",
-                        "default": "asc",
-                        "description": "Порядок сортировки",
-                        "name": "order",
+                        "description": "Оформлены начиная с даты (YYYY-MM-DD)",
+                        "name": "from",
                         "in": "query"
                     },
                     {
                         "type": "string",
-                        "description": "Название книги",
-                        "name": "title",
+                        "description": "Оформлены по дату включительно (YYYY-MM-DD)",
+                        "name": "to",
                         "in": "query"
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "total\": int64, \"page\": int, \"limit\": int}",
-                        "schema": {
-                            "$ref": "#/definitions/services.Book"
-                        }
                     },
-                    "408": {
-                        "description": "Request timed out\"}",
-                        "schema": {
-                            "$ref": "#/definitions/models.ErrorResponse"
-                        }
+                    {
+                        "enum": [
+                            "id",
+                            "created_at",
+                            "total",
+                            "status"
+                        ],
+                        "type": "string",
+                        "default": "created_at",
+                        "description": "Поле для сортировки",
+                        "name": "sort",
+                        "in": "query"
                     },
-                    "500": {
-                        "description": "Failed to fetch books\"}",
-                        "schema": {
-                            "$ref": "#/definitions/models.ErrorResponse"
-                        }
-                    }
-                }
-            },
-            "post": {
-                "description": "Создает новую книгу на основе переданных данных.",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "books"
-                ],
-                "summary": "Создание новой книги",
-                "parameters": [
                     {
-                        "description": "Данные книги",
-                        "name": "book",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/services.Book"
-                        }
+                        "enum": [
+                            "asc",
+                            "desc"
+                        ],
+                        "type": "string",
+                        "default": "desc",
+                        "description": "Порядок сортировки",
+                        "name": "order",
+                        "in": "query"
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/services.Book"
+                            "$ref": "#/definitions/models.OrderResponse"
                         }
                     },
                     "400": {
-                        "description": "Invalid request",
+                        "description": "Invalid filter",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
@@ -124,9 +112,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/books/authors/count": {
+        "/authors": {
             "get": {
-                "description": "Возвращает количество книг для каждого автора в базе данных.",
+                "description": "Возвращает список авторов с пагинацией; фильтр по имени учитывает альтернативные написания.",
                 "consumes": [
                     "application/json"
                 ],
@@ -134,25 +122,42 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "books"
+                    "authors"
+                ],
+                "summary": "Получение списка авторов",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Номер страницы",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Количество авторов на странице",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Фильтр по имени",
+                        "name": "name",
+                        "in": "query"
+                    }
                 ],
-                "summary": "Подсчет книг по авторам",
                 "responses": {
                     "200": {
-                        "description": "Количество книг по каждому автору",
+                        "description": "total\": int64, \"page\": int, \"limit\": int",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/models.Order"
-                            }
+                            "$ref": "#/definitions/services.Author"
                         }
                     }
                 }
-            }
-        },
-        "/books/publisher": {
-            "put": {
-                "description": "Обновляет издателя для всех книг в базе данных.",
+            },
+            "post": {
+                "description": "Создает нового автора.",
                 "consumes": [
                     "application/json"
                 ],
@@ -160,27 +165,29 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "books"
+                    "authors"
                 ],
-                "summary": "Обновление издателя для всех книг",
+                "summary": "Создание автора",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Имя издателя",
-                        "name": "publisher",
-                        "in": "query",
-                        "required": true
+                        "description": "Данные автора",
+                        "name": "author",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/services.Author"
+                        }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "Publisher updated successfully",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/models.MessageResponse"
+                            "$ref": "#/definitions/services.Author"
                         }
                     },
-                    "500": {
-                        "description": "Error updating publisher",
+                    "400": {
+                        "description": "Invalid request",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
@@ -188,9 +195,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/books/{id}": {
+        "/authors/{id}": {
             "get": {
-                "description": "Возвращает книгу с указанным идентификатором.",
+                "description": "Возвращает автора и список книг, в которых он участвовал, с указанием роли.",
                 "consumes": [
                     "application/json"
                 ],
@@ -198,13 +205,13 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "books"
+                    "authors"
                 ],
-                "summary": "Получение книги по ID",
+                "summary": "Получение автора по ID",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Идентификатор книги",
+                        "description": "Идентификатор автора",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -214,11 +221,11 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/services.Book"
+                            "$ref": "#/definitions/services.Author"
                         }
                     },
                     "404": {
-                        "description": "Book not found",
+                        "description": "Author not found",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
@@ -226,7 +233,7 @@ const docTemplate = `{
                 }
             },
             "put": {
-                "description": "Обновляет данные книги с указанным идентификатором на основе переданных данных.",
+                "description": "Обновляет имя, альтернативные имена, биографию и годы жизни автора.",
                 "consumes": [
                     "application/json"
                 ],
@@ -234,24 +241,24 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "books"
+                    "authors"
                 ],
-                "summary": "Обновление книги по ID",
+                "summary": "Обновление автора по ID",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Идентификатор книги",
+                        "description": "Идентификатор автора",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Обновленные данные книги",
-                        "name": "book",
+                        "description": "Обновленные данные автора",
+                        "name": "author",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/services.Book"
+                            "$ref": "#/definitions/services.Author"
                         }
                     }
                 ],
@@ -259,7 +266,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/services.Book"
+                            "$ref": "#/definitions/services.Author"
                         }
                     },
                     "400": {
@@ -269,7 +276,7 @@ const docTemplate = `{
                         }
                     },
                     "404": {
-                        "description": "Book not found",
+                        "description": "Author not found",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
@@ -277,7 +284,7 @@ const docTemplate = `{
                 }
             },
             "delete": {
-                "description": "Удаляет книгу с указанным идентификатором.",
+                "description": "Удаляет автора вместе с его связями с книгами.",
                 "consumes": [
                     "application/json"
                 ],
@@ -285,13 +292,13 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "books"
+                    "authors"
                 ],
-                "summary": "Удаление книги по ID",
+                "summary": "Удаление автора по ID",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Идентификатор книги",
+                        "description": "Идентификатор автора",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -299,13 +306,13 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "200": {
-                        "description": "Book deleted",
+                        "description": "Author deleted",
                         "schema": {
-                            "$ref": "#/definitions/models.ErrorResponse"
+                            "$ref": "#/definitions/models.MessageResponse"
                         }
                     },
                     "404": {
-                        "description": "Book not found",
+                        "description": "Author not found",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
@@ -313,143 +320,359 @@ const docTemplate = `{
                 }
             }
         },
-        "/generate-token": {
-            "post": {
-                "description": "Создает JWT-токен с именем пользователя и ролью, срок действия токена составляет 5 минут.",
+        "/books": {
+            "get": {
+                "description": "Возвращает список книг с фильтрацией, сортировкой и пагинацией, с тайм-аутом на выполнение запроса.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
                 "tags": [
-                    "authentication"
+                    "books"
                 ],
-                "summary": "Генерация JWT-токена",
+                "summary": "Получение книг с тайм-аутом",
                 "parameters": [
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Номер страницы",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Количество книг на странице",
+                        "name": "limit",
+                        "in": "query"
+                    },
                     {
                         "type": "string",
-                        "description": "Имя пользователя",
-                        "name": "username",
-                        "in": "query",
-                        "required": true
+                        "default": "id",
+                        "description": "Поле для сортировки",
+                        "name": "sort",
+                        "in": "query"
                     },
                     {
                         "type": "string",
-                        "description": "Роль пользователя",
-                        "name": "role",
-                        "in": "query",
-                        "required": true
+                        "default": "asc",
+                        "description": "Порядок сортировки",
+                        "name": "order",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Название книги",
+                        "name": "title",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "JWT token",
+                        "description": "total\": int64, \"page\": int, \"limit\": int}",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/services.Book"
+                        }
+                    },
+                    "408": {
+                        "description": "Request timed out\"}",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
                     "500": {
-                        "description": "Failed to generate token",
+                        "description": "Failed to fetch books\"}",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/models.ErrorResponse"
                         }
                     }
                 }
-            }
-        },
-        "/handle-error": {
+            },
             "post": {
-                "description": "Возвращает JSON-ответ с указанным кодом состояния и сообщением об ошибке.",
+                "description": "Создает новую книгу на основе переданных данных.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
                 "tags": [
-                    "error handling"
+                    "books"
                 ],
-                "summary": "Обработка ошибок",
+                "summary": "Создание новой книги",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Код состояния HTTP",
-                        "name": "statusCode",
-                        "in": "query",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "Сообщение об ошибке",
-                        "name": "message",
-                        "in": "query",
-                        "required": true
+                        "description": "Данные книги",
+                        "name": "book",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/services.Book"
+                        }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "error message",
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/services.Book"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
-                    }
-                }
-            }
-        },
-        "/initdb": {
-            "post": {
-                "description": "Устанавливает соединение с базой данных и выполняет миграцию для модели Book.",
-                "tags": [
-                    "database"
-                ],
-                "summary": "Инициализация базы данных",
-                "responses": {
-                    "200": {
-                        "description": "Database initialized successfully",
-                        "schema": {
-                            "type": "string"
-                        }
                     },
-                    "500": {
-                        "description": "Failed to connect to database",
+                    "409": {
+                        "description": "Book with this ISBN already exists",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/models.ErrorResponse"
                         }
                     }
                 }
             }
         },
-        "/login": {
-            "post": {
-                "description": "Проверяет учетные данные пользователя и возвращает токен при успешной аутентификации.",
+        "/books/count-by-author": {
+            "get": {
+                "description": "Возвращает количество книг для каждого автора в базе данных. Варианты написания имени одного автора считаются вместе. Принимает те же фильтры, что и список книг.",
                 "consumes": [
                     "application/json"
                 ],
                 "produces": [
                     "application/json"
                 ],
-                "summary": "Аутентификация пользователя",
+                "tags": [
+                    "books"
+                ],
+                "summary": "Подсчет книг по авторам",
                 "parameters": [
                     {
-                        "description": "Учетные данные пользователя",
-                        "name": "creds",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/services.Credentials"
-                        }
+                        "type": "string",
+                        "description": "Фильтр по заголовку книги",
+                        "name": "title",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Фильтр по автору",
+                        "name": "author",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Год издания от",
+                        "name": "startYear",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Год издания до",
+                        "name": "endYear",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Фильтр по издателю",
+                        "name": "publisher_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Фильтр по рубрике",
+                        "name": "category_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Фильтр по произведению",
+                        "name": "work_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Фильтр по серии",
+                        "name": "series_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Фильтр по языку текста книги",
+                        "name": "language",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Фильтр по тегам (через запятую или повтором параметра)",
+                        "name": "tags",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "all",
+                            "any"
+                        ],
+                        "type": "string",
+                        "default": "all",
+                        "description": "all — книги со всеми тегами, any — с любым из них",
+                        "name": "tag_mode",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Только книги в наличии (true) или отсутствующие (false)",
+                        "name": "available",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "токен доступа",
+                        "description": "Количество книг по каждому автору",
                         "schema": {
-                            "$ref": "#/definitions/models.TokenResponse"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.AuthorBookCount"
+                            }
                         }
                     },
                     "400": {
-                        "description": "invalid request",
+                        "description": "Invalid filter",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
+                    }
+                }
+            }
+        },
+        "/books/export": {
+            "get": {
+                "description": "Потоково выгружает отфильтрованный список книг в формате CSV, NDJSON или XLSX, не загружая его целиком в память. Название и автор ищутся без учета опечаток.",
+                "produces": [
+                    "text/csv",
+                    "application/x-ndjson",
+                    "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+                ],
+                "tags": [
+                    "books"
+                ],
+                "summary": "Выгрузка каталога книг",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "default": "csv",
+                        "description": "Формат выгрузки (csv, ndjson, xlsx)",
+                        "name": "format",
+                        "in": "query"
                     },
-                    "401": {
-                        "description": "unauthorized",
+                    {
+                        "type": "string",
+                        "description": "Список колонок через запятую (id, title, author, year, publisher_id, category_id, work_id, series_id, series_volume, isbn13, stock, price, currency)",
+                        "name": "columns",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Фильтр по заголовку книги",
+                        "name": "title",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Фильтр по автору",
+                        "name": "author",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Год издания от",
+                        "name": "startYear",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Год издания до",
+                        "name": "endYear",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Фильтр по издателю",
+                        "name": "publisher_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Фильтр по рубрике",
+                        "name": "category_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Фильтр по произведению",
+                        "name": "work_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Фильтр по серии",
+                        "name": "series_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Фильтр по языку текста книги",
+                        "name": "language",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Фильтр по тегам (через запятую или повтором параметра)",
+                        "name": "tags",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "all",
+                            "any"
+                        ],
+                        "type": "string",
+                        "default": "all",
+                        "description": "all — книги со всеми тегами, any — с любым из них",
+                        "name": "tag_mode",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Только книги в наличии (true) или отсутствующие (false)",
+                        "name": "available",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Файл выгрузки",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid export parameters",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
                     "500": {
-                        "description": "could not create token",
+                        "description": "Failed to export books",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
@@ -457,9 +680,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/protected-route": {
-            "get": {
-                "description": "Middleware для проверки JWT токена в заголовке Authorization.",
+        "/books/import": {
+            "post": {
+                "description": "Принимает массив книг. Книги с уже известным ISBN-13 обновляются, повторы внутри пакета пропускаются, книги без ISBN создаются.",
                 "consumes": [
                     "application/json"
                 ],
@@ -467,18 +690,38 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "auth"
+                    "books"
+                ],
+                "summary": "Импорт книг",
+                "parameters": [
+                    {
+                        "description": "Импортируемые книги",
+                        "name": "books",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/services.Book"
+                            }
+                        }
+                    }
                 ],
-                "summary": "Проверка токена авторизации",
                 "responses": {
                     "200": {
-                        "description": "success",
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.MessageResponse"
+                            "$ref": "#/definitions/models.ImportBooksResponse"
                         }
                     },
-                    "401": {
-                        "description": "unauthorized",
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to import books",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
@@ -486,9 +729,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/protected-route/{requiredRole}": {
+        "/books/isbn/{isbn}": {
             "get": {
-                "description": "Middleware для проверки роли пользователя на основе JWT токена.",
+                "description": "Возвращает книгу по ISBN-10 или ISBN-13; номер нормализуется перед поиском.",
                 "consumes": [
                     "application/json"
                 ],
@@ -496,33 +739,66 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "auth"
+                    "books"
                 ],
-                "summary": "Проверка роли пользователя",
+                "summary": "Получение книги по ISBN",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Необходимая роль для доступа",
-                        "name": "requiredRole",
+                        "description": "ISBN-10 или ISBN-13",
+                        "name": "isbn",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag ранее полученной версии книги",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Валюта отображения цен (ISO 4217); приоритетнее Accept-Currency",
+                        "name": "currency",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Предпочитаемые валюты отображения цен через запятую",
+                        "name": "Accept-Currency",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Язык названия и описания (тег BCP 47); приоритетнее Accept-Language",
+                        "name": "lang",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Предпочитаемые языки; без подходящего перевода отдается оригинал",
+                        "name": "Accept-Language",
+                        "in": "header"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "success",
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.MessageResponse"
+                            "$ref": "#/definitions/services.Book"
                         }
                     },
-                    "401": {
-                        "description": "unauthorized",
+                    "304": {
+                        "description": "Книга не изменилась"
+                    },
+                    "400": {
+                        "description": "invalid ISBN",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
-                    "403": {
-                        "description": "forbidden",
+                    "404": {
+                        "description": "Book not found",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
@@ -530,9 +806,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/refresh": {
+        "/books/publisher": {
             "post": {
-                "description": "Middleware для обновления JWT токена, если он истек.",
+                "description": "Назначает указанного издателя всем книгам, подходящим под фильтр, и возвращает количество измененных записей. Пустой фильтр не допускается; название и автор сравниваются без учета опечаток.",
                 "consumes": [
                     "application/json"
                 ],
@@ -540,30 +816,41 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "auth"
+                    "books"
+                ],
+                "summary": "Массовое переназначение издателя",
+                "parameters": [
+                    {
+                        "description": "Издатель и фильтр книг",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ReassignPublisherRequest"
+                        }
+                    }
                 ],
-                "summary": "Обновление токена авторизации",
                 "responses": {
                     "200": {
-                        "description": "новый токен",
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.TokenResponse"
+                            "$ref": "#/definitions/models.ReassignPublisherResponse"
                         }
                     },
                     "400": {
-                        "description": "token not expired enough",
+                        "description": "Invalid request",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
-                    "401": {
-                        "description": "unauthorized",
+                    "404": {
+                        "description": "Publisher not found",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
                     "500": {
-                        "description": "could not create token",
+                        "description": "Error updating publisher",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
@@ -571,9 +858,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/register": {
-            "post": {
-                "description": "Обрабатывает запрос на регистрацию, проверяет данные и сохраняет пользователя.",
+        "/books/suggest": {
+            "get": {
+                "description": "Возвращает подсказки для строки поиска: названия книг, авторов и рубрики. Сначала идут совпадения с началом названия или слова, затем похожие написания. Для запросов короче двух символов возвращается пустой список. Ответ кэшируется на короткое время.",
                 "consumes": [
                     "application/json"
                 ],
@@ -581,163 +868,6105 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "auth"
+                    "books"
                 ],
-                "summary": "Регистрация нового пользователя",
+                "summary": "Подсказки поиска",
                 "parameters": [
                     {
-                        "description": "Данные для регистрации пользователя",
-                        "name": "credentials",
+                        "type": "string",
+                        "description": "Начало строки поиска",
+                        "name": "q",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "default": 8,
+                        "description": "Количество подсказок (не больше 20)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/services.Suggestion"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to load suggestions",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/books/trash": {
+            "get": {
+                "description": "Возвращает мягко удаленные книги, начиная с последних удаленных.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "books"
+                ],
+                "summary": "Корзина удаленных книг",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Номер страницы",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Количество книг на странице",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "total\": int64, \"page\": int, \"limit\": int",
+                        "schema": {
+                            "$ref": "#/definitions/services.Book"
+                        }
+                    }
+                }
+            }
+        },
+        "/books/{id}": {
+            "get": {
+                "description": "Возвращает книгу с указанным идентификатором.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "books"
+                ],
+                "summary": "Получение книги по ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Идентификатор книги",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag ранее полученной версии книги",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Валюта отображения цен (ISO 4217); приоритетнее Accept-Currency",
+                        "name": "currency",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Предпочитаемые валюты отображения цен через запятую",
+                        "name": "Accept-Currency",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Язык названия и описания (тег BCP 47); приоритетнее Accept-Language",
+                        "name": "lang",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Предпочитаемые языки; без подходящего перевода отдается оригинал",
+                        "name": "Accept-Language",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/services.Book"
+                        }
+                    },
+                    "304": {
+                        "description": "Книга не изменилась"
+                    },
+                    "400": {
+                        "description": "unsupported currency",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Book not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Полностью заменяет редактируемые поля книги: не переданные поля получают нулевые значения. Возвращает сохраненную книгу.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "books"
+                ],
+                "summary": "Замена книги по ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Идентификатор книги",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag, полученный при чтении книги (обязателен, если включен BOOK_REQUIRE_IF_MATCH)",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Новые данные книги",
+                        "name": "book",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/services.Credentials"
+                            "$ref": "#/definitions/models.BookInput"
                         }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "user registered successfully",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.MessageResponse"
+                            "$ref": "#/definitions/services.Book"
                         }
                     },
                     "400": {
-                        "description": "invalid request",
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Book not found",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
                     "409": {
-                        "description": "user already exists",
+                        "description": "Book with this ISBN already exists",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "Book has been modified",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "428": {
+                        "description": "If-Match header is required",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Перемещает книгу с указанным идентификатором в корзину. Книга окончательно удаляется по истечении срока хранения.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "books"
+                ],
+                "summary": "Удаление книги по ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Идентификатор книги",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag, полученный при чтении книги (обязателен, если включен BOOK_REQUIRE_IF_MATCH)",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Book deleted",
+                        "schema": {
+                            "$ref": "#/definitions/models.MessageResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Book not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "Book has been modified",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "428": {
+                        "description": "If-Match header is required",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to delete book",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
                     }
                 }
+            },
+            "patch": {
+                "description": "Применяет к редактируемым полям книги JSON Merge Patch (RFC 7396, Content-Type application/merge-patch+json или application/json) или JSON Patch (RFC 6902, Content-Type application/json-patch+json). Возвращает сохраненную книгу.",
+                "consumes": [
+                    "application/merge-patch+json",
+                    "application/json-patch+json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "books"
+                ],
+                "summary": "Частичное изменение книги по ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Идентификатор книги",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag, полученный при чтении книги (обязателен, если включен BOOK_REQUIRE_IF_MATCH)",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Патч",
+                        "name": "patch",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "object"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/services.Book"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid patch",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Book not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Book with this ISBN already exists",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "Book has been modified",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "415": {
+                        "description": "Unsupported patch format",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Patch test operation failed",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "428": {
+                        "description": "If-Match header is required",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/books/{id}/also-bought": {
+            "get": {
+                "description": "Возвращает книги, которые чаще всего покупают вместе с указанной (по предвычисленной статистике заказов). Если данных мало, список дополняется книгами того же автора и той же рубрики; причина указана в поле reason.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "recommendations"
+                ],
+                "summary": "С этой книгой покупают",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Идентификатор книги",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Количество рекомендаций",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Валюта отображения цен (ISO 4217); приоритетнее Accept-Currency",
+                        "name": "currency",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Предпочитаемые валюты отображения цен через запятую",
+                        "name": "Accept-Currency",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/services.Recommendation"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Book not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/books/{id}/authors": {
+            "put": {
+                "description": "Полностью заменяет список участников книги (авторы, переводчики, иллюстраторы, редакторы). Порядок в запросе сохраняется. Версия книги увеличивается, изменение попадает в историю.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "authors"
+                ],
+                "summary": "Назначение авторов книги",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Идентификатор книги",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Участники книги",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.SetBookAuthorsRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/services.BookAuthor"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Book not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/books/{id}/cover": {
+            "post": {
+                "description": "Принимает изображение JPEG, PNG или WebP, сохраняет оригинал и строит миниатюры. Предыдущая обложка заменяется.",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "books"
+                ],
+                "summary": "Загрузка обложки книги",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Идентификатор книги",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "file",
+                        "description": "Изображение обложки",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/services.BookCover"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid image",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Book not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "413": {
+                        "description": "File too large",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "415": {
+                        "description": "Unsupported image type",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to save cover",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Удаляет обложку книги и все ее миниатюры.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "books"
+                ],
+                "summary": "Удаление обложки книги",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Идентификатор книги",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Cover deleted",
+                        "schema": {
+                            "$ref": "#/definitions/models.MessageResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Cover not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to delete cover",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/books/{id}/downloads": {
+            "get": {
+                "description": "Возвращает число скачиваний книги каждым пользователем и время последнего скачивания.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "files"
+                ],
+                "summary": "Статистика скачиваний книги",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Идентификатор книги",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/services.DownloadStat"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Book not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/books/{id}/entitlements": {
+            "post": {
+                "description": "Выдает пользователю книгу на время (loan, требуется expires_at) или бессрочно (grant).",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "files"
+                ],
+                "summary": "Выдача доступа к книге",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Идентификатор книги",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Пользователь и срок доступа",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.GrantEntitlementRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/services.BookEntitlement"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Book not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/books/{id}/entitlements/{username}": {
+            "delete": {
+                "description": "Отзывает выданный администратором доступ пользователя к книге. Доступ по покупке не затрагивается.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "files"
+                ],
+                "summary": "Отзыв доступа к книге",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Идентификатор книги",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Имя пользователя",
+                        "name": "username",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Access revoked",
+                        "schema": {
+                            "$ref": "#/definitions/models.MessageResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Access not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/books/{id}/files": {
+            "post": {
+                "description": "Принимает файл EPUB или PDF и сохраняет его в закрытом хранилище. Файл того же формата заменяется; версия книги увеличивается, изменение записывается в историю.",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "files"
+                ],
+                "summary": "Загрузка файла книги",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Идентификатор книги",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "file",
+                        "description": "Файл EPUB или PDF",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/services.BookFile"
+                        }
+                    },
+                    "400": {
+                        "description": "File is required",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Book not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "413": {
+                        "description": "File too large",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "415": {
+                        "description": "only EPUB and PDF files are supported",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/books/{id}/files/{fileId}": {
+            "delete": {
+                "description": "Удаляет файл книги из хранилища, увеличивает версию книги и записывает изменение в историю.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "files"
+                ],
+                "summary": "Удаление файла книги",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Идентификатор книги",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Идентификатор файла",
+                        "name": "fileId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "File deleted",
+                        "schema": {
+                            "$ref": "#/definitions/models.MessageResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "File not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/books/{id}/files/{fileId}/link": {
+            "get": {
+                "description": "Проверяет, что пользователь купил книгу или получил к ней доступ, и возвращает подписанную ссылку, действующую DOWNLOAD_URL_TTL.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "files"
+                ],
+                "summary": "Ссылка на скачивание файла книги",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Идентификатор книги",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Идентификатор файла",
+                        "name": "fileId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.DownloadLinkResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Book is not purchased",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "File not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/books/{id}/history": {
+            "get": {
+                "description": "Возвращает ревизии книги (кто, когда и какие поля изменил), начиная с последней. Доступна и для книг в корзине.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "books"
+                ],
+                "summary": "История изменений книги",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Идентификатор книги",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Номер страницы",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Количество ревизий на странице",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "total\": int64, \"page\": int, \"limit\": int",
+                        "schema": {
+                            "$ref": "#/definitions/services.BookRevision"
+                        }
+                    },
+                    "404": {
+                        "description": "Book not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/books/{id}/history/{revision}/rollback": {
+            "post": {
+                "description": "Возвращает поля книги к состоянию после указанной ревизии. Откат сам сохраняется как новая ревизия.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "books"
+                ],
+                "summary": "Откат книги к ревизии",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Идентификатор книги",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Идентификатор ревизии",
+                        "name": "revision",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/services.Book"
+                        }
+                    },
+                    "400": {
+                        "description": "Cannot roll back to a delete revision",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Revision not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Book with this ISBN already exists",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/books/{id}/prices": {
+            "get": {
+                "description": "Возвращает записи истории цен книги, начиная с последних. Суммы указаны в минимальных единицах валюты. Запланированные, но еще не начавшиеся цены видны только администраторам.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "prices"
+                ],
+                "summary": "История цен книги",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Идентификатор книги",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Номер страницы",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Количество записей на странице",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "total\": int64, \"page\": int, \"limit\": int",
+                        "schema": {
+                            "$ref": "#/definitions/services.BookPrice"
+                        }
+                    },
+                    "404": {
+                        "description": "Book not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Добавляет в историю базовую цену или распродажу. Цена с датой начала в будущем применяется автоматически в указанное время; распродажа действует до ends_at и на это время перекрывает базовую цену.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "prices"
+                ],
+                "summary": "Назначение цены книги",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Идентификатор книги",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Новая цена",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.SetPriceRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/services.BookPrice"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid price",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Book not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/books/{id}/prices/{priceId}": {
+            "delete": {
+                "description": "Удаляет цену или распродажу, которая еще не начала действовать. Вступившие в силу записи являются историей и не удаляются.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "prices"
+                ],
+                "summary": "Отмена запланированной цены",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Идентификатор книги",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Идентификатор записи цены",
+                        "name": "priceId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Price deleted",
+                        "schema": {
+                            "$ref": "#/definitions/models.MessageResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Price not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "only scheduled prices can be removed",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/books/{id}/restore": {
+            "post": {
+                "description": "Возвращает мягко удаленную книгу в каталог.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "books"
+                ],
+                "summary": "Восстановление книги",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Идентификатор книги",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/services.Book"
+                        }
+                    },
+                    "404": {
+                        "description": "Book not found in trash",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Book with this ISBN already exists",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/books/{id}/stock": {
+            "post": {
+                "description": "Изменяет количество экземпляров на складе и записывает движение в журнал. Поступление (receiving) только увеличивает остаток, списание брака (damage) только уменьшает, корректировка (correction) работает в обе стороны.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "stock"
+                ],
+                "summary": "Изменение остатка книги",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Идентификатор книги",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Изменение остатка",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.StockAdjustmentRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/services.StockMovement"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Book not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "insufficient stock",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/books/{id}/stock/movements": {
+            "get": {
+                "description": "Возвращает движения остатков книги, начиная с последних.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "stock"
+                ],
+                "summary": "Журнал движения остатков",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Идентификатор книги",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Номер страницы",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Количество записей на странице",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "total\": int64, \"page\": int, \"limit\": int",
+                        "schema": {
+                            "$ref": "#/definitions/services.StockMovement"
+                        }
+                    },
+                    "404": {
+                        "description": "Book not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/books/{id}/tags": {
+            "post": {
+                "description": "Добавляет книге теги; несуществующие теги создаются. Теги приводятся к нижнему регистру, пробелы заменяются дефисами. Возвращает все теги книги.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tags"
+                ],
+                "summary": "Добавление тегов книге",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Идентификатор книги",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Теги",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.BookTagsRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/services.Tag"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Book not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/books/{id}/tags/{tag}": {
+            "delete": {
+                "description": "Снимает тег с книги; сам тег остается. Возвращает оставшиеся теги книги.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tags"
+                ],
+                "summary": "Снятие тега с книги",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Идентификатор книги",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Название тега",
+                        "name": "tag",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/services.Tag"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Book not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/books/{id}/translations": {
+            "get": {
+                "description": "Возвращает все переводы названия и описания книги.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "translations"
+                ],
+                "summary": "Переводы книги",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Идентификатор книги",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/services.BookTranslation"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Book not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/books/{id}/translations/{locale}": {
+            "put": {
+                "description": "Создает или заменяет перевод названия и описания книги на указанный язык. Версия книги увеличивается.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "translations"
+                ],
+                "summary": "Перевод книги на язык",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Идентификатор книги",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Язык перевода, тег BCP 47 (en, en-US)",
+                        "name": "locale",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Перевод",
+                        "name": "translation",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.BookTranslationRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/services.BookTranslation"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Book not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Удаляет перевод книги на указанный язык. Версия книги увеличивается.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "translations"
+                ],
+                "summary": "Удаление перевода книги",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Идентификатор книги",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Язык перевода",
+                        "name": "locale",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Translation deleted",
+                        "schema": {
+                            "$ref": "#/definitions/models.MessageResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Book or translation not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/cart": {
+            "get": {
+                "description": "Возвращает корзину текущего пользователя или анонимную корзину по токену из X-Cart-Token. При каждом чтении цены и наличие проверяются заново: строки удаленных, снятых с продажи книг и книг, которых не хватает на складе, помечаются issue и не входят в сумму; price_changed означает, что цена изменилась после добавления. Цены считаются в валюте заказа (currency или Accept-Currency).",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "cart"
+                ],
+                "summary": "Корзина",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Токен анонимной корзины",
+                        "name": "X-Cart-Token",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Валюта (ISO 4217); приоритетнее Accept-Currency",
+                        "name": "currency",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Предпочитаемые валюты через запятую",
+                        "name": "Accept-Currency",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/services.Cart"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Удаляет из корзины все книги.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "cart"
+                ],
+                "summary": "Очистка корзины",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Токен анонимной корзины",
+                        "name": "X-Cart-Token",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Cart cleared",
+                        "schema": {
+                            "$ref": "#/definitions/models.MessageResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/cart/checkout": {
+            "post": {
+                "description": "Оформляет содержимое корзины текущего пользователя как заказ (см. POST /orders) и убирает оформленные книги из корзины. Если в корзине есть недоступные книги, возвращается 409 — корзину нужно сначала поправить. Анонимную корзину оформить нельзя: ее книги переносятся в корзину пользователя при входе.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "cart"
+                ],
+                "summary": "Оформление корзины",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Валюта заказа (ISO 4217); приоритетнее Accept-Currency",
+                        "name": "currency",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Предпочитаемые валюты через запятую",
+                        "name": "Accept-Currency",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.Order"
+                        }
+                    },
+                    "400": {
+                        "description": "order must contain at least one book",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "cart contains unavailable books",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/cart/items": {
+            "post": {
+                "description": "Добавляет книгу в корзину; если книга уже в корзине, количества складываются. Книга должна продаваться и быть на складе. Анонимная корзина создается при первом добавлении, ее токен возвращается в заголовке X-Cart-Token и в поле token.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "cart"
+                ],
+                "summary": "Добавление книги в корзину",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Токен анонимной корзины",
+                        "name": "X-Cart-Token",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Книга и количество",
+                        "name": "item",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.AddCartItemRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/services.Cart"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Book not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "book 1: insufficient stock",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/cart/items/{bookId}": {
+            "put": {
+                "description": "Задает количество экземпляров книги, уже лежащей в корзине. Цена добавления обновляется до текущей.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "cart"
+                ],
+                "summary": "Изменение количества книги в корзине",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Токен анонимной корзины",
+                        "name": "X-Cart-Token",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Идентификатор книги",
+                        "name": "bookId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Новое количество",
+                        "name": "item",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.UpdateProductQuantityRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/services.Cart"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Book is not in cart",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "book 1: insufficient stock",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Удаляет книгу из корзины и возвращает оставшуюся корзину.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "cart"
+                ],
+                "summary": "Удаление книги из корзины",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Токен анонимной корзины",
+                        "name": "X-Cart-Token",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Идентификатор книги",
+                        "name": "bookId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/services.Cart"
+                        }
+                    },
+                    "404": {
+                        "description": "Book is not in cart",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/categories": {
+            "get": {
+                "description": "Возвращает все рубрики каталога в алфавитном порядке. Книга ссылается на рубрику через поле category_id.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "categories"
+                ],
+                "summary": "Получение списка рубрик",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Category"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Создает новую рубрику каталога.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "categories"
+                ],
+                "summary": "Создание рубрики",
+                "parameters": [
+                    {
+                        "description": "Данные рубрики",
+                        "name": "category",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.Category"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.Category"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/categories/{id}": {
+            "put": {
+                "description": "Обновляет название и описание рубрики.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "categories"
+                ],
+                "summary": "Обновление рубрики по ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Идентификатор рубрики",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Обновленные данные рубрики",
+                        "name": "category",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.Category"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Category"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Category not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Удаляет рубрику; у ее книг ссылка на рубрику обнуляется, версии книг увеличиваются, а изменения попадают в историю.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "categories"
+                ],
+                "summary": "Удаление рубрики по ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Идентификатор рубрики",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Category deleted",
+                        "schema": {
+                            "$ref": "#/definitions/models.MessageResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Category not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/currencies": {
+            "get": {
+                "description": "Возвращает курсы всех валют к базовой валюте магазина, включая саму базовую валюту с курсом 1.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "currencies"
+                ],
+                "summary": "Получение курсов валют",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/services.CurrencyRate"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/currencies/import": {
+            "post": {
+                "description": "Принимает CSV-файл с заголовком currency,rate[,round_to] и создает или обновляет перечисленные курсы. Файл применяется целиком или не применяется вовсе.",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "currencies"
+                ],
+                "summary": "Импорт курсов валют",
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "CSV-файл с курсами",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/services.CurrencyRate"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid CSV",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/currencies/{code}": {
+            "put": {
+                "description": "Создает или обновляет курс валюты к базовой валюте магазина и шаг округления сконвертированных цен.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "currencies"
+                ],
+                "summary": "Установка курса валюты",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Код валюты ISO 4217",
+                        "name": "code",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Курс валюты",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.SetCurrencyRateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/services.CurrencyRate"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Удаляет курс валюты; цены в этой валюте больше не отображаются.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "currencies"
+                ],
+                "summary": "Удаление курса валюты",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Код валюты ISO 4217",
+                        "name": "code",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Currency rate deleted",
+                        "schema": {
+                            "$ref": "#/definitions/models.MessageResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Currency rate not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/downloads/{fileId}": {
+            "get": {
+                "description": "Отдает файл по ссылке, полученной из /books/{id}/files/{fileId}/link. Право на книгу проверяется повторно, скачивание записывается в журнал.",
+                "produces": [
+                    "application/epub+zip",
+                    "application/pdf"
+                ],
+                "tags": [
+                    "files"
+                ],
+                "summary": "Скачивание файла книги",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Идентификатор файла",
+                        "name": "fileId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Имя пользователя",
+                        "name": "user",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Роль пользователя на момент выдачи ссылки",
+                        "name": "role",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Окончание действия ссылки (Unix time)",
+                        "name": "expires",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Подпись ссылки",
+                        "name": "signature",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Файл книги",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "403": {
+                        "description": "Invalid or expired link",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "File not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "download limit reached",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/generate-token": {
+            "post": {
+                "description": "Создает JWT-токен с именем пользователя и ролью, срок действия токена составляет 5 минут.",
+                "tags": [
+                    "authentication"
+                ],
+                "summary": "Генерация JWT-токена",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Имя пользователя",
+                        "name": "username",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Роль пользователя",
+                        "name": "role",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "JWT token",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to generate token",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/guest/cart": {
+            "get": {
+                "description": "Возвращает корзину текущего пользователя или анонимную корзину по токену из X-Cart-Token. При каждом чтении цены и наличие проверяются заново: строки удаленных, снятых с продажи книг и книг, которых не хватает на складе, помечаются issue и не входят в сумму; price_changed означает, что цена изменилась после добавления. Цены считаются в валюте заказа (currency или Accept-Currency).",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "cart"
+                ],
+                "summary": "Корзина",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Токен анонимной корзины",
+                        "name": "X-Cart-Token",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Валюта (ISO 4217); приоритетнее Accept-Currency",
+                        "name": "currency",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Предпочитаемые валюты через запятую",
+                        "name": "Accept-Currency",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/services.Cart"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Удаляет из корзины все книги.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "cart"
+                ],
+                "summary": "Очистка корзины",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Токен анонимной корзины",
+                        "name": "X-Cart-Token",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Cart cleared",
+                        "schema": {
+                            "$ref": "#/definitions/models.MessageResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/guest/cart/items": {
+            "post": {
+                "description": "Добавляет книгу в корзину; если книга уже в корзине, количества складываются. Книга должна продаваться и быть на складе. Анонимная корзина создается при первом добавлении, ее токен возвращается в заголовке X-Cart-Token и в поле token.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "cart"
+                ],
+                "summary": "Добавление книги в корзину",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Токен анонимной корзины",
+                        "name": "X-Cart-Token",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Книга и количество",
+                        "name": "item",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.AddCartItemRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/services.Cart"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Book not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "book 1: insufficient stock",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/guest/cart/items/{bookId}": {
+            "put": {
+                "description": "Задает количество экземпляров книги, уже лежащей в корзине. Цена добавления обновляется до текущей.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "cart"
+                ],
+                "summary": "Изменение количества книги в корзине",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Токен анонимной корзины",
+                        "name": "X-Cart-Token",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Идентификатор книги",
+                        "name": "bookId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Новое количество",
+                        "name": "item",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.UpdateProductQuantityRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/services.Cart"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Book is not in cart",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "book 1: insufficient stock",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Удаляет книгу из корзины и возвращает оставшуюся корзину.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "cart"
+                ],
+                "summary": "Удаление книги из корзины",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Токен анонимной корзины",
+                        "name": "X-Cart-Token",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Идентификатор книги",
+                        "name": "bookId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/services.Cart"
+                        }
+                    },
+                    "404": {
+                        "description": "Book is not in cart",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/handle-error": {
+            "post": {
+                "description": "Возвращает JSON-ответ с указанным кодом состояния и сообщением об ошибке.",
+                "tags": [
+                    "error handling"
+                ],
+                "summary": "Обработка ошибок",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Код состояния HTTP",
+                        "name": "statusCode",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Сообщение об ошибке",
+                        "name": "message",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "error message",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/initdb": {
+            "post": {
+                "description": "Устанавливает соединение с базой данных и выполняет миграцию для модели Book.",
+                "tags": [
+                    "database"
+                ],
+                "summary": "Инициализация базы данных",
+                "responses": {
+                    "200": {
+                        "description": "Database initialized successfully",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to connect to database",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/login": {
+            "post": {
+                "description": "Проверяет учетные данные пользователя и возвращает токен при успешной аутентификации.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Аутентификация пользователя",
+                "parameters": [
+                    {
+                        "description": "Учетные данные пользователя",
+                        "name": "creds",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/services.Credentials"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Токен анонимной корзины; ее книги переносятся в корзину пользователя",
+                        "name": "X-Cart-Token",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "токен доступа",
+                        "schema": {
+                            "$ref": "#/definitions/models.TokenResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "could not create token",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/me/orders": {
+            "get": {
+                "description": "Возвращает заказы текущего пользователя со строками, по умолчанию начиная с последних. Поддерживает фильтры по статусу и дате оформления, сортировку и пагинацию.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "orders"
+                ],
+                "summary": "Мои заказы",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Номер страницы",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Количество заказов на странице",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Статусы (через запятую или повтором параметра)",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Оформлены начиная с даты (YYYY-MM-DD)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Оформлены по дату включительно (YYYY-MM-DD)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "id",
+                            "created_at",
+                            "total",
+                            "status"
+                        ],
+                        "type": "string",
+                        "default": "created_at",
+                        "description": "Поле для сортировки",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "asc",
+                            "desc"
+                        ],
+                        "type": "string",
+                        "default": "desc",
+                        "description": "Порядок сортировки",
+                        "name": "order",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.OrderResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid filter",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/me/recommendations": {
+            "get": {
+                "description": "Возвращает книги, подобранные по заказам, списку желаний и отзывам пользователя, с объяснением вида «because you bought X». Рекомендации пересчитываются в фоне; пока истории нет, возвращаются популярные книги.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "recommendations"
+                ],
+                "summary": "Персональные рекомендации",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Количество рекомендаций",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Валюта отображения цен (ISO 4217); приоритетнее Accept-Currency",
+                        "name": "currency",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Предпочитаемые валюты отображения цен через запятую",
+                        "name": "Accept-Currency",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/services.Recommendation"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/me/wishlist": {
+            "get": {
+                "description": "Возвращает книги из списка желаний текущего пользователя, начиная с последних добавленных.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "wishlist"
+                ],
+                "summary": "Список желаний",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/services.WishlistItem"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/me/wishlist/{bookId}": {
+            "put": {
+                "description": "Добавляет книгу в список желаний текущего пользователя. Повторное добавление ничего не меняет.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "wishlist"
+                ],
+                "summary": "Добавление книги в список желаний",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Идентификатор книги",
+                        "name": "bookId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/services.WishlistItem"
+                        }
+                    },
+                    "404": {
+                        "description": "Book not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Удаляет книгу из списка желаний текущего пользователя.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "wishlist"
+                ],
+                "summary": "Удаление книги из списка желаний",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Идентификатор книги",
+                        "name": "bookId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Removed from wishlist",
+                        "schema": {
+                            "$ref": "#/definitions/models.MessageResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Book is not in wishlist",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/orders": {
+            "post": {
+                "description": "Оформляет заказ текущего пользователя. В одной транзакции проверяет книги и количества, блокирует и списывает остатки, фиксирует цены в строках заказа и считает сумму. Валюта заказа выбирается как при показе цен (currency или Accept-Currency), по умолчанию — базовая валюта магазина. Право скачивать электронные версии купленных книг покупатель получает после оплаты заказа.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "orders"
+                ],
+                "summary": "Оформление заказа",
+                "parameters": [
+                    {
+                        "description": "Книги и количества",
+                        "name": "order",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CreateOrderRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Валюта заказа (ISO 4217); приоритетнее Accept-Currency",
+                        "name": "currency",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Предпочитаемые валюты через запятую",
+                        "name": "Accept-Currency",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.Order"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "book 1: insufficient stock",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/orders/{id}": {
+            "get": {
+                "description": "Возвращает заказ со строками, книгами каталога в поле product и историей статусов. Покупатель видит только свои заказы.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "orders"
+                ],
+                "summary": "Заказ",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Идентификатор заказа",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Order"
+                        }
+                    },
+                    "404": {
+                        "description": "Order not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/orders/{id}/cancel": {
+            "post": {
+                "description": "Отменяет заказ текущего пользователя, пока он не оплачен. Книги возвращаются на склад, право на скачивание купленных по заказу электронных версий отзывается.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "orders"
+                ],
+                "summary": "Отмена заказа",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Идентификатор заказа",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Причина отмены",
+                        "name": "request",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/models.CancelOrderRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Order"
+                        }
+                    },
+                    "404": {
+                        "description": "Order not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "invalid order status transition: shipped -\u003e cancelled",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/orders/{id}/history": {
+            "get": {
+                "description": "Возвращает изменения статуса заказа от оформления до текущего статуса. Покупатель видит только свои заказы.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "orders"
+                ],
+                "summary": "История статусов заказа",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Идентификатор заказа",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.OrderStatusChange"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Order not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/orders/{id}/status": {
+            "post": {
+                "description": "Переводит заказ в новый статус. Допустимые переходы: pending → paid или cancelled, paid → packed или refunded, packed → shipped или refunded, shipped → delivered, delivered → refunded. Время перехода и запись истории сохраняются. При оплате покупатель получает право скачивать электронные версии купленных книг. При отмене и возврате неотправленного заказа книги возвращаются на склад; право на скачивание электронных версий отзывается.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "orders"
+                ],
+                "summary": "Смена статуса заказа",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Идентификатор заказа",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Новый статус",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.OrderStatusRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Order"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Order not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "invalid order status transition: pending -\u003e shipped",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/protected-route": {
+            "get": {
+                "description": "Middleware для проверки JWT токена в заголовке Authorization.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Проверка токена авторизации",
+                "responses": {
+                    "200": {
+                        "description": "success",
+                        "schema": {
+                            "$ref": "#/definitions/models.MessageResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/protected-route/{requiredRole}": {
+            "get": {
+                "description": "Middleware для проверки роли пользователя на основе JWT токена.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Проверка роли пользователя",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Необходимая роль для доступа",
+                        "name": "requiredRole",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "success",
+                        "schema": {
+                            "$ref": "#/definitions/models.MessageResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/publishers": {
+            "get": {
+                "description": "Возвращает список издателей с пагинацией и фильтром по названию.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "publishers"
+                ],
+                "summary": "Получение списка издателей",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Номер страницы",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Количество издателей на странице",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Фильтр по названию",
+                        "name": "name",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "total\": int64, \"page\": int, \"limit\": int",
+                        "schema": {
+                            "$ref": "#/definitions/services.Publisher"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Создает нового издателя. Название должно быть уникальным.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "publishers"
+                ],
+                "summary": "Создание издателя",
+                "parameters": [
+                    {
+                        "description": "Данные издателя",
+                        "name": "publisher",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/services.Publisher"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/services.Publisher"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Publisher already exists",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/publishers/{id}": {
+            "get": {
+                "description": "Возвращает издателя с указанным идентификатором и список его книг.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "publishers"
+                ],
+                "summary": "Получение издателя по ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Идентификатор издателя",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/services.Publisher"
+                        }
+                    },
+                    "404": {
+                        "description": "Publisher not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Обновляет данные издателя с указанным идентификатором.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "publishers"
+                ],
+                "summary": "Обновление издателя по ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Идентификатор издателя",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Обновленные данные издателя",
+                        "name": "publisher",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/services.Publisher"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/services.Publisher"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Publisher not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Publisher already exists",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Удаляет издателя; у его книг ссылка на издателя обнуляется, версии книг увеличиваются, а изменения попадают в историю.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "publishers"
+                ],
+                "summary": "Удаление издателя по ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Идентификатор издателя",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Publisher deleted",
+                        "schema": {
+                            "$ref": "#/definitions/models.MessageResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Publisher not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/refresh": {
+            "post": {
+                "description": "Middleware для обновления JWT токена, если он истек.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Обновление токена авторизации",
+                "responses": {
+                    "200": {
+                        "description": "новый токен",
+                        "schema": {
+                            "$ref": "#/definitions/models.TokenResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "token not expired enough",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "could not create token",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/register": {
+            "post": {
+                "description": "Обрабатывает запрос на регистрацию, проверяет данные и сохраняет пользователя.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Регистрация нового пользователя",
+                "parameters": [
+                    {
+                        "description": "Данные для регистрации пользователя",
+                        "name": "credentials",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/services.Credentials"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "user registered successfully",
+                        "schema": {
+                            "$ref": "#/definitions/models.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "user already exists",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/series": {
+            "get": {
+                "description": "Возвращает все серии в алфавитном порядке.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "series"
+                ],
+                "summary": "Получение списка серий",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/services.Series"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Создает новую серию. Название должно быть уникальным.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "series"
+                ],
+                "summary": "Создание серии",
+                "parameters": [
+                    {
+                        "description": "Данные серии",
+                        "name": "series",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/services.Series"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/services.Series"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Series already exists",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/series/{id}": {
+            "put": {
+                "description": "Обновляет название и описание серии.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "series"
+                ],
+                "summary": "Обновление серии по ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Идентификатор серии",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Обновленные данные серии",
+                        "name": "series",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/services.Series"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/services.Series"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Series not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Series already exists",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Удаляет серию; книги из нее остаются в каталоге без серии, их версии увеличиваются, а изменения попадают в историю.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "series"
+                ],
+                "summary": "Удаление серии по ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Идентификатор серии",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Series deleted",
+                        "schema": {
+                            "$ref": "#/definitions/models.MessageResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Series not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/series/{id}/books": {
+            "get": {
+                "description": "Возвращает книги серии по порядку томов; книги без номера тома идут в конце.\nС collapse_editions=true из изданий одного произведения остается одно.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "series"
+                ],
+                "summary": "Книги серии",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Идентификатор серии",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Показывать по одному изданию каждого произведения",
+                        "name": "collapse_editions",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Валюта отображения цен (ISO 4217); приоритетнее Accept-Currency",
+                        "name": "currency",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Предпочитаемые валюты отображения цен через запятую",
+                        "name": "Accept-Currency",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Язык названия и описания (тег BCP 47); приоритетнее Accept-Language",
+                        "name": "lang",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Предпочитаемые языки; без подходящего перевода отдается оригинал",
+                        "name": "Accept-Language",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/services.Book"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Series not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/stats/authors/ratings": {
+            "get": {
+                "description": "Возвращает средний рейтинг отзывов на книги каждого автора, от лучших к худшим. Учитываются только книги, подходящие под фильтры списка книг.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "stats"
+                ],
+                "summary": "Средний рейтинг авторов",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Минимальное число отзывов, чтобы автор попал в рейтинг",
+                        "name": "min_reviews",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Фильтр по заголовку книги",
+                        "name": "title",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Фильтр по автору",
+                        "name": "author",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Год издания от",
+                        "name": "startYear",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Год издания до",
+                        "name": "endYear",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Фильтр по издателю",
+                        "name": "publisher_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Фильтр по рубрике",
+                        "name": "category_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Фильтр по произведению",
+                        "name": "work_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Фильтр по серии",
+                        "name": "series_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Фильтр по языку текста книги",
+                        "name": "language",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Фильтр по тегам (через запятую или повтором параметра)",
+                        "name": "tags",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "all",
+                            "any"
+                        ],
+                        "type": "string",
+                        "default": "all",
+                        "description": "all — книги со всеми тегами, any — с любым из них",
+                        "name": "tag_mode",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Только книги в наличии (true) или отсутствующие (false)",
+                        "name": "available",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.AuthorRating"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid filter",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/stats/books/by-category": {
+            "get": {
+                "description": "Возвращает количество книг в каждой рубрике, от крупных к мелким; книги без рубрики идут с category_id = null. Принимает те же фильтры, что и список книг.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "stats"
+                ],
+                "summary": "Книги по рубрикам",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Фильтр по заголовку книги",
+                        "name": "title",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Фильтр по автору",
+                        "name": "author",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Год издания от",
+                        "name": "startYear",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Год издания до",
+                        "name": "endYear",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Фильтр по издателю",
+                        "name": "publisher_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Фильтр по рубрике",
+                        "name": "category_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Фильтр по произведению",
+                        "name": "work_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Фильтр по серии",
+                        "name": "series_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Фильтр по языку текста книги",
+                        "name": "language",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Фильтр по тегам (через запятую или повтором параметра)",
+                        "name": "tags",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "all",
+                            "any"
+                        ],
+                        "type": "string",
+                        "default": "all",
+                        "description": "all — книги со всеми тегами, any — с любым из них",
+                        "name": "tag_mode",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Только книги в наличии (true) или отсутствующие (false)",
+                        "name": "available",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.CategoryBookCount"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid filter",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/stats/books/by-publisher": {
+            "get": {
+                "description": "Возвращает количество книг каждого издателя, от крупных к мелким; книги без издателя идут с publisher_id = null. Принимает те же фильтры, что и список книг.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "stats"
+                ],
+                "summary": "Книги по издателям",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Фильтр по заголовку книги",
+                        "name": "title",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Фильтр по автору",
+                        "name": "author",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Год издания от",
+                        "name": "startYear",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Год издания до",
+                        "name": "endYear",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Фильтр по издателю",
+                        "name": "publisher_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Фильтр по рубрике",
+                        "name": "category_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Фильтр по произведению",
+                        "name": "work_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Фильтр по серии",
+                        "name": "series_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Фильтр по языку текста книги",
+                        "name": "language",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Фильтр по тегам (через запятую или повтором параметра)",
+                        "name": "tags",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "all",
+                            "any"
+                        ],
+                        "type": "string",
+                        "default": "all",
+                        "description": "all — книги со всеми тегами, any — с любым из них",
+                        "name": "tag_mode",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Только книги в наличии (true) или отсутствующие (false)",
+                        "name": "available",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.PublisherBookCount"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid filter",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/stats/books/by-year": {
+            "get": {
+                "description": "Возвращает количество книг по годам или десятилетиям издания. Принимает те же фильтры, что и список книг.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "stats"
+                ],
+                "summary": "Книги по годам издания",
+                "parameters": [
+                    {
+                        "enum": [
+                            "year",
+                            "decade"
+                        ],
+                        "type": "string",
+                        "default": "year",
+                        "description": "Группировка: year — по годам, decade — по десятилетиям",
+                        "name": "group",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Фильтр по заголовку книги",
+                        "name": "title",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Фильтр по автору",
+                        "name": "author",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Год издания от",
+                        "name": "startYear",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Год издания до",
+                        "name": "endYear",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Фильтр по издателю",
+                        "name": "publisher_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Фильтр по рубрике",
+                        "name": "category_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Фильтр по произведению",
+                        "name": "work_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Фильтр по серии",
+                        "name": "series_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Фильтр по языку текста книги",
+                        "name": "language",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Фильтр по тегам (через запятую или повтором параметра)",
+                        "name": "tags",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "all",
+                            "any"
+                        ],
+                        "type": "string",
+                        "default": "all",
+                        "description": "all — книги со всеми тегами, any — с любым из них",
+                        "name": "tag_mode",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Только книги в наличии (true) или отсутствующие (false)",
+                        "name": "available",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.PeriodBookCount"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid filter",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/stats/books/new-arrivals": {
+            "get": {
+                "description": "Возвращает количество книг, добавленных в каталог, за каждый из последних months месяцев, включая текущий; месяцы без поступлений идут с нулем. Принимает те же фильтры, что и список книг.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "stats"
+                ],
+                "summary": "Новые поступления по месяцам",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "default": 12,
+                        "description": "Количество месяцев (не больше 120)",
+                        "name": "months",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Фильтр по заголовку книги",
+                        "name": "title",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Фильтр по автору",
+                        "name": "author",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Год издания от",
+                        "name": "startYear",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Год издания до",
+                        "name": "endYear",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Фильтр по издателю",
+                        "name": "publisher_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Фильтр по рубрике",
+                        "name": "category_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Фильтр по произведению",
+                        "name": "work_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Фильтр по серии",
+                        "name": "series_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Фильтр по языку текста книги",
+                        "name": "language",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Фильтр по тегам (через запятую или повтором параметра)",
+                        "name": "tags",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "all",
+                            "any"
+                        ],
+                        "type": "string",
+                        "default": "all",
+                        "description": "all — книги со всеми тегами, any — с любым из них",
+                        "name": "tag_mode",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Только книги в наличии (true) или отсутствующие (false)",
+                        "name": "available",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.MonthBookCount"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid filter",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tags": {
+            "get": {
+                "description": "Возвращает теги с числом книг каталога, от самых популярных.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tags"
+                ],
+                "summary": "Облако тегов",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "default": 0,
+                        "description": "Максимальное количество тегов (0 — без ограничения)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Минимальное число книг с тегом",
+                        "name": "min_count",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/services.TagCount"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/tags/{id}": {
+            "put": {
+                "description": "Переименовывает тег у всех книг сразу. Если тег с новым названием уже есть, возвращается 409 — такие теги нужно объединить.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tags"
+                ],
+                "summary": "Переименование тега",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Идентификатор тега",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Новое название",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.RenameTagRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/services.Tag"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Tag not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Tag already exists, merge the tags instead",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Удаляет тег и снимает его со всех книг.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tags"
+                ],
+                "summary": "Удаление тега",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Идентификатор тега",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Tag deleted",
+                        "schema": {
+                            "$ref": "#/definitions/models.MessageResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Tag not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tags/{id}/merge": {
+            "post": {
+                "description": "Переносит все книги с тега на тег into_id и удаляет исходный тег.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tags"
+                ],
+                "summary": "Объединение тегов",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Идентификатор объединяемого тега",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Тег, в который вливается исходный",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.MergeTagsRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/services.Tag"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Tag not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/works": {
+            "get": {
+                "description": "Возвращает произведения с пагинацией; параметр title фильтрует по названию.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "works"
+                ],
+                "summary": "Получение списка произведений",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Номер страницы",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Количество произведений на странице",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Фильтр по названию",
+                        "name": "title",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "total\": int64, \"page\": int, \"limit\": int",
+                        "schema": {
+                            "$ref": "#/definitions/services.Work"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Создает произведение, к которому затем привязываются издания через поле work_id книги.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "works"
+                ],
+                "summary": "Создание произведения",
+                "parameters": [
+                    {
+                        "description": "Данные произведения",
+                        "name": "work",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/services.Work"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/services.Work"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/works/{id}": {
+            "put": {
+                "description": "Обновляет название, автора и описание произведения.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "works"
+                ],
+                "summary": "Обновление произведения по ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Идентификатор произведения",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Обновленные данные произведения",
+                        "name": "work",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/services.Work"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/services.Work"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Work not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Удаляет произведение; его издания остаются в каталоге как отдельные книги, их версии увеличиваются, а изменения попадают в историю.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "works"
+                ],
+                "summary": "Удаление произведения по ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Идентификатор произведения",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Work deleted",
+                        "schema": {
+                            "$ref": "#/definitions/models.MessageResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Work not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/works/{id}/editions": {
+            "get": {
+                "description": "Возвращает все издания произведения, от новых к старым.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "works"
+                ],
+                "summary": "Издания произведения",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Идентификатор произведения",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Валюта отображения цен (ISO 4217); приоритетнее Accept-Currency",
+                        "name": "currency",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Предпочитаемые валюты отображения цен через запятую",
+                        "name": "Accept-Currency",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Язык названия и описания (тег BCP 47); приоритетнее Accept-Language",
+                        "name": "lang",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Предпочитаемые языки; без подходящего перевода отдается оригинал",
+                        "name": "Accept-Language",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/services.Book"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Work not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "models.AddCartItemRequest": {
+            "type": "object",
+            "required": [
+                "book_id"
+            ],
+            "properties": {
+                "book_id": {
+                    "type": "integer"
+                },
+                "quantity": {
+                    "description": "По умолчанию 1",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.AuthorBookCount": {
+            "type": "object",
+            "properties": {
+                "author_id": {
+                    "type": "integer"
+                },
+                "count": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.AuthorRating": {
+            "type": "object",
+            "properties": {
+                "author_id": {
+                    "type": "integer"
+                },
+                "average_rating": {
+                    "type": "number"
+                },
+                "books": {
+                    "description": "Книги автора, у которых есть отзывы",
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "reviews": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.BookAuthorInput": {
+            "type": "object",
+            "required": [
+                "author_id"
+            ],
+            "properties": {
+                "author_id": {
+                    "type": "integer"
+                },
+                "role": {
+                    "description": "author, translator, illustrator или editor; по умолчанию author",
+                    "type": "string"
+                }
+            }
+        },
+        "models.BookFilter": {
+            "type": "object",
+            "properties": {
+                "author": {
+                    "type": "string"
+                },
+                "available": {
+                    "type": "boolean"
+                },
+                "category_id": {
+                    "type": "integer"
+                },
+                "end_year": {
+                    "type": "integer"
+                },
+                "exact": {
+                    "description": "Отключает поиск с опечатками по названию и автору",
+                    "type": "boolean"
+                },
+                "language": {
+                    "description": "Язык текста; en подходит и для en-US, и для en-GB",
+                    "type": "string"
+                },
+                "publisher_id": {
+                    "type": "integer"
+                },
+                "series_id": {
+                    "type": "integer"
+                },
+                "start_year": {
+                    "type": "integer"
+                },
+                "tag_mode": {
+                    "description": "all — все теги сразу (по умолчанию), any — любой из них",
+                    "type": "string",
+                    "enum": [
+                        "all",
+                        "any"
+                    ]
+                },
+                "tags": {
+                    "description": "Теги; можно повторять параметр или перечислять через запятую",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "title": {
+                    "type": "string"
+                },
+                "work_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.BookInput": {
+            "type": "object",
+            "properties": {
+                "author": {
+                    "type": "string"
+                },
+                "category_id": {
+                    "type": "integer"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "isbn10": {
+                    "type": "string"
+                },
+                "isbn13": {
+                    "type": "string"
+                },
+                "language": {
+                    "description": "Язык названия и описания, тег BCP 47 (ru, en-US)",
+                    "type": "string"
+                },
+                "publisher_id": {
+                    "type": "integer"
+                },
+                "series_id": {
+                    "type": "integer"
+                },
+                "series_volume": {
+                    "description": "SeriesVolume — номер тома в серии; допустим только вместе с SeriesID",
+                    "type": "integer"
+                },
+                "title": {
+                    "type": "string"
+                },
+                "work_id": {
+                    "type": "integer"
+                },
+                "year": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.BookTagsRequest": {
+            "type": "object",
+            "required": [
+                "tags"
+            ],
+            "properties": {
+                "tags": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "models.BookTranslationRequest": {
+            "type": "object",
+            "required": [
+                "title"
+            ],
+            "properties": {
+                "description": {
+                    "type": "string"
+                },
+                "title": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.CancelOrderRequest": {
+            "type": "object",
+            "properties": {
+                "note": {
+                    "description": "Причина отмены",
+                    "type": "string"
+                }
+            }
+        },
+        "models.Category": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "products": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Product"
+                    }
+                }
+            }
+        },
+        "models.CategoryBookCount": {
+            "type": "object",
+            "properties": {
+                "category_id": {
+                    "description": "nil — книги без рубрики",
+                    "type": "integer"
+                },
+                "count": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.CreateOrderRequest": {
+            "type": "object",
+            "properties": {
+                "products": {
+                    "description": "Опциональный список продуктов",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ProductInOrder"
+                    }
+                }
+            }
+        },
+        "models.DownloadLinkResponse": {
+            "type": "object",
+            "properties": {
+                "expires_at": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.ErrorResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "Код ошибки, например, 400 или 500",
+                    "type": "integer"
+                },
+                "message": {
+                    "description": "Сообщение об ошибке",
+                    "type": "string"
+                }
+            }
+        },
+        "models.GrantEntitlementRequest": {
+            "type": "object",
+            "required": [
+                "username"
+            ],
+            "properties": {
+                "expires_at": {
+                    "description": "Окончание доступа; обязательно для loan",
+                    "type": "string"
+                },
+                "source": {
+                    "description": "loan или grant; по умолчанию grant",
+                    "type": "string"
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.ImportBooksResponse": {
+            "type": "object",
+            "properties": {
+                "created": {
+                    "type": "integer"
+                },
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ImportError"
+                    }
+                },
+                "skipped": {
+                    "type": "integer"
+                },
+                "updated": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.ImportError": {
+            "type": "object",
+            "properties": {
+                "index": {
+                    "description": "Позиция записи во входном массиве",
+                    "type": "integer"
+                },
+                "message": {
+                    "description": "Причина, по которой запись пропущена",
+                    "type": "string"
+                }
+            }
+        },
+        "models.MergeTagsRequest": {
+            "type": "object",
+            "required": [
+                "into_id"
+            ],
+            "properties": {
+                "into_id": {
+                    "description": "Тег, в который вливается исходный",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.MessageResponse": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.MonthBookCount": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "month": {
+                    "description": "Месяц в формате YYYY-MM",
+                    "type": "string"
+                }
+            }
+        },
+        "models.Order": {
+            "type": "object",
+            "properties": {
+                "cancelled_at": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "currency": {
+                    "description": "Currency и ExchangeRate фиксируют валюту заказа и курс пересчета из базовой валюты\nмагазина на момент покупки, чтобы последующие изменения курсов не меняли сумму заказа",
+                    "type": "string"
+                },
+                "delivered_at": {
+                    "type": "string"
+                },
+                "exchange_rate": {
+                    "type": "number"
+                },
+                "history": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.OrderStatusChange"
+                    }
+                },
+                "order_id": {
+                    "type": "integer"
+                },
+                "packed_at": {
+                    "type": "string"
+                },
+                "paid_at": {
+                    "type": "string"
+                },
+                "products": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.OrderProduct"
+                    }
+                },
+                "refunded_at": {
+                    "type": "string"
+                },
+                "shipped_at": {
+                    "type": "string"
+                },
+                "status": {
+                    "description": "Status меняется только через services.TransitionOrder; время перехода в каждый статус\nхранится в отдельном поле, вся последовательность изменений — в History",
+                    "type": "string"
+                },
+                "total": {
+                    "description": "Total — сумма заказа в минимальных единицах Currency; считается сервером по строкам заказа",
+                    "type": "integer"
+                },
+                "user_id": {
+                    "type": "integer"
+                },
+                "username": {
+                    "description": "Владелец заказа; пользователи идентифицируются по имени",
+                    "type": "string"
+                }
+            }
+        },
+        "models.OrderProduct": {
+            "type": "object",
+            "properties": {
+                "line_total": {
+                    "type": "integer"
+                },
+                "order_id": {
+                    "type": "integer"
+                },
+                "product": {
+                    "$ref": "#/definitions/models.Product"
+                },
+                "product_id": {
+                    "type": "integer"
+                },
+                "quantity": {
+                    "type": "integer"
+                },
+                "title": {
+                    "type": "string"
+                },
+                "unit_price": {
+                    "description": "Цена за экземпляр в валюте заказа",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.OrderResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Order"
+                    }
+                },
+                "limit": {
+                    "type": "integer"
+                },
+                "page": {
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.OrderStatusChange": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "from": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "note": {
+                    "type": "string"
+                },
+                "order_id": {
+                    "type": "integer"
+                },
+                "to": {
+                    "type": "string"
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.OrderStatusRequest": {
+            "type": "object",
+            "required": [
+                "status"
+            ],
+            "properties": {
+                "note": {
+                    "type": "string"
+                },
+                "status": {
+                    "description": "paid, packed, shipped, delivered, cancelled или refunded",
+                    "type": "string"
+                }
+            }
+        },
+        "models.PeriodBookCount": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "period": {
+                    "description": "Год или первый год десятилетия",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.Product": {
+            "type": "object",
+            "properties": {
+                "category_id": {
+                    "type": "integer"
+                },
+                "currency": {
+                    "description": "Код валюты ISO 4217",
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "manufacturer": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "price": {
+                    "description": "Цена в минимальных единицах валюты (копейки, тиыны, центы)",
+                    "type": "integer"
+                },
+                "rating": {
+                    "type": "number"
+                }
+            }
+        },
+        "models.ProductInOrder": {
+            "type": "object",
+            "properties": {
+                "product_id": {
+                    "type": "integer"
+                },
+                "quantity": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.PublisherBookCount": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "publisher_id": {
+                    "description": "nil — книги без издателя",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.ReassignPublisherRequest": {
+            "type": "object",
+            "required": [
+                "publisher_id"
+            ],
+            "properties": {
+                "filter": {
+                    "$ref": "#/definitions/models.BookFilter"
+                },
+                "publisher_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.ReassignPublisherResponse": {
+            "type": "object",
+            "properties": {
+                "updated": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.RenameTagRequest": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.SetBookAuthorsRequest": {
+            "type": "object",
+            "properties": {
+                "authors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.BookAuthorInput"
+                    }
+                }
+            }
+        },
+        "models.SetCurrencyRateRequest": {
+            "type": "object",
+            "required": [
+                "rate"
+            ],
+            "properties": {
+                "rate": {
+                    "description": "Стоимость единицы валюты в базовой валюте магазина",
+                    "type": "number"
+                },
+                "round_to": {
+                    "description": "Шаг округления в минимальных единицах; по умолчанию 1",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.SetPriceRequest": {
+            "type": "object",
+            "required": [
+                "amount"
+            ],
+            "properties": {
+                "amount": {
+                    "description": "Цена в минимальных единицах валюты",
+                    "type": "integer"
+                },
+                "currency": {
+                    "description": "Код валюты ISO 4217; по умолчанию PRICE_DEFAULT_CURRENCY",
+                    "type": "string"
+                },
+                "ends_at": {
+                    "description": "Окончание действия; обязательно для распродажи",
+                    "type": "string"
+                },
+                "kind": {
+                    "description": "regular или sale; по умолчанию regular",
+                    "type": "string"
+                },
+                "starts_at": {
+                    "description": "Начало действия; по умолчанию сразу",
+                    "type": "string"
+                }
+            }
+        },
+        "models.StockAdjustmentRequest": {
+            "type": "object",
+            "required": [
+                "delta",
+                "reason"
+            ],
+            "properties": {
+                "delta": {
+                    "description": "Изменение остатка: положительное — приход, отрицательное — списание",
+                    "type": "integer"
+                },
+                "note": {
+                    "type": "string"
+                },
+                "reason": {
+                    "description": "receiving, damage или correction",
+                    "type": "string"
+                }
+            }
+        },
+        "models.TokenResponse": {
+            "type": "object",
+            "properties": {
+                "token": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.UpdateProductQuantityRequest": {
+            "type": "object",
+            "properties": {
+                "quantity": {
+                    "type": "integer"
+                }
+            }
+        },
+        "services.Author": {
+            "type": "object",
+            "properties": {
+                "alternative_names": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "bio": {
+                    "type": "string"
+                },
+                "birth_year": {
+                    "type": "integer"
+                },
+                "books": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/services.BookAuthor"
+                    }
+                },
+                "death_year": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "services.Book": {
+            "type": "object",
+            "properties": {
+                "author": {
+                    "description": "Author — строка авторов в том виде, в каком она напечатана на книге;\nструктурированные связи с авторами хранятся в Authors",
+                    "type": "string"
+                },
+                "authors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/services.BookAuthor"
+                    }
+                },
+                "available": {
+                    "type": "boolean"
+                },
+                "category": {
+                    "$ref": "#/definitions/models.Category"
+                },
+                "category_id": {
+                    "type": "integer"
+                },
+                "cover": {
+                    "$ref": "#/definitions/services.BookCover"
+                },
+                "created_at": {
+                    "description": "CreatedAt — момент появления книги в каталоге",
+                    "type": "string"
+                },
+                "currency": {
+                    "type": "string"
+                },
+                "deleted_at": {
+                    "description": "DeletedAt включает мягкое удаление: такие книги скрыты из обычных запросов",
+                    "type": "string"
+                },
+                "description": {
+                    "description": "Description и Title даны на языке Language (тег BCP 47); переводы хранятся в Translations",
+                    "type": "string"
+                },
+                "edition_count": {
+                    "description": "EditionCount — число изданий произведения; заполняется только в списке со свернутыми изданиями",
+                    "type": "integer"
+                },
+                "files": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/services.BookFile"
+                    }
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "isbn10": {
+                    "type": "string"
+                },
+                "isbn13": {
+                    "description": "ISBN13 хранится в каноническом виде и уникален среди неудаленных книг; ISBN10 вычисляется из него",
+                    "type": "string"
+                },
+                "language": {
+                    "type": "string"
+                },
+                "locale": {
+                    "description": "Locale — язык, на котором отданы название и описание; OriginalTitle заполняется, если отдан перевод",
+                    "type": "string"
+                },
+                "on_sale": {
+                    "type": "boolean"
+                },
+                "original_title": {
+                    "type": "string"
+                },
+                "price": {
+                    "description": "Price — действующая цена в минимальных единицах валюты, RegularPrice — базовая цена\nбез учета распродажи. Оба поля вычисляются из истории цен (см. ApplyBookPrices)\nи не редактируются напрямую; nil означает, что цена не назначена.",
+                    "type": "integer"
+                },
+                "publisher": {
+                    "$ref": "#/definitions/services.Publisher"
+                },
+                "publisher_id": {
+                    "description": "PublisherID ссылается на издателя; при удалении издателя ссылка обнуляется",
+                    "type": "integer"
+                },
+                "regular_price": {
+                    "type": "integer"
+                },
+                "series": {
+                    "$ref": "#/definitions/services.Series"
+                },
+                "series_id": {
+                    "type": "integer"
+                },
+                "series_volume": {
+                    "type": "integer"
+                },
+                "stock": {
+                    "description": "Stock — количество экземпляров на складе; меняется только через AdjustStock,\nчтобы каждое изменение попадало в журнал. Остаток и наличие входят в представление\nкниги, поэтому изменение остатка увеличивает версию.",
+                    "type": "integer"
+                },
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/services.Tag"
+                    }
+                },
+                "title": {
+                    "type": "string"
+                },
+                "version": {
+                    "description": "Version увеличивается при каждом изменении книги и используется для ETag/If-Match",
+                    "type": "integer"
+                },
+                "work": {
+                    "$ref": "#/definitions/services.Work"
+                },
+                "work_id": {
+                    "description": "WorkID объединяет издания одного произведения, SeriesID и SeriesVolume задают место книги в серии",
+                    "type": "integer"
+                },
+                "year": {
+                    "type": "integer"
+                }
+            }
+        },
+        "services.BookAuthor": {
+            "type": "object",
+            "properties": {
+                "author": {
+                    "$ref": "#/definitions/services.Author"
+                },
+                "author_id": {
+                    "type": "integer"
+                },
+                "book": {
+                    "$ref": "#/definitions/services.Book"
+                },
+                "book_id": {
+                    "type": "integer"
+                },
+                "position": {
+                    "type": "integer"
+                },
+                "role": {
+                    "type": "string"
+                }
+            }
+        },
+        "services.BookCover": {
+            "type": "object",
+            "properties": {
+                "content_type": {
+                    "type": "string"
+                },
+                "height": {
+                    "type": "integer"
+                },
+                "medium_url": {
+                    "type": "string"
+                },
+                "small_url": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
+                },
+                "width": {
+                    "type": "integer"
+                }
+            }
+        },
+        "services.BookEntitlement": {
+            "type": "object",
+            "properties": {
+                "book_id": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "description": "nil — бессрочно",
+                    "type": "string"
+                },
+                "granted_by": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "order_id": {
+                    "type": "integer"
+                },
+                "source": {
+                    "type": "string"
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "services.BookFile": {
+            "type": "object",
+            "properties": {
+                "book_id": {
+                    "type": "integer"
+                },
+                "content_type": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "format": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "size": {
+                    "type": "integer"
+                }
             }
-        }
-    },
-    "definitions": {
-        "models.ErrorResponse": {
+        },
+        "services.BookPrice": {
             "type": "object",
             "properties": {
-                "code": {
-                    "description": "Код ошибки, например, 400 или 500",
+                "amount": {
                     "type": "integer"
                 },
-                "message": {
-                    "description": "Сообщение об ошибке",
+                "book_id": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "currency": {
+                    "type": "string"
+                },
+                "ends_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "kind": {
+                    "type": "string"
+                },
+                "starts_at": {
+                    "type": "string"
+                },
+                "username": {
                     "type": "string"
                 }
             }
         },
-        "models.MessageResponse": {
+        "services.BookRef": {
             "type": "object",
             "properties": {
-                "message": {
+                "id": {
+                    "type": "integer"
+                },
+                "title": {
                     "type": "string"
                 }
             }
         },
-        "models.Order": {
+        "services.BookRevision": {
             "type": "object",
             "properties": {
-                "order_id": {
+                "action": {
+                    "type": "string"
+                },
+                "book_id": {
                     "type": "integer"
                 },
-                "products": {
+                "changes": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/models.OrderProduct"
+                        "$ref": "#/definitions/services.FieldChange"
                     }
                 },
-                "user_id": {
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
                     "type": "integer"
+                },
+                "snapshot": {
+                    "type": "object",
+                    "additionalProperties": true
+                },
+                "username": {
+                    "type": "string"
                 }
             }
         },
-        "models.OrderProduct": {
+        "services.BookTranslation": {
             "type": "object",
             "properties": {
-                "order_id": {
+                "book_id": {
                     "type": "integer"
                 },
-                "product": {
-                    "$ref": "#/definitions/models.Product"
+                "description": {
+                    "type": "string"
                 },
-                "product_id": {
+                "locale": {
+                    "type": "string"
+                },
+                "title": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "services.Cart": {
+            "type": "object",
+            "properties": {
+                "currency": {
+                    "description": "Currency и Total — валюта и сумма оформляемых строк; Ready — можно ли оформить корзину",
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/services.CartItem"
+                    }
+                },
+                "ready": {
+                    "type": "boolean"
+                },
+                "token": {
+                    "type": "string"
+                },
+                "total": {
+                    "type": "integer"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "services.CartItem": {
+            "type": "object",
+            "properties": {
+                "added_currency": {
+                    "type": "string"
+                },
+                "added_price": {
+                    "type": "integer"
+                },
+                "book": {
+                    "$ref": "#/definitions/services.Book"
+                },
+                "book_id": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "issue": {
+                    "type": "string"
+                },
+                "line_total": {
                     "type": "integer"
                 },
                 "quantity": {
                     "type": "integer"
+                },
+                "unit_price": {
+                    "description": "UnitPrice и LineTotal — текущая цена в валюте корзины; Issue — причина, по которой\nстроку нельзя оформить, или price_changed, если цена изменилась после добавления",
+                    "type": "integer"
                 }
             }
         },
-        "models.Product": {
+        "services.Credentials": {
             "type": "object",
             "properties": {
-                "category_id": {
+                "password": {
+                    "type": "string"
+                },
+                "role": {
+                    "type": "string"
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "services.CurrencyRate": {
+            "type": "object",
+            "properties": {
+                "currency": {
+                    "type": "string"
+                },
+                "rate": {
+                    "description": "Rate — стоимость одной единицы валюты в базовой валюте, например 98.5 для EUR при базовой RUB",
+                    "type": "number"
+                },
+                "round_to": {
+                    "description": "RoundTo — шаг округления сконвертированных цен в минимальных единицах валюты:\n1 — до копейки/цента, 100 — до целых единиц",
                     "type": "integer"
                 },
-                "description": {
+                "updated_at": {
                     "type": "string"
                 },
-                "id": {
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "services.DownloadStat": {
+            "type": "object",
+            "properties": {
+                "count": {
                     "type": "integer"
                 },
-                "manufacturer": {
+                "last_download_at": {
+                    "type": "string"
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "services.FieldChange": {
+            "type": "object",
+            "properties": {
+                "field": {
+                    "type": "string"
+                },
+                "new": {},
+                "old": {}
+            }
+        },
+        "services.Publisher": {
+            "type": "object",
+            "properties": {
+                "books": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/services.Book"
+                    }
+                },
+                "country": {
                     "type": "string"
                 },
+                "id": {
+                    "type": "integer"
+                },
                 "name": {
                     "type": "string"
                 },
-                "price": {
-                    "type": "number"
+                "website": {
+                    "type": "string"
+                }
+            }
+        },
+        "services.Recommendation": {
+            "type": "object",
+            "properties": {
+                "because": {
+                    "$ref": "#/definitions/services.BookRef"
                 },
-                "rating": {
+                "book": {
+                    "$ref": "#/definitions/services.Book"
+                },
+                "explanation": {
+                    "type": "string"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "score": {
                     "type": "number"
                 }
             }
         },
-        "models.TokenResponse": {
+        "services.Series": {
             "type": "object",
             "properties": {
-                "token": {
+                "books": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/services.Book"
+                    }
+                },
+                "description": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
                     "type": "string"
                 }
             }
         },
-        "services.Book": {
+        "services.StockMovement": {
             "type": "object",
             "properties": {
-                "author": {
+                "balance": {
+                    "description": "Остаток после движения",
+                    "type": "integer"
+                },
+                "book_id": {
+                    "type": "integer"
+                },
+                "created_at": {
                     "type": "string"
                 },
+                "delta": {
+                    "type": "integer"
+                },
                 "id": {
                     "type": "integer"
                 },
-                "publisher": {
+                "note": {
+                    "type": "string"
+                },
+                "order_id": {
                     "type": "integer"
                 },
-                "title": {
+                "reason": {
                     "type": "string"
                 },
-                "year": {
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "services.Suggestion": {
+            "type": "object",
+            "properties": {
+                "id": {
                     "type": "integer"
+                },
+                "text": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
                 }
             }
         },
-        "services.Credentials": {
+        "services.Tag": {
             "type": "object",
             "properties": {
-                "password": {
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
                     "type": "string"
+                }
+            }
+        },
+        "services.TagCount": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
                 },
-                "role": {
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
                     "type": "string"
+                }
+            }
+        },
+        "services.WishlistItem": {
+            "type": "object",
+            "properties": {
+                "book": {
+                    "$ref": "#/definitions/services.Book"
                 },
-                "username": {
+                "book_id": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "services.Work": {
+            "type": "object",
+            "properties": {
+                "author": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "editions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/services.Book"
+                    }
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "title": {
                     "type": "string"
                 }
             }
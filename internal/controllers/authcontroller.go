@@ -2,6 +2,7 @@ package controllers
 
 import (
 	"Projectmugen/internal/services"
+	"log"
 	"net/http"
 	"time"
 
@@ -15,6 +16,7 @@ import (
 // @Accept json
 // @Produce json
 // @Param creds body services.Credentials true "Учетные данные пользователя"
+// @Param X-Cart-Token header string false "Токен анонимной корзины; ее книги переносятся в корзину пользователя"
 // @Success 200 {object} models.TokenResponse "токен доступа"
 // @Failure 400 {object} models.ErrorResponse "invalid request"
 // @Failure 401 {object} models.ErrorResponse "unauthorized"
@@ -45,6 +47,13 @@ func Login(c *gin.Context) {
 		return
 	}
 
+	// Анонимная корзина переносится в корзину пользователя; ошибка переноса не мешает входу
+	if cartToken := c.GetHeader(CartTokenHeader); cartToken != "" {
+		if err := services.MergeGuestCart(cartToken, creds.Username); err != nil {
+			log.Println("cart merge:", err)
+		}
+	}
+
 	c.JSON(http.StatusOK, gin.H{"token": token})
 }
 
@@ -84,10 +93,19 @@ func AuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
+		// Сохраняем данные пользователя для обработчиков
+		c.Set("username", claims.Username)
+		c.Set("role", claims.Role)
+
 		c.Next()
 	}
 }
 
+// currentUsername возвращает имя пользователя, установленное AuthMiddleware.
+func currentUsername(c *gin.Context) string {
+	return c.GetString("username")
+}
+
 var Users = map[string]string{
 	"admin":    "admin123",
 	"user":     "password",
@@ -0,0 +1,251 @@
+package controllers
+
+import (
+	"Projectmugen/internal/models"
+	"Projectmugen/internal/services"
+	"Projectmugen/internal/utils"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// GetAuthors обрабатывает запрос на получение списка авторов.
+// @Summary Получение списка авторов
+// @Description Возвращает список авторов с пагинацией; фильтр по имени учитывает альтернативные написания.
+// @Tags authors
+// @Accept json
+// @Produce json
+// @Param page query int false "Номер страницы" default(1)
+// @Param limit query int false "Количество авторов на странице" default(10)
+// @Param name query string false "Фильтр по имени"
+// @Success 200 {object} services.Author "total": int64, "page": int, "limit": int
+// @Router /authors [get]
+func GetAuthors(c *gin.Context) {
+	var authors []services.Author
+	var total int64
+
+	pageInt, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limitInt, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	offset := (pageInt - 1) * limitInt
+
+	query := services.Db.Model(&services.Author{})
+	if name := c.Query("name"); name != "" {
+		query = query.Where("name ILIKE ? OR alternative_names ILIKE ?", "%"+name+"%", "%"+name+"%")
+	}
+
+	query.Count(&total)
+	query.Order("name asc").Limit(limitInt).Offset(offset).Find(&authors)
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":  authors,
+		"total": total,
+		"page":  pageInt,
+		"limit": limitInt,
+	})
+}
+
+// GetAuthorByID обрабатывает запрос на получение автора вместе с его книгами.
+// @Summary Получение автора по ID
+// @Description Возвращает автора и список книг, в которых он участвовал, с указанием роли.
+// @Tags authors
+// @Accept json
+// @Produce json
+// @Param id path string true "Идентификатор автора"
+// @Success 200 {object} services.Author
+// @Failure 404 {object} models.ErrorResponse "Author not found"
+// @Router /authors/{id} [get]
+func GetAuthorByID(c *gin.Context) {
+	var author services.Author
+	if err := services.Db.Preload("Books.Book").First(&author, c.Param("id")).Error; err != nil {
+		utils.HandleError(c, http.StatusNotFound, "Author not found")
+		return
+	}
+	c.JSON(http.StatusOK, author)
+}
+
+// CreateAuthor обрабатывает запрос на создание автора.
+// @Summary Создание автора
+// @Description Создает нового автора.
+// @Tags authors
+// @Accept json
+// @Produce json
+// @Param author body services.Author true "Данные автора"
+// @Success 201 {object} services.Author
+// @Failure 400 {object} models.ErrorResponse "Invalid request"
+// @Router /authors [post]
+func CreateAuthor(c *gin.Context) {
+	var author services.Author
+	if err := c.BindJSON(&author); err != nil || author.Name == "" {
+		utils.HandleError(c, http.StatusBadRequest, "Invalid request")
+		return
+	}
+	author.ID = 0
+	author.Books = nil
+	author.NameKey = services.AuthorNameKey(author.Name)
+	if author.AlternativeNames == nil {
+		author.AlternativeNames = []string{}
+	}
+
+	if err := services.Db.Create(&author).Error; err != nil {
+		utils.HandleError(c, http.StatusInternalServerError, "Failed to save author")
+		return
+	}
+	c.JSON(http.StatusCreated, author)
+}
+
+// UpdateAuthor обрабатывает запрос на обновление автора.
+// @Summary Обновление автора по ID
+// @Description Обновляет имя, альтернативные имена, биографию и годы жизни автора.
+// @Tags authors
+// @Accept json
+// @Produce json
+// @Param id path string true "Идентификатор автора"
+// @Param author body services.Author true "Обновленные данные автора"
+// @Success 200 {object} services.Author
+// @Failure 400 {object} models.ErrorResponse "Invalid request"
+// @Failure 404 {object} models.ErrorResponse "Author not found"
+// @Router /authors/{id} [put]
+func UpdateAuthor(c *gin.Context) {
+	var author services.Author
+	if err := services.Db.First(&author, c.Param("id")).Error; err != nil {
+		utils.HandleError(c, http.StatusNotFound, "Author not found")
+		return
+	}
+
+	var input services.Author
+	if err := c.BindJSON(&input); err != nil || input.Name == "" {
+		utils.HandleError(c, http.StatusBadRequest, "Invalid request")
+		return
+	}
+
+	author.Name = input.Name
+	author.NameKey = services.AuthorNameKey(input.Name)
+	author.AlternativeNames = input.AlternativeNames
+	if author.AlternativeNames == nil {
+		author.AlternativeNames = []string{}
+	}
+	author.Bio = input.Bio
+	author.BirthYear = input.BirthYear
+	author.DeathYear = input.DeathYear
+	err := services.Db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&author).Error; err != nil {
+			return err
+		}
+		return services.BumpAuthorBooks(tx, author.ID)
+	})
+	if err != nil {
+		utils.HandleError(c, http.StatusInternalServerError, "Failed to save author")
+		return
+	}
+	c.JSON(http.StatusOK, author)
+}
+
+// DeleteAuthor обрабатывает запрос на удаление автора.
+// @Summary Удаление автора по ID
+// @Description Удаляет автора вместе с его связями с книгами.
+// @Tags authors
+// @Accept json
+// @Produce json
+// @Param id path string true "Идентификатор автора"
+// @Success 200 {object} models.MessageResponse "Author deleted"
+// @Failure 404 {object} models.ErrorResponse "Author not found"
+// @Router /authors/{id} [delete]
+func DeleteAuthor(c *gin.Context) {
+	var author services.Author
+	if err := services.Db.First(&author, c.Param("id")).Error; err != nil {
+		utils.HandleError(c, http.StatusNotFound, "Author not found")
+		return
+	}
+
+	// Версии книг увеличиваются до удаления: после него связи с автором уже не найти
+	err := services.Db.Transaction(func(tx *gorm.DB) error {
+		if err := services.BumpAuthorBooks(tx, author.ID); err != nil {
+			return err
+		}
+		return tx.Delete(&author).Error
+	})
+	if err != nil {
+		utils.HandleError(c, http.StatusInternalServerError, "Failed to delete author")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Author deleted"})
+}
+
+// SetBookAuthors обрабатывает запрос на замену списка авторов книги.
+// @Summary Назначение авторов книги
+// @Description Полностью заменяет список участников книги (авторы, переводчики, иллюстраторы, редакторы). Порядок в запросе сохраняется. Версия книги увеличивается, изменение попадает в историю.
+// @Tags authors
+// @Accept json
+// @Produce json
+// @Param id path string true "Идентификатор книги"
+// @Param request body models.SetBookAuthorsRequest true "Участники книги"
+// @Success 200 {array} services.BookAuthor
+// @Failure 400 {object} models.ErrorResponse "Invalid request"
+// @Failure 404 {object} models.ErrorResponse "Book not found"
+// @Router /books/{id}/authors [put]
+func SetBookAuthors(c *gin.Context) {
+	var book services.Book
+	if err := services.Db.First(&book, c.Param("id")).Error; err != nil {
+		utils.HandleError(c, http.StatusNotFound, "Book not found")
+		return
+	}
+
+	var req models.SetBookAuthorsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.HandleError(c, http.StatusBadRequest, "Invalid request")
+		return
+	}
+
+	links := make([]services.BookAuthor, 0, len(req.Authors))
+	seen := make(map[services.BookAuthor]bool)
+	for i, input := range req.Authors {
+		role := input.Role
+		if role == "" {
+			role = services.RoleAuthor
+		}
+		if !services.ValidAuthorRole(role) {
+			utils.HandleError(c, http.StatusBadRequest, "Invalid role: "+role)
+			return
+		}
+		key := services.BookAuthor{BookID: book.ID, AuthorID: input.AuthorID, Role: role}
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		key.Position = i
+		links = append(links, key)
+	}
+
+	err := services.Db.Transaction(func(tx *gorm.DB) error {
+		if err := services.BumpBookVersion(tx, book.ID, 0); err != nil {
+			return err
+		}
+		var before []services.BookAuthor
+		if err := tx.Where("book_id = ?", book.ID).Order("position asc").Find(&before).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("book_id = ?", book.ID).Delete(&services.BookAuthor{}).Error; err != nil {
+			return err
+		}
+		if len(links) > 0 {
+			if err := tx.Create(&links).Error; err != nil {
+				return err
+			}
+		}
+		return services.RecordBookAuthorsRevision(tx, currentUsername(c), &book, before, links)
+	})
+	if errors.Is(err, gorm.ErrForeignKeyViolated) {
+		utils.HandleError(c, http.StatusBadRequest, "Author not found")
+		return
+	}
+	if err != nil {
+		utils.HandleError(c, http.StatusInternalServerError, "Failed to save authors")
+		return
+	}
+
+	services.Db.Preload("Author").Where("book_id = ?", book.ID).Order("position asc").Find(&links)
+	c.JSON(http.StatusOK, links)
+}
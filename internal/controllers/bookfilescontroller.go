@@ -0,0 +1,335 @@
+package controllers
+
+import (
+	"Projectmugen/internal/models"
+	"Projectmugen/internal/services"
+	"Projectmugen/internal/storage"
+	"Projectmugen/internal/utils"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// UploadBookFile обрабатывает загрузку электронной версии книги.
+// @Summary Загрузка файла книги
+// @Description Принимает файл EPUB или PDF и сохраняет его в закрытом хранилище. Файл того же формата заменяется; версия книги увеличивается, изменение записывается в историю.
+// @Tags files
+// @Accept multipart/form-data
+// @Produce json
+// @Param id path string true "Идентификатор книги"
+// @Param file formData file true "Файл EPUB или PDF"
+// @Success 200 {object} services.BookFile
+// @Failure 400 {object} models.ErrorResponse "File is required"
+// @Failure 404 {object} models.ErrorResponse "Book not found"
+// @Failure 413 {object} models.ErrorResponse "File too large"
+// @Failure 415 {object} models.ErrorResponse "only EPUB and PDF files are supported"
+// @Router /books/{id}/files [post]
+func UploadBookFile(c *gin.Context) {
+	var book services.Book
+	if err := services.Db.First(&book, c.Param("id")).Error; err != nil {
+		utils.HandleError(c, http.StatusNotFound, "Book not found")
+		return
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		utils.HandleError(c, http.StatusBadRequest, "File is required")
+		return
+	}
+	if fileHeader.Size > services.BookFileMaxBytes {
+		utils.HandleError(c, http.StatusRequestEntityTooLarge, "File too large")
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		utils.HandleError(c, http.StatusBadRequest, "Invalid file")
+		return
+	}
+	defer file.Close()
+
+	saved, err := services.SaveBookFile(c.Request.Context(), book.ID, currentUsername(c), io.LimitReader(file, fileHeader.Size), fileHeader.Size)
+	if errors.Is(err, services.ErrUnsupportedBookFile) {
+		utils.HandleError(c, http.StatusUnsupportedMediaType, err.Error())
+		return
+	}
+	if err != nil {
+		utils.HandleError(c, http.StatusInternalServerError, "Failed to save file")
+		return
+	}
+	c.JSON(http.StatusOK, saved)
+}
+
+// DeleteBookFile обрабатывает удаление электронной версии книги.
+// @Summary Удаление файла книги
+// @Description Удаляет файл книги из хранилища, увеличивает версию книги и записывает изменение в историю.
+// @Tags files
+// @Accept json
+// @Produce json
+// @Param id path string true "Идентификатор книги"
+// @Param fileId path string true "Идентификатор файла"
+// @Success 200 {object} models.MessageResponse "File deleted"
+// @Failure 404 {object} models.ErrorResponse "File not found"
+// @Router /books/{id}/files/{fileId} [delete]
+func DeleteBookFile(c *gin.Context) {
+	bookID, _ := strconv.ParseUint(c.Param("id"), 10, 64)
+	fileID, _ := strconv.ParseUint(c.Param("fileId"), 10, 64)
+
+	err := services.DeleteBookFile(c.Request.Context(), uint(bookID), uint(fileID), currentUsername(c))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		utils.HandleError(c, http.StatusNotFound, "File not found")
+		return
+	}
+	if err != nil {
+		utils.HandleError(c, http.StatusInternalServerError, "Failed to delete file")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "File deleted"})
+}
+
+// GetBookDownloadLink обрабатывает запрос на получение ссылки для скачивания файла книги.
+// @Summary Ссылка на скачивание файла книги
+// @Description Проверяет, что пользователь купил книгу или получил к ней доступ, и возвращает подписанную ссылку, действующую DOWNLOAD_URL_TTL.
+// @Tags files
+// @Accept json
+// @Produce json
+// @Param id path string true "Идентификатор книги"
+// @Param fileId path string true "Идентификатор файла"
+// @Success 200 {object} models.DownloadLinkResponse
+// @Failure 403 {object} models.ErrorResponse "Book is not purchased"
+// @Failure 404 {object} models.ErrorResponse "File not found"
+// @Router /books/{id}/files/{fileId}/link [get]
+func GetBookDownloadLink(c *gin.Context) {
+	var file services.BookFile
+	if err := services.Db.Where("book_id = ?", c.Param("id")).First(&file, c.Param("fileId")).Error; err != nil {
+		utils.HandleError(c, http.StatusNotFound, "File not found")
+		return
+	}
+
+	username, role := currentUsername(c), c.GetString("role")
+	if !canDownload(c, username, role, file.BookID) {
+		return
+	}
+
+	expires := time.Now().Add(services.DownloadURLTTL)
+	query := url.Values{
+		"user":      {username},
+		"expires":   {strconv.FormatInt(expires.Unix(), 10)},
+		"signature": {services.SignDownload(file.ID, username, role, expires)},
+	}
+	if role != "" {
+		query.Set("role", role)
+	}
+	c.JSON(http.StatusOK, models.DownloadLinkResponse{
+		URL:       fmt.Sprintf("/downloads/%d?%s", file.ID, query.Encode()),
+		ExpiresAt: expires,
+	})
+}
+
+// DownloadBookFile отдает файл книги по подписанной ссылке.
+// @Summary Скачивание файла книги
+// @Description Отдает файл по ссылке, полученной из /books/{id}/files/{fileId}/link. Право на книгу проверяется повторно, скачивание записывается в журнал.
+// @Tags files
+// @Produce application/epub+zip
+// @Produce application/pdf
+// @Param fileId path string true "Идентификатор файла"
+// @Param user query string true "Имя пользователя"
+// @Param role query string false "Роль пользователя на момент выдачи ссылки"
+// @Param expires query int true "Окончание действия ссылки (Unix time)"
+// @Param signature query string true "Подпись ссылки"
+// @Success 200 {file} file "Файл книги"
+// @Failure 403 {object} models.ErrorResponse "Invalid or expired link"
+// @Failure 404 {object} models.ErrorResponse "File not found"
+// @Failure 429 {object} models.ErrorResponse "download limit reached"
+// @Router /downloads/{fileId} [get]
+func DownloadBookFile(c *gin.Context) {
+	fileID, _ := strconv.ParseUint(c.Param("fileId"), 10, 64)
+	// Маршрут открыт без авторизации: имя и роль берутся из подписанной ссылки
+	username, role := c.Query("user"), c.Query("role")
+	if !services.VerifyDownload(uint(fileID), username, role, c.Query("expires"), c.Query("signature")) {
+		utils.HandleError(c, http.StatusForbidden, "Invalid or expired link")
+		return
+	}
+
+	var file services.BookFile
+	if err := services.Db.First(&file, fileID).Error; err != nil {
+		utils.HandleError(c, http.StatusNotFound, "File not found")
+		return
+	}
+	var book services.Book
+	if err := services.Db.First(&book, file.BookID).Error; err != nil {
+		utils.HandleError(c, http.StatusNotFound, "File not found")
+		return
+	}
+	// Доступ мог быть отозван после выдачи ссылки
+	if !canDownload(c, username, role, file.BookID) {
+		return
+	}
+
+	reader, err := services.PrivateStorage.Get(c.Request.Context(), file.Key)
+	if errors.Is(err, storage.ErrNotFound) {
+		utils.HandleError(c, http.StatusNotFound, "File not found")
+		return
+	}
+	if err != nil {
+		utils.HandleError(c, http.StatusInternalServerError, "Failed to read file")
+		return
+	}
+	defer reader.Close()
+
+	err = services.RecordDownload(services.Db, username, file.BookID, file.ID)
+	if errors.Is(err, services.ErrDownloadLimit) {
+		utils.HandleError(c, http.StatusTooManyRequests, err.Error())
+		return
+	}
+	if err != nil {
+		utils.HandleError(c, http.StatusInternalServerError, "Failed to record download")
+		return
+	}
+
+	filename := fmt.Sprintf("book-%d.%s", book.ID, file.Format)
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"; filename*=UTF-8''%s.%s`,
+		filename, url.PathEscape(book.Title), file.Format))
+	c.Header("Cache-Control", "private, no-store")
+	c.DataFromReader(http.StatusOK, file.Size, file.ContentType, reader, nil)
+}
+
+// canDownload проверяет право пользователя с ролью role на файлы книги; администраторам
+// доступны все книги. Возвращает false, если ответ с ошибкой уже отправлен.
+func canDownload(c *gin.Context, username, role string, bookID uint) bool {
+	if role == "admin" {
+		return true
+	}
+	ok, err := services.HasEntitlement(services.Db, username, bookID)
+	if err != nil {
+		utils.HandleError(c, http.StatusInternalServerError, "Failed to check access")
+		return false
+	}
+	if !ok {
+		utils.HandleError(c, http.StatusForbidden, "Book is not purchased")
+		return false
+	}
+	return true
+}
+
+// GrantBookAccess обрабатывает выдачу пользователю доступа к электронной версии книги.
+// @Summary Выдача доступа к книге
+// @Description Выдает пользователю книгу на время (loan, требуется expires_at) или бессрочно (grant).
+// @Tags files
+// @Accept json
+// @Produce json
+// @Param id path string true "Идентификатор книги"
+// @Param request body models.GrantEntitlementRequest true "Пользователь и срок доступа"
+// @Success 201 {object} services.BookEntitlement
+// @Failure 400 {object} models.ErrorResponse "Invalid request"
+// @Failure 404 {object} models.ErrorResponse "Book not found"
+// @Router /books/{id}/entitlements [post]
+func GrantBookAccess(c *gin.Context) {
+	var book services.Book
+	if err := services.Db.First(&book, c.Param("id")).Error; err != nil {
+		utils.HandleError(c, http.StatusNotFound, "Book not found")
+		return
+	}
+
+	var req models.GrantEntitlementRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.HandleError(c, http.StatusBadRequest, "Invalid request")
+		return
+	}
+	if _, exists := Users[req.Username]; !exists {
+		utils.HandleError(c, http.StatusBadRequest, "User not found")
+		return
+	}
+	if req.Source == "" {
+		req.Source = services.EntitlementGrant
+	}
+	switch {
+	case req.Source != services.EntitlementLoan && req.Source != services.EntitlementGrant:
+		utils.HandleError(c, http.StatusBadRequest, "Invalid source: "+req.Source)
+		return
+	case req.Source == services.EntitlementLoan && req.ExpiresAt == nil:
+		utils.HandleError(c, http.StatusBadRequest, "expires_at is required for a loan")
+		return
+	case req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()):
+		utils.HandleError(c, http.StatusBadRequest, "expires_at must be in the future")
+		return
+	}
+
+	entitlement := services.BookEntitlement{
+		Username:  req.Username,
+		BookID:    book.ID,
+		Source:    req.Source,
+		ExpiresAt: req.ExpiresAt,
+		GrantedBy: currentUsername(c),
+	}
+	if err := services.Db.Create(&entitlement).Error; err != nil {
+		utils.HandleError(c, http.StatusInternalServerError, "Failed to grant access")
+		return
+	}
+	c.JSON(http.StatusCreated, entitlement)
+}
+
+// RevokeBookAccess обрабатывает отзыв выданного доступа к книге.
+// @Summary Отзыв доступа к книге
+// @Description Отзывает выданный администратором доступ пользователя к книге. Доступ по покупке не затрагивается.
+// @Tags files
+// @Accept json
+// @Produce json
+// @Param id path string true "Идентификатор книги"
+// @Param username path string true "Имя пользователя"
+// @Success 200 {object} models.MessageResponse "Access revoked"
+// @Failure 404 {object} models.ErrorResponse "Access not found"
+// @Router /books/{id}/entitlements/{username} [delete]
+func RevokeBookAccess(c *gin.Context) {
+	result := services.Db.
+		Where("book_id = ? AND username = ? AND source IN ?", c.Param("id"), c.Param("username"),
+			[]string{services.EntitlementLoan, services.EntitlementGrant}).
+		Delete(&services.BookEntitlement{})
+	if result.Error != nil {
+		utils.HandleError(c, http.StatusInternalServerError, "Failed to revoke access")
+		return
+	}
+	if result.RowsAffected == 0 {
+		utils.HandleError(c, http.StatusNotFound, "Access not found")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Access revoked"})
+}
+
+// GetBookDownloadStats обрабатывает запрос на получение статистики скачиваний книги.
+// @Summary Статистика скачиваний книги
+// @Description Возвращает число скачиваний книги каждым пользователем и время последнего скачивания.
+// @Tags files
+// @Accept json
+// @Produce json
+// @Param id path string true "Идентификатор книги"
+// @Success 200 {array} services.DownloadStat
+// @Failure 404 {object} models.ErrorResponse "Book not found"
+// @Router /books/{id}/downloads [get]
+func GetBookDownloadStats(c *gin.Context) {
+	var book services.Book
+	if err := services.Db.Unscoped().First(&book, c.Param("id")).Error; err != nil {
+		utils.HandleError(c, http.StatusNotFound, "Book not found")
+		return
+	}
+
+	stats := []services.DownloadStat{}
+	err := services.Db.Model(&services.BookDownload{}).
+		Select("username, COUNT(*) AS count, MAX(created_at) AS last_download_at").
+		Where("book_id = ?", book.ID).
+		Group("username").
+		Order("count desc, username asc").
+		Scan(&stats).Error
+	if err != nil {
+		utils.HandleError(c, http.StatusInternalServerError, "Failed to load download stats")
+		return
+	}
+	c.JSON(http.StatusOK, stats)
+}
@@ -1,19 +1,30 @@
 package controllers
 
 import (
+	"Projectmugen/internal/models"
+	"Projectmugen/internal/patch"
 	"Projectmugen/internal/services"
 	"Projectmugen/internal/utils"
+	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // GetBooks обрабатывает запрос на получение списка книг с поддержкой фильтрации, сортировки и пагинации.
 // @Summary Получение списка книг
 // @Description Возвращает список книг с возможностью фильтрации по заголовку, сортировки и пагинации.
+// @Description Название и автор ищутся с учетом опечаток; если найдено мало книг, поле did_you_mean содержит ближайшее известное название или имя автора.
 // @Tags books
 // @Accept json
 // @Produce json
@@ -22,29 +33,52 @@ import (
 // @Param sort query string false "Поле для сортировки" default(id)
 // @Param order query string false "Порядок сортировки (asc или desc)" default(asc)
 // @Param title query string false "Фильтр по заголовку книги"
-// @Success 200 {object} services.Book "total": int64, "page": int, "limit": int
+// @Param author query string false "Фильтр по автору"
+// @Param exact query bool false "Искать название и автора только по точному вхождению, без учета опечаток"
+// @Param startYear query int false "Год издания от"
+// @Param endYear query int false "Год издания до"
+// @Param publisher_id query int false "Фильтр по издателю"
+// @Param category_id query int false "Фильтр по рубрике"
+// @Param work_id query int false "Фильтр по произведению"
+// @Param series_id query int false "Фильтр по серии"
+// @Param language query string false "Фильтр по языку текста книги (en включает en-US и en-GB)"
+// @Param tags query []string false "Фильтр по тегам (через запятую или повтором параметра)" collectionFormat(multi)
+// @Param tag_mode query string false "all — книги со всеми тегами, any — с любым из них" Enums(all, any) default(all)
+// @Param collapse_editions query bool false "Показывать по одному изданию каждого произведения (с числом изданий в edition_count)"
+// @Param available query bool false "Только книги в наличии (true) или отсутствующие (false)"
+// @Param currency query string false "Валюта отображения цен (ISO 4217); приоритетнее Accept-Currency"
+// @Param Accept-Currency header string false "Предпочитаемые валюты отображения цен через запятую"
+// @Param lang query string false "Язык названия и описания (тег BCP 47); приоритетнее Accept-Language"
+// @Param Accept-Language header string false "Предпочитаемые языки; без подходящего перевода отдается оригинал"
+// @Success 200 {object} services.Book "total": int64, "page": int, "limit": int, "did_you_mean": string (если найдено мало книг)
+// @Failure 400 {object} models.ErrorResponse "Invalid filter"
 // @Router /books [get]
 func GetBooks(c *gin.Context) {
 	var books []services.Book
 	var total int64
+	var filter models.BookFilter
+
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		utils.HandleError(c, http.StatusBadRequest, "Invalid filter")
+		return
+	}
 
 	// Получаем параметры фильтров, сортировки и пагинации
 	page := c.DefaultQuery("page", "1")
 	limit := c.DefaultQuery("limit", "10")
 	sort := c.DefaultQuery("sort", "id")
 	order := c.DefaultQuery("order", "asc")
-	title := c.Query("title")
 
 	// Преобразуем строковые параметры в int
 	pageInt, _ := strconv.Atoi(page)
 	limitInt, _ := strconv.Atoi(limit)
 	offset := (pageInt - 1) * limitInt
 
-	query := services.Db.Model(&services.Book{})
-
 	// Применяем фильтры
-	if title != "" {
-		query = query.Where("title ILIKE ?", "%"+title+"%")
+	query := applyBookFilters(services.Db.Model(&services.Book{}), filter)
+	collapse := c.Query("collapse_editions") == "true"
+	if collapse {
+		query = collapseEditions(query, filter)
 	}
 
 	query.Count(&total)
@@ -56,15 +90,141 @@ func GetBooks(c *gin.Context) {
 	query = query.Order(sort + " " + order).Limit(limitInt).Offset(offset)
 
 	// Загружаем продукты и считаем общее количество
-	query.Find(&books)
+	query.Preload("Authors.Author").Preload("Cover").Preload("Tags").Find(&books)
 
-	// Возращаем результат
-	c.JSON(http.StatusOK, gin.H{
+	if collapse {
+		if err := fillEditionCounts(books); err != nil {
+			utils.HandleError(c, http.StatusInternalServerError, "Failed to load books")
+			return
+		}
+	}
+
+	// Пересчитываем цены в валюту клиента
+	if !localizeBookList(c, books) || !convertBookListPrices(c, books) {
+		return
+	}
+
+	response := gin.H{
 		"data":  books,
 		"total": total,
 		"page":  pageInt,
 		"limit": limitInt,
-	})
+	}
+
+	// Если поиск почти ничего не нашел, подсказываем похожее известное название или автора
+	if total <= services.SearchSparseResults && (filter.Title != "" || filter.Author != "") {
+		if suggestion, err := services.DidYouMean(filter.Title, filter.Author); err == nil && suggestion != "" {
+			response["did_you_mean"] = suggestion
+		}
+	}
+
+	// Возращаем результат
+	c.JSON(http.StatusOK, response)
+}
+
+// collapseEditions оставляет в выборке по одному изданию каждого произведения: из подходящих под
+// фильтры изданий выбирается имеющееся в наличии, затем самое новое. Книги без произведения не сворачиваются.
+func collapseEditions(query *gorm.DB, filter models.BookFilter) *gorm.DB {
+	representatives := applyBookFilters(services.Db.Model(&services.Book{}), filter).
+		Select("DISTINCT ON (work_id) id").
+		Where("work_id IS NOT NULL").
+		Order("work_id, stock > 0 desc, year desc, id desc")
+	return query.Where("work_id IS NULL OR id IN (?)", representatives)
+}
+
+// fillEditionCounts проставляет книгам число изданий их произведений.
+func fillEditionCounts(books []services.Book) error {
+	var workIDs []uint
+	for _, book := range books {
+		if book.WorkID != nil {
+			workIDs = append(workIDs, *book.WorkID)
+		}
+	}
+	counts, err := services.EditionCounts(workIDs)
+	if err != nil {
+		return err
+	}
+	for i := range books {
+		if books[i].WorkID != nil {
+			books[i].EditionCount = counts[*books[i].WorkID]
+		}
+	}
+	return nil
+}
+
+// applyBookFilters добавляет к запросу фильтры каталога.
+// Используется списком книг и всеми эндпоинтами, которые должны принимать те же фильтры.
+func applyBookFilters(query *gorm.DB, filter models.BookFilter) *gorm.DB {
+	// С pg_trgm название и автор находятся и с опечатками: по сходству слов не ниже порога
+	// pg_trgm.word_similarity_threshold (см. services.SearchSimilarityThreshold)
+	fuzzy := services.TrigramAvailable && !filter.Exact
+	if filter.Title != "" {
+		pattern := "%" + filter.Title + "%"
+		if fuzzy {
+			query = query.Where("title ILIKE ? OR ? <% title", pattern, filter.Title)
+		} else {
+			query = query.Where("title ILIKE ?", pattern)
+		}
+	}
+	if filter.Author != "" {
+		// Ищем и по строке на обложке, и по связанным авторам с их вариантами имени
+		pattern := "%" + filter.Author + "%"
+		if fuzzy {
+			query = query.Where(`author ILIKE ? OR ? <% author OR EXISTS (
+				SELECT 1 FROM book_authors JOIN authors ON authors.id = book_authors.author_id
+				WHERE book_authors.book_id = books.id AND (authors.name ILIKE ? OR authors.alternative_names ILIKE ?
+					OR ? <% authors.name))`,
+				pattern, filter.Author, pattern, pattern, filter.Author)
+		} else {
+			query = query.Where(`author ILIKE ? OR EXISTS (
+				SELECT 1 FROM book_authors JOIN authors ON authors.id = book_authors.author_id
+				WHERE book_authors.book_id = books.id AND (authors.name ILIKE ? OR authors.alternative_names ILIKE ?))`,
+				pattern, pattern, pattern)
+		}
+	}
+	if filter.StartYear != nil {
+		query = query.Where("year >= ?", *filter.StartYear)
+	}
+	if filter.EndYear != nil {
+		query = query.Where("year <= ?", *filter.EndYear)
+	}
+	if filter.PublisherID != nil {
+		query = query.Where("publisher_id = ?", *filter.PublisherID)
+	}
+	if filter.CategoryID != nil {
+		query = query.Where("category_id = ?", *filter.CategoryID)
+	}
+	if filter.WorkID != nil {
+		query = query.Where("work_id = ?", *filter.WorkID)
+	}
+	if filter.SeriesID != nil {
+		query = query.Where("series_id = ?", *filter.SeriesID)
+	}
+	if tags := services.NormalizeTags(filter.Tags); len(tags) > 0 {
+		if filter.TagMode == services.TagModeAny {
+			query = query.Where(`EXISTS (
+				SELECT 1 FROM book_tags JOIN tags ON tags.id = book_tags.tag_id
+				WHERE book_tags.book_id = books.id AND tags.name IN ?)`, tags)
+		} else {
+			query = query.Where(`(
+				SELECT COUNT(*) FROM book_tags JOIN tags ON tags.id = book_tags.tag_id
+				WHERE book_tags.book_id = books.id AND tags.name IN ?) = ?`, tags, len(tags))
+		}
+	}
+	if filter.Language != "" {
+		// Фильтр по языку без региона включает все его региональные варианты
+		if language, err := services.NormalizeLanguage(filter.Language); err == nil {
+			query = query.Where("language = ? OR language LIKE ?", language, services.EscapeLike(language)+"-%")
+		}
+	}
+	if filter.Available != nil {
+		if *filter.Available {
+			query = query.Where("stock > 0")
+		} else {
+			query = query.Where("stock <= 0")
+		}
+	}
+	return query
 }
 
 // GetBookByID обрабатывает запрос на получение книги по ее идентификатору.
@@ -74,18 +234,121 @@ func GetBooks(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param id path string true "Идентификатор книги"
+// @Param If-None-Match header string false "ETag ранее полученной версии книги"
+// @Param currency query string false "Валюта отображения цен (ISO 4217); приоритетнее Accept-Currency"
+// @Param Accept-Currency header string false "Предпочитаемые валюты отображения цен через запятую"
+// @Param lang query string false "Язык названия и описания (тег BCP 47); приоритетнее Accept-Language"
+// @Param Accept-Language header string false "Предпочитаемые языки; без подходящего перевода отдается оригинал"
 // @Success 200 {object} services.Book
+// @Success 304 "Книга не изменилась"
+// @Failure 400 {object} models.ErrorResponse "unsupported currency"
 // @Failure 404 {object} models.ErrorResponse "Book not found"
 // @Router /books/{id} [get]
 func GetBookByID(c *gin.Context) {
-	id := c.Param("id")
+	respondBook(c, services.Db.Where("id = ?", c.Param("id")))
+}
+
+// GetBookByISBN обрабатывает запрос на получение книги по ISBN.
+// @Summary Получение книги по ISBN
+// @Description Возвращает книгу по ISBN-10 или ISBN-13; номер нормализуется перед поиском.
+// @Tags books
+// @Accept json
+// @Produce json
+// @Param isbn path string true "ISBN-10 или ISBN-13"
+// @Param If-None-Match header string false "ETag ранее полученной версии книги"
+// @Param currency query string false "Валюта отображения цен (ISO 4217); приоритетнее Accept-Currency"
+// @Param Accept-Currency header string false "Предпочитаемые валюты отображения цен через запятую"
+// @Param lang query string false "Язык названия и описания (тег BCP 47); приоритетнее Accept-Language"
+// @Param Accept-Language header string false "Предпочитаемые языки; без подходящего перевода отдается оригинал"
+// @Success 200 {object} services.Book
+// @Success 304 "Книга не изменилась"
+// @Failure 400 {object} models.ErrorResponse "invalid ISBN"
+// @Failure 404 {object} models.ErrorResponse "Book not found"
+// @Router /books/isbn/{isbn} [get]
+func GetBookByISBN(c *gin.Context) {
+	isbn, err := services.NormalizeISBN(c.Param("isbn"))
+	if err != nil {
+		utils.HandleError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	respondBook(c, services.Db.Where("isbn13 = ?", isbn))
+}
+
+// respondBook загружает книгу, найденную запросом query, со всеми связями и отвечает ею
+// с ETag. Если представление не изменилось с того, что указано в If-None-Match, отвечает 304.
+func respondBook(c *gin.Context, query *gorm.DB) {
 	var book services.Book
-	if err := services.Db.First(&book, id).Error; err != nil {
+	err := query.Preload("Publisher").Preload("Category").Preload("Authors.Author").
+		Preload("Cover").Preload("Files").Preload("Tags").
+		First(&book).Error
+	if err != nil {
 		utils.HandleError(c, http.StatusNotFound, "Book not found")
 		return
 	}
+
+	// Перевод и цены подставляются до ETag: разные языки и валюты — разные представления,
+	// а курс может измениться без изменения версии книги
+	if !localizeBooks(c, &book) || !convertBookPrices(c, &book) {
+		return
+	}
+	etag := services.BookETag(&book, book.Locale, bookPriceVariant(&book))
+	c.Header("ETag", etag)
+	if match := c.GetHeader("If-None-Match"); match != "" && services.ETagMatches(match, etag) {
+		c.Status(http.StatusNotModified)
+		return
+	}
 	c.JSON(http.StatusOK, book)
+}
 
+// bookPriceVariant возвращает часть ETag, зависящую от валюты ответа: код валюты и цены в ней.
+func bookPriceVariant(book *services.Book) string {
+	if book.Price == nil {
+		return book.Currency
+	}
+	variant := fmt.Sprintf("%s%d", book.Currency, *book.Price)
+	if book.RegularPrice != nil {
+		variant += fmt.Sprintf(".%d", *book.RegularPrice)
+	}
+	return variant
+}
+
+// checkBookIfMatch проверяет заголовок If-Match для изменения книги. Возвращает версию,
+// с которой должно совпасть обновление (0, если заголовок не передан и он необязателен),
+// и false, если ответ с ошибкой уже отправлен.
+func checkBookIfMatch(c *gin.Context, book *services.Book) (uint, bool) {
+	header := c.GetHeader("If-Match")
+	if header == "" {
+		if services.RequireIfMatch {
+			utils.HandleError(c, http.StatusPreconditionRequired, "If-Match header is required")
+			return 0, false
+		}
+		return 0, true
+	}
+	if !services.BookVersionMatches(header, book) {
+		utils.HandleError(c, http.StatusPreconditionFailed, "Book has been modified")
+		return 0, false
+	}
+	return book.Version, true
+}
+
+// handleBookWriteError отвечает на ошибку записи книги: конфликт уникального ISBN
+// возвращается как 409, ссылка на несуществующего издателя, рубрику, произведение или серию — как 400, конфликт версий — как 412,
+// остальное — как 500.
+func handleBookWriteError(c *gin.Context, err error) {
+	if errors.Is(err, services.ErrVersionConflict) {
+		utils.HandleError(c, http.StatusPreconditionFailed, "Book has been modified")
+		return
+	}
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		utils.HandleError(c, http.StatusConflict, "Book with this ISBN already exists")
+		return
+	}
+	if errors.Is(err, gorm.ErrForeignKeyViolated) {
+		utils.HandleError(c, http.StatusBadRequest, "Publisher, category, work or series not found")
+		return
+	}
+	utils.HandleError(c, http.StatusInternalServerError, "Failed to save book")
 }
 
 // CreateBook обрабатывает запрос на создание новой книги.
@@ -97,6 +360,7 @@ func GetBookByID(c *gin.Context) {
 // @Param book body services.Book true "Данные книги"
 // @Success 201 {object} services.Book
 // @Failure 400 {object} models.ErrorResponse "Invalid request"
+// @Failure 409 {object} models.ErrorResponse "Book with this ISBN already exists"
 // @Router /books [post]
 func CreateBook(c *gin.Context) {
 	var newBook services.Book
@@ -106,62 +370,376 @@ func CreateBook(c *gin.Context) {
 		return
 	}
 
-	services.Db.Create(&newBook)
+	if err := services.NormalizeBookISBN(&newBook); err != nil {
+		utils.HandleError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	if newBook.SeriesVolume != nil && (newBook.SeriesID == nil || *newBook.SeriesVolume <= 0) {
+		utils.HandleError(c, http.StatusBadRequest, "series_volume must be positive and requires series_id")
+		return
+	}
+	language, err := services.NormalizeLanguage(newBook.Language)
+	if err != nil {
+		utils.HandleError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	newBook.Language = language
+	newBook.Translations = nil
+	newBook.Version = 1
+	newBook.CreatedAt = time.Time{}
+	newBook.DeletedAt = gorm.DeletedAt{}
+	// Остаток меняется только через движения склада, цена — через историю цен
+	newBook.Stock = 0
+	newBook.Price, newBook.RegularPrice, newBook.Currency, newBook.OnSale = nil, nil, "", false
+
+	err = services.Db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit(clause.Associations).Create(&newBook).Error; err != nil {
+			return err
+		}
+		if err := services.LinkAuthorsFromString(tx, &newBook); err != nil {
+			return err
+		}
+		return services.RecordBookRevision(tx, services.RevisionCreate, currentUsername(c), nil, &newBook)
+	})
+	if err != nil {
+		handleBookWriteError(c, err)
+		return
+	}
+	c.Header("ETag", services.BookETag(&newBook))
 	c.JSON(http.StatusCreated, newBook)
 
 }
 
-// UpdateBook обрабатывает запрос на обновление существующей книги по ее идентификатору.
-// @Summary Обновление книги по ID
-// @Description Обновляет данные книги с указанным идентификатором на основе переданных данных.
+// UpdateBook обрабатывает запрос на полную замену книги по ее идентификатору.
+// @Summary Замена книги по ID
+// @Description Полностью заменяет редактируемые поля книги: не переданные поля получают нулевые значения. Возвращает сохраненную книгу.
 // @Tags books
 // @Accept json
 // @Produce json
 // @Param id path string true "Идентификатор книги"
-// @Param book body services.Book true "Обновленные данные книги"
+// @Param If-Match header string false "ETag, полученный при чтении книги (обязателен, если включен BOOK_REQUIRE_IF_MATCH)"
+// @Param book body models.BookInput true "Новые данные книги"
 // @Success 200 {object} services.Book
 // @Failure 400 {object} models.ErrorResponse "Invalid request"
 // @Failure 404 {object} models.ErrorResponse "Book not found"
+// @Failure 409 {object} models.ErrorResponse "Book with this ISBN already exists"
+// @Failure 412 {object} models.ErrorResponse "Book has been modified"
+// @Failure 428 {object} models.ErrorResponse "If-Match header is required"
 // @Router /books/{id} [put]
 func UpdateBook(c *gin.Context) {
-	id := c.Param("id")
-	var updatedBook services.Book
+	var before services.Book
+	if err := services.Db.First(&before, c.Param("id")).Error; err != nil {
+		utils.HandleError(c, http.StatusNotFound, "Book not found")
+		return
+	}
 
-	if err := c.BindJSON(&updatedBook); err != nil {
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
 		utils.HandleError(c, http.StatusBadRequest, "Invalid request")
 		return
 	}
+	input, err := decodeBookInput(body)
+	if err != nil {
+		utils.HandleError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	replaceBook(c, &before, input)
+}
 
-	if err := services.Db.Model(&services.Book{}).Where("id = ?", id).Updates(updatedBook).Error; err != nil {
+// PatchBook обрабатывает запрос на частичное изменение книги.
+// @Summary Частичное изменение книги по ID
+// @Description Применяет к редактируемым полям книги JSON Merge Patch (RFC 7396, Content-Type application/merge-patch+json или application/json) или JSON Patch (RFC 6902, Content-Type application/json-patch+json). Возвращает сохраненную книгу.
+// @Tags books
+// @Accept application/merge-patch+json
+// @Accept application/json-patch+json
+// @Produce json
+// @Param id path string true "Идентификатор книги"
+// @Param If-Match header string false "ETag, полученный при чтении книги (обязателен, если включен BOOK_REQUIRE_IF_MATCH)"
+// @Param patch body object true "Патч"
+// @Success 200 {object} services.Book
+// @Failure 400 {object} models.ErrorResponse "Invalid patch"
+// @Failure 404 {object} models.ErrorResponse "Book not found"
+// @Failure 409 {object} models.ErrorResponse "Book with this ISBN already exists"
+// @Failure 412 {object} models.ErrorResponse "Book has been modified"
+// @Failure 415 {object} models.ErrorResponse "Unsupported patch format"
+// @Failure 422 {object} models.ErrorResponse "Patch test operation failed"
+// @Failure 428 {object} models.ErrorResponse "If-Match header is required"
+// @Router /books/{id} [patch]
+func PatchBook(c *gin.Context) {
+	var before services.Book
+	if err := services.Db.First(&before, c.Param("id")).Error; err != nil {
 		utils.HandleError(c, http.StatusNotFound, "Book not found")
 		return
 	}
 
-	c.JSON(http.StatusOK, updatedBook)
+	patchBody, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		utils.HandleError(c, http.StatusBadRequest, "Invalid patch")
+		return
+	}
+	current, err := json.Marshal(bookToInput(&before))
+	if err != nil {
+		utils.HandleError(c, http.StatusInternalServerError, "Failed to patch book")
+		return
+	}
+
+	var patched []byte
+	switch c.ContentType() {
+	case "application/merge-patch+json", "application/json":
+		patched, err = patch.MergePatch(current, patchBody)
+	case "application/json-patch+json":
+		patched, err = patch.ApplyJSONPatch(current, patchBody)
+	default:
+		utils.HandleError(c, http.StatusUnsupportedMediaType, "Unsupported patch format")
+		return
+	}
+	if errors.Is(err, patch.ErrTestFailed) {
+		utils.HandleError(c, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+	if err != nil {
+		utils.HandleError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	input, err := decodeBookInput(patched)
+	if err != nil {
+		utils.HandleError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	replaceBook(c, &before, input)
+}
+
+// decodeBookInput разбирает и проверяет редактируемые поля книги. Неизвестные поля
+// считаются ошибкой, чтобы опечатки и попытки изменить служебные поля не терялись молча.
+func decodeBookInput(data []byte) (models.BookInput, error) {
+	var input models.BookInput
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&input); err != nil {
+		return input, fmt.Errorf("invalid book: %w", err)
+	}
+
+	input.Title = strings.TrimSpace(input.Title)
+	if input.Title == "" {
+		return input, errors.New("title is required")
+	}
+	if input.Year < 0 || input.Year > time.Now().Year()+1 {
+		return input, errors.New("year is out of range")
+	}
+	if input.SeriesVolume != nil && (input.SeriesID == nil || *input.SeriesVolume <= 0) {
+		return input, errors.New("series_volume must be positive and requires series_id")
+	}
+	language, err := services.NormalizeLanguage(input.Language)
+	if err != nil {
+		return input, err
+	}
+	input.Language = language
+	return input, nil
+}
+
+func bookToInput(book *services.Book) models.BookInput {
+	return models.BookInput{
+		Title:        book.Title,
+		Author:       book.Author,
+		Year:         book.Year,
+		Description:  book.Description,
+		Language:     book.Language,
+		PublisherID:  book.PublisherID,
+		CategoryID:   book.CategoryID,
+		WorkID:       book.WorkID,
+		SeriesID:     book.SeriesID,
+		SeriesVolume: book.SeriesVolume,
+		ISBN13:       book.ISBN13,
+		ISBN10:       book.ISBN10,
+	}
+}
+
+func sameString(a, b *string) bool {
+	return a != nil && b != nil && *a == *b
+}
+
+// replaceBook записывает в книгу все редактируемые поля из input, включая нулевые значения,
+// сохраняет ревизию и отвечает сохраненной книгой.
+func replaceBook(c *gin.Context, before *services.Book, input models.BookInput) {
+	replacement := *before
+	replacement.Title = input.Title
+	replacement.Author = input.Author
+	replacement.Year = input.Year
+	replacement.Description, replacement.Language = input.Description, input.Language
+	replacement.PublisherID = input.PublisherID
+	replacement.CategoryID = input.CategoryID
+	replacement.WorkID = input.WorkID
+	replacement.SeriesID, replacement.SeriesVolume = input.SeriesID, input.SeriesVolume
+	replacement.ISBN13, replacement.ISBN10 = input.ISBN13, input.ISBN10
+	// Номера ISBN взаимно вычисляемы: если клиент изменил только один из них, второй
+	// пересчитывается заново, а не сверяется со старым значением
+	if input.ISBN13 != nil && input.ISBN10 != nil {
+		if sameString(input.ISBN10, before.ISBN10) {
+			replacement.ISBN10 = nil
+		} else if sameString(input.ISBN13, before.ISBN13) {
+			replacement.ISBN13 = nil
+		}
+	}
+	if err := services.NormalizeBookISBN(&replacement); err != nil {
+		utils.HandleError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	expectedVersion, ok := checkBookIfMatch(c, before)
+	if !ok {
+		return
+	}
+
+	var after services.Book
+	err := services.Db.Transaction(func(tx *gorm.DB) error {
+		if err := services.BumpBookVersion(tx, before.ID, expectedVersion); err != nil {
+			return err
+		}
+		if err := tx.Model(&services.Book{}).Where("id = ?", before.ID).Updates(services.BookSnapshot(&replacement)).Error; err != nil {
+			return err
+		}
+		if err := tx.Preload("Publisher").Preload("Category").Preload("Authors.Author").Preload("Cover").First(&after, before.ID).Error; err != nil {
+			return err
+		}
+		return services.RecordBookRevision(tx, services.RevisionUpdate, currentUsername(c), before, &after)
+	})
+	if err != nil {
+		handleBookWriteError(c, err)
+		return
+	}
+
+	c.Header("ETag", services.BookETag(&after))
+	c.JSON(http.StatusOK, after)
 }
 
 // DeleteBook обрабатывает запрос на удаление книги по ее идентификатору.
 // @Summary Удаление книги по ID
-// @Description Удаляет книгу с указанным идентификатором.
+// @Description Перемещает книгу с указанным идентификатором в корзину. Книга окончательно удаляется по истечении срока хранения.
 // @Tags books
 // @Accept json
 // @Produce json
 // @Param id path string true "Идентификатор книги"
-// @Success 200 {object} models.ErrorResponse "Book deleted"
+// @Param If-Match header string false "ETag, полученный при чтении книги (обязателен, если включен BOOK_REQUIRE_IF_MATCH)"
+// @Success 200 {object} models.MessageResponse "Book deleted"
 // @Failure 404 {object} models.ErrorResponse "Book not found"
+// @Failure 412 {object} models.ErrorResponse "Book has been modified"
+// @Failure 428 {object} models.ErrorResponse "If-Match header is required"
+// @Failure 500 {object} models.ErrorResponse "Failed to delete book"
 // @Router /books/{id} [delete]
 func DeleteBook(c *gin.Context) {
 	id := c.Param("id")
 
-	if err := services.Db.Delete(&services.Book{}, id).Error; err != nil {
+	var book services.Book
+	if err := services.Db.First(&book, id).Error; err != nil {
 		utils.HandleError(c, http.StatusNotFound, "Book not found")
 		return
 	}
 
+	expectedVersion, ok := checkBookIfMatch(c, &book)
+	if !ok {
+		return
+	}
+
+	err := services.Db.Transaction(func(tx *gorm.DB) error {
+		if err := services.BumpBookVersion(tx, book.ID, expectedVersion); err != nil {
+			return err
+		}
+		result := tx.Delete(&book)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return services.RecordBookRevision(tx, services.RevisionDelete, currentUsername(c), &book, nil)
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		utils.HandleError(c, http.StatusNotFound, "Book not found")
+		return
+	}
+	if errors.Is(err, services.ErrVersionConflict) {
+		utils.HandleError(c, http.StatusPreconditionFailed, "Book has been modified")
+		return
+	}
+	if err != nil {
+		utils.HandleError(c, http.StatusInternalServerError, "Failed to delete book")
+		return
+	}
+
 	c.JSON(http.StatusOK, gin.H{"message": "Book deleted"})
 
 }
 
+// GetDeletedBooks обрабатывает запрос на получение содержимого корзины.
+// @Summary Корзина удаленных книг
+// @Description Возвращает мягко удаленные книги, начиная с последних удаленных.
+// @Tags books
+// @Accept json
+// @Produce json
+// @Param page query int false "Номер страницы" default(1)
+// @Param limit query int false "Количество книг на странице" default(10)
+// @Success 200 {object} services.Book "total": int64, "page": int, "limit": int
+// @Router /books/trash [get]
+func GetDeletedBooks(c *gin.Context) {
+	var books []services.Book
+	var total int64
+
+	pageInt, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limitInt, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	offset := (pageInt - 1) * limitInt
+
+	query := services.Db.Unscoped().Model(&services.Book{}).Where("deleted_at IS NOT NULL")
+	query.Count(&total)
+	query.Order("deleted_at desc").Limit(limitInt).Offset(offset).Find(&books)
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":  books,
+		"total": total,
+		"page":  pageInt,
+		"limit": limitInt,
+	})
+}
+
+// RestoreBook обрабатывает запрос на восстановление книги из корзины.
+// @Summary Восстановление книги
+// @Description Возвращает мягко удаленную книгу в каталог.
+// @Tags books
+// @Accept json
+// @Produce json
+// @Param id path string true "Идентификатор книги"
+// @Success 200 {object} services.Book
+// @Failure 404 {object} models.ErrorResponse "Book not found in trash"
+// @Failure 409 {object} models.ErrorResponse "Book with this ISBN already exists"
+// @Router /books/{id}/restore [post]
+func RestoreBook(c *gin.Context) {
+	var book services.Book
+	err := services.Db.Unscoped().Where("deleted_at IS NOT NULL").First(&book, c.Param("id")).Error
+	if err != nil {
+		utils.HandleError(c, http.StatusNotFound, "Book not found in trash")
+		return
+	}
+
+	err = services.Db.Transaction(func(tx *gorm.DB) error {
+		if err := services.BumpBookVersion(tx.Unscoped(), book.ID, 0); err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Model(&book).Update("deleted_at", nil).Error; err != nil {
+			return err
+		}
+		return services.RecordBookRevision(tx, services.RevisionRestore, currentUsername(c), nil, &book)
+	})
+	if err != nil {
+		handleBookWriteError(c, err)
+		return
+	}
+
+	services.Db.Preload("Publisher").Preload("Authors.Author").Preload("Cover").First(&book, book.ID)
+	c.JSON(http.StatusOK, book)
+}
+
 // GetBooksByYearRange обрабатывает запрос на получение книг в указанном диапазоне лет.
 // @Summary Получение книг по диапазону лет
 // @Description Возвращает список книг, выпущенных в заданном диапазоне лет.
@@ -170,7 +748,12 @@ func DeleteBook(c *gin.Context) {
 // @Produce json
 // @Param startYear query string true "Начальный год"
 // @Param endYear query string true "Конечный год"
+// @Param currency query string false "Валюта отображения цен (ISO 4217); приоритетнее Accept-Currency"
+// @Param Accept-Currency header string false "Предпочитаемые валюты отображения цен через запятую"
+// @Param lang query string false "Язык названия и описания (тег BCP 47); приоритетнее Accept-Language"
+// @Param Accept-Language header string false "Предпочитаемые языки; без подходящего перевода отдается оригинал"
 // @Success 200 {array} services.Book
+// @Failure 400 {object} models.ErrorResponse "unsupported currency"
 // @Failure 500 {object} models.ErrorResponse "Error fetching books"
 // @Router /books [get]
 func GetBooksByYearRange(c *gin.Context) {
@@ -182,48 +765,121 @@ func GetBooksByYearRange(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching books"})
 		return
 	}
+	if !localizeBookList(c, books) || !convertBookListPrices(c, books) {
+		return
+	}
 	c.JSON(http.StatusOK, books)
 }
 
-// UpdateBooksPublisher обрабатывает запрос на обновление издателя для всех книг.
-// @Summary Обновление издателя для всех книг
-// @Description Обновляет издателя для всех книг в базе данных.
+// UpdateBooksPublisher обрабатывает запрос на переназначение издателя для книг, подходящих под фильтр.
+// @Summary Массовое переназначение издателя
+// @Description Назначает указанного издателя всем книгам, подходящим под фильтр, и возвращает количество измененных записей. Пустой фильтр не допускается; название и автор сравниваются без учета опечаток.
 // @Tags books
 // @Accept json
 // @Produce json
-// @Param publisher query string true "Имя издателя"
-// @Success 200 {object} models.MessageResponse "Publisher updated successfully"
+// @Param request body models.ReassignPublisherRequest true "Издатель и фильтр книг"
+// @Success 200 {object} models.ReassignPublisherResponse
+// @Failure 400 {object} models.ErrorResponse "Invalid request"
+// @Failure 404 {object} models.ErrorResponse "Publisher not found"
 // @Failure 500 {object} models.ErrorResponse "Error updating publisher"
-// @Router /books/publisher [put]
+// @Router /books/publisher [post]
 func UpdateBooksPublisher(c *gin.Context) {
-	publisher := c.Query("publisher")
-	tx := services.Db.Begin()
+	var req models.ReassignPublisherRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.HandleError(c, http.StatusBadRequest, "Invalid request")
+		return
+	}
+	if req.Filter.IsEmpty() {
+		utils.HandleError(c, http.StatusBadRequest, "Filter must not be empty")
+		return
+	}
+	// Массовое изменение не должно задевать книги, лишь похожие на искомые
+	req.Filter.Exact = true
+
+	var updated int64
+	err := services.Db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&services.Publisher{}, req.PublisherID).Error; err != nil {
+			return err
+		}
 
-	if err := tx.Model(&services.Book{}).Update("publisher", publisher).Error; err != nil {
-		tx.Rollback()
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error updating publisher"})
+		var books []services.Book
+		err := applyBookFilters(tx.Model(&services.Book{}), req.Filter).
+			Where("publisher_id IS DISTINCT FROM ?", req.PublisherID).
+			Find(&books).Error
+		if err != nil || len(books) == 0 {
+			return err
+		}
+
+		ids := make([]uint, len(books))
+		for i, book := range books {
+			ids[i] = book.ID
+		}
+		result := tx.Model(&services.Book{}).Where("id IN ?", ids).Updates(map[string]interface{}{
+			"publisher_id": req.PublisherID,
+			"version":      gorm.Expr("version + 1"),
+		})
+		if result.Error != nil {
+			return result.Error
+		}
+		updated = result.RowsAffected
+
+		for i := range books {
+			after := books[i]
+			after.PublisherID = &req.PublisherID
+			if err := services.RecordBookRevision(tx, services.RevisionUpdate, currentUsername(c), &books[i], &after); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		utils.HandleError(c, http.StatusNotFound, "Publisher not found")
+		return
+	}
+	if err != nil {
+		utils.HandleError(c, http.StatusInternalServerError, "Error updating publisher")
 		return
 	}
 
-	tx.Commit()
-	c.JSON(http.StatusOK, gin.H{"message": "Publisher updated successfully"})
+	c.JSON(http.StatusOK, models.ReassignPublisherResponse{Updated: updated})
 }
 
 // CountBooksByAuthor обрабатывает запрос на подсчет количества книг по каждому автору.
 // @Summary Подсчет книг по авторам
-// @Description Возвращает количество книг для каждого автора в базе данных.
+// @Description Возвращает количество книг для каждого автора в базе данных. Варианты написания имени одного автора считаются вместе. Принимает те же фильтры, что и список книг.
 // @Tags books
 // @Accept json
 // @Produce json
-// @Success 200 {array} models.Order "Количество книг по каждому автору"
-// @Router /books/authors/count [get]
+// @Param title query string false "Фильтр по заголовку книги"
+// @Param author query string false "Фильтр по автору"
+// @Param startYear query int false "Год издания от"
+// @Param endYear query int false "Год издания до"
+// @Param publisher_id query int false "Фильтр по издателю"
+// @Param category_id query int false "Фильтр по рубрике"
+// @Param work_id query int false "Фильтр по произведению"
+// @Param series_id query int false "Фильтр по серии"
+// @Param language query string false "Фильтр по языку текста книги"
+// @Param tags query []string false "Фильтр по тегам (через запятую или повтором параметра)" collectionFormat(multi)
+// @Param tag_mode query string false "all — книги со всеми тегами, any — с любым из них" Enums(all, any) default(all)
+// @Param available query bool false "Только книги в наличии (true) или отсутствующие (false)"
+// @Success 200 {array} models.AuthorBookCount "Количество книг по каждому автору"
+// @Failure 400 {object} models.ErrorResponse "Invalid filter"
+// @Router /books/count-by-author [get]
 func CountBooksByAuthor(c *gin.Context) {
-	var result []struct {
-		Author string
-		Count  int
+	ids, ok := filteredBookIDs(c)
+	if !ok {
+		return
 	}
-
-	services.Db.Model(&services.Book{}).Select("author, COUNT(*) as count").Group("author").Scan(&result)
+	result := []models.AuthorBookCount{}
+
+	services.Db.Model(&services.BookAuthor{}).
+		Select("authors.id AS author_id, authors.name AS name, COUNT(DISTINCT book_authors.book_id) AS count").
+		Joins("JOIN authors ON authors.id = book_authors.author_id").
+		Where("book_authors.book_id IN (?)", ids).
+		Where("book_authors.role = ?", services.RoleAuthor).
+		Group("authors.id, authors.name").
+		Order("count desc").
+		Scan(&result)
 	c.JSON(http.StatusOK, result)
 }
 
@@ -0,0 +1,293 @@
+package controllers
+
+import (
+	"Projectmugen/internal/models"
+	"Projectmugen/internal/services"
+	"Projectmugen/internal/utils"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// CartTokenHeader — заголовок с токеном анонимной корзины.
+const CartTokenHeader = "X-Cart-Token"
+
+// GetCart обрабатывает запрос на получение корзины.
+// @Summary Корзина
+// @Description Возвращает корзину текущего пользователя или анонимную корзину по токену из X-Cart-Token. При каждом чтении цены и наличие проверяются заново: строки удаленных, снятых с продажи книг и книг, которых не хватает на складе, помечаются issue и не входят в сумму; price_changed означает, что цена изменилась после добавления. Цены считаются в валюте заказа (currency или Accept-Currency).
+// @Tags cart
+// @Accept json
+// @Produce json
+// @Param X-Cart-Token header string false "Токен анонимной корзины"
+// @Param currency query string false "Валюта (ISO 4217); приоритетнее Accept-Currency"
+// @Param Accept-Currency header string false "Предпочитаемые валюты через запятую"
+// @Success 200 {object} services.Cart
+// @Router /cart [get]
+// @Router /guest/cart [get]
+func GetCart(c *gin.Context) {
+	cart, ok := requestCart(c, false)
+	if !ok {
+		return
+	}
+	respondCart(c, cart)
+}
+
+// AddCartItem обрабатывает добавление книги в корзину.
+// @Summary Добавление книги в корзину
+// @Description Добавляет книгу в корзину; если книга уже в корзине, количества складываются. Книга должна продаваться и быть на складе. Анонимная корзина создается при первом добавлении, ее токен возвращается в заголовке X-Cart-Token и в поле token.
+// @Tags cart
+// @Accept json
+// @Produce json
+// @Param X-Cart-Token header string false "Токен анонимной корзины"
+// @Param item body models.AddCartItemRequest true "Книга и количество"
+// @Success 200 {object} services.Cart
+// @Failure 400 {object} models.ErrorResponse "Invalid request"
+// @Failure 404 {object} models.ErrorResponse "Book not found"
+// @Failure 409 {object} models.ErrorResponse "book 1: insufficient stock"
+// @Router /cart/items [post]
+// @Router /guest/cart/items [post]
+func AddCartItem(c *gin.Context) {
+	var req models.AddCartItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.HandleError(c, http.StatusBadRequest, "Invalid request")
+		return
+	}
+	if req.Quantity == 0 {
+		req.Quantity = 1
+	}
+
+	cart, ok := requestCart(c, true)
+	if !ok {
+		return
+	}
+	if err := services.AddCartItem(cart, req.BookID, req.Quantity); err != nil {
+		respondCartError(c, err)
+		return
+	}
+	respondCart(c, cart)
+}
+
+// UpdateCartItem обрабатывает изменение количества книги в корзине.
+// @Summary Изменение количества книги в корзине
+// @Description Задает количество экземпляров книги, уже лежащей в корзине. Цена добавления обновляется до текущей.
+// @Tags cart
+// @Accept json
+// @Produce json
+// @Param X-Cart-Token header string false "Токен анонимной корзины"
+// @Param bookId path string true "Идентификатор книги"
+// @Param item body models.UpdateProductQuantityRequest true "Новое количество"
+// @Success 200 {object} services.Cart
+// @Failure 400 {object} models.ErrorResponse "Invalid request"
+// @Failure 404 {object} models.ErrorResponse "Book is not in cart"
+// @Failure 409 {object} models.ErrorResponse "book 1: insufficient stock"
+// @Router /cart/items/{bookId} [put]
+// @Router /guest/cart/items/{bookId} [put]
+func UpdateCartItem(c *gin.Context) {
+	bookID, err := strconv.ParseUint(c.Param("bookId"), 10, 64)
+	if err != nil {
+		utils.HandleError(c, http.StatusNotFound, "Book is not in cart")
+		return
+	}
+	var req models.UpdateProductQuantityRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.HandleError(c, http.StatusBadRequest, "Invalid request")
+		return
+	}
+
+	cart, ok := requestCart(c, false)
+	if !ok {
+		return
+	}
+	if cart == nil {
+		utils.HandleError(c, http.StatusNotFound, "Book is not in cart")
+		return
+	}
+	err = services.SetCartItemQuantity(cart, uint(bookID), req.Quantity)
+	var lineErr *services.OrderLineError
+	if errors.Is(err, gorm.ErrRecordNotFound) && !errors.As(err, &lineErr) {
+		utils.HandleError(c, http.StatusNotFound, "Book is not in cart")
+		return
+	}
+	if err != nil {
+		respondCartError(c, err)
+		return
+	}
+	respondCart(c, cart)
+}
+
+// RemoveCartItem обрабатывает удаление книги из корзины.
+// @Summary Удаление книги из корзины
+// @Description Удаляет книгу из корзины и возвращает оставшуюся корзину.
+// @Tags cart
+// @Accept json
+// @Produce json
+// @Param X-Cart-Token header string false "Токен анонимной корзины"
+// @Param bookId path string true "Идентификатор книги"
+// @Success 200 {object} services.Cart
+// @Failure 404 {object} models.ErrorResponse "Book is not in cart"
+// @Router /cart/items/{bookId} [delete]
+// @Router /guest/cart/items/{bookId} [delete]
+func RemoveCartItem(c *gin.Context) {
+	bookID, err := strconv.ParseUint(c.Param("bookId"), 10, 64)
+	if err != nil {
+		utils.HandleError(c, http.StatusNotFound, "Book is not in cart")
+		return
+	}
+
+	cart, ok := requestCart(c, false)
+	if !ok {
+		return
+	}
+	if cart == nil {
+		utils.HandleError(c, http.StatusNotFound, "Book is not in cart")
+		return
+	}
+	err = services.RemoveCartItem(cart, uint(bookID))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		utils.HandleError(c, http.StatusNotFound, "Book is not in cart")
+		return
+	}
+	if err != nil {
+		utils.HandleError(c, http.StatusInternalServerError, "Failed to update cart")
+		return
+	}
+	respondCart(c, cart)
+}
+
+// ClearCart обрабатывает очистку корзины.
+// @Summary Очистка корзины
+// @Description Удаляет из корзины все книги.
+// @Tags cart
+// @Accept json
+// @Produce json
+// @Param X-Cart-Token header string false "Токен анонимной корзины"
+// @Success 200 {object} models.MessageResponse "Cart cleared"
+// @Router /cart [delete]
+// @Router /guest/cart [delete]
+func ClearCart(c *gin.Context) {
+	cart, ok := requestCart(c, false)
+	if !ok {
+		return
+	}
+	if cart != nil {
+		if err := services.ClearCart(cart); err != nil {
+			utils.HandleError(c, http.StatusInternalServerError, "Failed to update cart")
+			return
+		}
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Cart cleared"})
+}
+
+// CheckoutCart обрабатывает оформление заказа из корзины.
+// @Summary Оформление корзины
+// @Description Оформляет содержимое корзины текущего пользователя как заказ (см. POST /orders) и убирает оформленные книги из корзины. Если в корзине есть недоступные книги, возвращается 409 — корзину нужно сначала поправить. Анонимную корзину оформить нельзя: ее книги переносятся в корзину пользователя при входе.
+// @Tags cart
+// @Accept json
+// @Produce json
+// @Param currency query string false "Валюта заказа (ISO 4217); приоритетнее Accept-Currency"
+// @Param Accept-Currency header string false "Предпочитаемые валюты через запятую"
+// @Success 201 {object} models.Order
+// @Failure 400 {object} models.ErrorResponse "order must contain at least one book"
+// @Failure 409 {object} models.ErrorResponse "cart contains unavailable books"
+// @Router /cart/checkout [post]
+func CheckoutCart(c *gin.Context) {
+	cart, err := services.UserCart(currentUsername(c))
+	if err != nil {
+		utils.HandleError(c, http.StatusInternalServerError, "Failed to load cart")
+		return
+	}
+	currency, rates, ok := orderCurrency(c)
+	if !ok {
+		return
+	}
+
+	order, err := services.CheckoutCart(cart, currency, rates)
+	if errors.Is(err, services.ErrCartNotReady) {
+		utils.HandleError(c, http.StatusConflict, err.Error())
+		return
+	}
+	if err != nil {
+		respondOrderError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, order)
+}
+
+// requestCart находит корзину запроса: у авторизованного пользователя — его корзину,
+// у анонимного — корзину по токену из X-Cart-Token. Если анонимной корзины нет, при create
+// она создается, а ее токен возвращается в заголовке X-Cart-Token; иначе возвращается nil.
+// Возвращает false, если ответ с ошибкой уже отправлен.
+func requestCart(c *gin.Context, create bool) (*services.Cart, bool) {
+	var cart *services.Cart
+	var err error
+	if username := currentUsername(c); username != "" {
+		cart, err = services.UserCart(username)
+	} else {
+		cart, err = nil, gorm.ErrRecordNotFound
+		if token := c.GetHeader(CartTokenHeader); token != "" {
+			cart, err = services.GuestCart(token)
+		}
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			cart, err = nil, nil
+			if create {
+				cart, err = services.NewGuestCart()
+			}
+		}
+		if cart != nil && cart.Token != nil {
+			c.Header(CartTokenHeader, *cart.Token)
+		}
+	}
+	if err != nil {
+		utils.HandleError(c, http.StatusInternalServerError, "Failed to load cart")
+		return nil, false
+	}
+	return cart, true
+}
+
+// respondCart отвечает корзиной с проверенными ценами и наличием. Отсутствующая анонимная
+// корзина отдается пустой.
+func respondCart(c *gin.Context, cart *services.Cart) {
+	currency, rates, ok := orderCurrency(c)
+	if !ok {
+		return
+	}
+	if cart == nil {
+		c.JSON(http.StatusOK, services.Cart{Items: []services.CartItem{}, Currency: currency})
+		return
+	}
+	if err := services.RevalidateCart(cart, currency, rates); err != nil {
+		utils.HandleError(c, http.StatusInternalServerError, "Failed to load cart")
+		return
+	}
+
+	books := make([]*services.Book, 0, len(cart.Items))
+	for _, item := range cart.Items {
+		if item.Book != nil {
+			books = append(books, item.Book)
+		}
+	}
+	if !localizeBooks(c, books...) || !convertBookPrices(c, books...) {
+		return
+	}
+	if cart.Items == nil {
+		cart.Items = []services.CartItem{}
+	}
+	c.JSON(http.StatusOK, cart)
+}
+
+// respondCartError отвечает на ошибку изменения корзины.
+func respondCartError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrInsufficientStock):
+		utils.HandleError(c, http.StatusConflict, err.Error())
+	case errors.Is(err, services.ErrInvalidQuantity), errors.Is(err, services.ErrNotForSale):
+		utils.HandleError(c, http.StatusBadRequest, err.Error())
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		utils.HandleError(c, http.StatusNotFound, "Book not found")
+	default:
+		utils.HandleError(c, http.StatusInternalServerError, "Failed to update cart")
+	}
+}
@@ -0,0 +1,115 @@
+package controllers
+
+import (
+	"Projectmugen/internal/models"
+	"Projectmugen/internal/services"
+	"Projectmugen/internal/utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// GetCategories обрабатывает запрос на получение списка рубрик.
+// @Summary Получение списка рубрик
+// @Description Возвращает все рубрики каталога в алфавитном порядке. Книга ссылается на рубрику через поле category_id.
+// @Tags categories
+// @Accept json
+// @Produce json
+// @Success 200 {array} models.Category
+// @Router /categories [get]
+func GetCategories(c *gin.Context) {
+	categories := []models.Category{}
+	services.Db.Order("name asc").Find(&categories)
+	c.JSON(http.StatusOK, categories)
+}
+
+// CreateCategory обрабатывает запрос на создание рубрики.
+// @Summary Создание рубрики
+// @Description Создает новую рубрику каталога.
+// @Tags categories
+// @Accept json
+// @Produce json
+// @Param category body models.Category true "Данные рубрики"
+// @Success 201 {object} models.Category
+// @Failure 400 {object} models.ErrorResponse "Invalid request"
+// @Router /categories [post]
+func CreateCategory(c *gin.Context) {
+	var category models.Category
+	if err := c.BindJSON(&category); err != nil || category.Name == "" {
+		utils.HandleError(c, http.StatusBadRequest, "Invalid request")
+		return
+	}
+	category.ID = 0
+	category.Products = nil
+
+	if err := services.Db.Create(&category).Error; err != nil {
+		utils.HandleError(c, http.StatusInternalServerError, "Failed to save category")
+		return
+	}
+	c.JSON(http.StatusCreated, category)
+}
+
+// UpdateCategory обрабатывает запрос на обновление рубрики.
+// @Summary Обновление рубрики по ID
+// @Description Обновляет название и описание рубрики.
+// @Tags categories
+// @Accept json
+// @Produce json
+// @Param id path string true "Идентификатор рубрики"
+// @Param category body models.Category true "Обновленные данные рубрики"
+// @Success 200 {object} models.Category
+// @Failure 400 {object} models.ErrorResponse "Invalid request"
+// @Failure 404 {object} models.ErrorResponse "Category not found"
+// @Router /categories/{id} [put]
+func UpdateCategory(c *gin.Context) {
+	var category models.Category
+	if err := services.Db.First(&category, c.Param("id")).Error; err != nil {
+		utils.HandleError(c, http.StatusNotFound, "Category not found")
+		return
+	}
+
+	var input models.Category
+	if err := c.BindJSON(&input); err != nil || input.Name == "" {
+		utils.HandleError(c, http.StatusBadRequest, "Invalid request")
+		return
+	}
+
+	category.Name = input.Name
+	category.Description = input.Description
+	if err := services.Db.Save(&category).Error; err != nil {
+		utils.HandleError(c, http.StatusInternalServerError, "Failed to save category")
+		return
+	}
+	c.JSON(http.StatusOK, category)
+}
+
+// DeleteCategory обрабатывает запрос на удаление рубрики.
+// @Summary Удаление рубрики по ID
+// @Description Удаляет рубрику; у ее книг ссылка на рубрику обнуляется, версии книг увеличиваются, а изменения попадают в историю.
+// @Tags categories
+// @Accept json
+// @Produce json
+// @Param id path string true "Идентификатор рубрики"
+// @Success 200 {object} models.MessageResponse "Category deleted"
+// @Failure 404 {object} models.ErrorResponse "Category not found"
+// @Router /categories/{id} [delete]
+func DeleteCategory(c *gin.Context) {
+	var category models.Category
+	if err := services.Db.First(&category, c.Param("id")).Error; err != nil {
+		utils.HandleError(c, http.StatusNotFound, "Category not found")
+		return
+	}
+
+	err := services.Db.Transaction(func(tx *gorm.DB) error {
+		if err := services.DetachBooks(tx, "category_id", uint(category.ID), currentUsername(c)); err != nil {
+			return err
+		}
+		return tx.Delete(&category).Error
+	})
+	if err != nil {
+		utils.HandleError(c, http.StatusInternalServerError, "Failed to delete category")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Category deleted"})
+}
@@ -0,0 +1,109 @@
+package controllers
+
+import (
+	"Projectmugen/internal/services"
+	"Projectmugen/internal/utils"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// UploadBookCover обрабатывает загрузку обложки книги.
+// @Summary Загрузка обложки книги
+// @Description Принимает изображение JPEG, PNG или WebP, сохраняет оригинал и строит миниатюры. Предыдущая обложка заменяется.
+// @Tags books
+// @Accept multipart/form-data
+// @Produce json
+// @Param id path string true "Идентификатор книги"
+// @Param file formData file true "Изображение обложки"
+// @Success 200 {object} services.BookCover
+// @Failure 400 {object} models.ErrorResponse "Invalid image"
+// @Failure 404 {object} models.ErrorResponse "Book not found"
+// @Failure 413 {object} models.ErrorResponse "File too large"
+// @Failure 415 {object} models.ErrorResponse "Unsupported image type"
+// @Failure 500 {object} models.ErrorResponse "Failed to save cover"
+// @Router /books/{id}/cover [post]
+func UploadBookCover(c *gin.Context) {
+	var book services.Book
+	if err := services.Db.First(&book, c.Param("id")).Error; err != nil {
+		utils.HandleError(c, http.StatusNotFound, "Book not found")
+		return
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		utils.HandleError(c, http.StatusBadRequest, "File is required")
+		return
+	}
+	if fileHeader.Size > services.CoverMaxBytes {
+		utils.HandleError(c, http.StatusRequestEntityTooLarge, "File too large")
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		utils.HandleError(c, http.StatusBadRequest, "Invalid image")
+		return
+	}
+	defer file.Close()
+
+	// Читаем на байт больше лимита, чтобы не доверять размеру из заголовка части
+	data, err := io.ReadAll(io.LimitReader(file, services.CoverMaxBytes+1))
+	if err != nil {
+		utils.HandleError(c, http.StatusBadRequest, "Invalid image")
+		return
+	}
+	if int64(len(data)) > services.CoverMaxBytes {
+		utils.HandleError(c, http.StatusRequestEntityTooLarge, "File too large")
+		return
+	}
+
+	cover, err := services.SaveCover(c.Request.Context(), book.ID, data)
+	switch {
+	case errors.Is(err, services.ErrUnsupportedImage):
+		utils.HandleError(c, http.StatusUnsupportedMediaType, err.Error())
+		return
+	case errors.Is(err, services.ErrImageTooLarge):
+		utils.HandleError(c, http.StatusBadRequest, err.Error())
+		return
+	case err != nil:
+		utils.HandleError(c, http.StatusInternalServerError, "Failed to save cover")
+		return
+	}
+
+	c.JSON(http.StatusOK, cover)
+}
+
+// DeleteBookCover обрабатывает удаление обложки книги.
+// @Summary Удаление обложки книги
+// @Description Удаляет обложку книги и все ее миниатюры.
+// @Tags books
+// @Accept json
+// @Produce json
+// @Param id path string true "Идентификатор книги"
+// @Success 200 {object} models.MessageResponse "Cover deleted"
+// @Failure 404 {object} models.ErrorResponse "Cover not found"
+// @Failure 500 {object} models.ErrorResponse "Failed to delete cover"
+// @Router /books/{id}/cover [delete]
+func DeleteBookCover(c *gin.Context) {
+	var book services.Book
+	if err := services.Db.First(&book, c.Param("id")).Error; err != nil {
+		utils.HandleError(c, http.StatusNotFound, "Book not found")
+		return
+	}
+
+	err := services.DeleteCover(c.Request.Context(), book.ID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		utils.HandleError(c, http.StatusNotFound, "Cover not found")
+		return
+	}
+	if err != nil {
+		utils.HandleError(c, http.StatusInternalServerError, "Failed to delete cover")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Cover deleted"})
+}
@@ -0,0 +1,296 @@
+package controllers
+
+import (
+	"Projectmugen/internal/models"
+	"Projectmugen/internal/services"
+	"Projectmugen/internal/utils"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// GetCurrencyRates обрабатывает запрос на получение курсов валют.
+// @Summary Получение курсов валют
+// @Description Возвращает курсы всех валют к базовой валюте магазина, включая саму базовую валюту с курсом 1.
+// @Tags currencies
+// @Accept json
+// @Produce json
+// @Success 200 {array} services.CurrencyRate
+// @Router /currencies [get]
+func GetCurrencyRates(c *gin.Context) {
+	rates, err := services.LoadRates(services.Db)
+	if err != nil {
+		utils.HandleError(c, http.StatusInternalServerError, "Failed to load currency rates")
+		return
+	}
+
+	list := make([]services.CurrencyRate, 0, len(rates))
+	for _, rate := range rates {
+		list = append(list, rate)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Currency < list[j].Currency })
+	c.JSON(http.StatusOK, list)
+}
+
+// SetCurrencyRate обрабатывает запрос на создание или обновление курса валюты.
+// @Summary Установка курса валюты
+// @Description Создает или обновляет курс валюты к базовой валюте магазина и шаг округления сконвертированных цен.
+// @Tags currencies
+// @Accept json
+// @Produce json
+// @Param code path string true "Код валюты ISO 4217"
+// @Param request body models.SetCurrencyRateRequest true "Курс валюты"
+// @Success 200 {object} services.CurrencyRate
+// @Failure 400 {object} models.ErrorResponse "Invalid request"
+// @Router /currencies/{code} [put]
+func SetCurrencyRate(c *gin.Context) {
+	var req models.SetCurrencyRateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.HandleError(c, http.StatusBadRequest, "Invalid request")
+		return
+	}
+
+	rate, err := buildCurrencyRate(c.Param("code"), req.Rate, req.RoundTo)
+	if err != nil {
+		utils.HandleError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	rate.Username = currentUsername(c)
+
+	if err := services.Db.Clauses(clause.OnConflict{UpdateAll: true}).Create(&rate).Error; err != nil {
+		utils.HandleError(c, http.StatusInternalServerError, "Failed to save currency rate")
+		return
+	}
+	c.JSON(http.StatusOK, rate)
+}
+
+// DeleteCurrencyRate обрабатывает запрос на удаление курса валюты.
+// @Summary Удаление курса валюты
+// @Description Удаляет курс валюты; цены в этой валюте больше не отображаются.
+// @Tags currencies
+// @Accept json
+// @Produce json
+// @Param code path string true "Код валюты ISO 4217"
+// @Success 200 {object} models.MessageResponse "Currency rate deleted"
+// @Failure 404 {object} models.ErrorResponse "Currency rate not found"
+// @Router /currencies/{code} [delete]
+func DeleteCurrencyRate(c *gin.Context) {
+	result := services.Db.Delete(&services.CurrencyRate{}, "currency = ?", strings.ToUpper(c.Param("code")))
+	if result.Error != nil {
+		utils.HandleError(c, http.StatusInternalServerError, "Failed to delete currency rate")
+		return
+	}
+	if result.RowsAffected == 0 {
+		utils.HandleError(c, http.StatusNotFound, "Currency rate not found")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Currency rate deleted"})
+}
+
+// ImportCurrencyRates обрабатывает загрузку курсов валют из CSV-файла.
+// @Summary Импорт курсов валют
+// @Description Принимает CSV-файл с заголовком currency,rate[,round_to] и создает или обновляет перечисленные курсы. Файл применяется целиком или не применяется вовсе.
+// @Tags currencies
+// @Accept multipart/form-data
+// @Produce json
+// @Param file formData file true "CSV-файл с курсами"
+// @Success 200 {array} services.CurrencyRate
+// @Failure 400 {object} models.ErrorResponse "Invalid CSV"
+// @Router /currencies/import [post]
+func ImportCurrencyRates(c *gin.Context) {
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		utils.HandleError(c, http.StatusBadRequest, "File is required")
+		return
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		utils.HandleError(c, http.StatusBadRequest, "Invalid CSV")
+		return
+	}
+	defer file.Close()
+
+	rates, err := parseCurrencyRatesCSV(file)
+	if err != nil {
+		utils.HandleError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	for i := range rates {
+		rates[i].Username = currentUsername(c)
+	}
+
+	err = services.Db.Transaction(func(tx *gorm.DB) error {
+		if len(rates) == 0 {
+			return nil
+		}
+		return tx.Clauses(clause.OnConflict{UpdateAll: true}).Create(&rates).Error
+	})
+	if err != nil {
+		utils.HandleError(c, http.StatusInternalServerError, "Failed to save currency rates")
+		return
+	}
+	c.JSON(http.StatusOK, rates)
+}
+
+// parseCurrencyRatesCSV разбирает CSV с курсами; ошибка содержит номер строки.
+func parseCurrencyRatesCSV(r io.Reader) ([]services.CurrencyRate, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, errors.New("invalid CSV")
+	}
+	columns := make(map[string]int)
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	currencyCol, ok1 := columns["currency"]
+	rateCol, ok2 := columns["rate"]
+	if !ok1 || !ok2 {
+		return nil, errors.New("CSV must have currency and rate columns")
+	}
+	roundCol, hasRound := columns["round_to"]
+
+	var rates []services.CurrencyRate
+	seen := make(map[string]bool)
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid CSV", line)
+		}
+		field := func(i int) string {
+			if i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+
+		value, err := strconv.ParseFloat(field(rateCol), 64)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid rate", line)
+		}
+		var roundTo int64
+		if hasRound && field(roundCol) != "" {
+			if roundTo, err = strconv.ParseInt(field(roundCol), 10, 64); err != nil {
+				return nil, fmt.Errorf("line %d: invalid round_to", line)
+			}
+		}
+		rate, err := buildCurrencyRate(field(currencyCol), value, roundTo)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", line, err)
+		}
+		if seen[rate.Currency] {
+			return nil, fmt.Errorf("line %d: duplicate currency %s", line, rate.Currency)
+		}
+		seen[rate.Currency] = true
+		rates = append(rates, rate)
+	}
+	return rates, nil
+}
+
+// buildCurrencyRate проверяет код валюты, курс и шаг округления. Курс базовой валюты всегда
+// равен 1 и не редактируется.
+func buildCurrencyRate(code string, value float64, roundTo int64) (services.CurrencyRate, error) {
+	currency, err := services.NormalizeCurrency(code)
+	if err != nil || code == "" {
+		return services.CurrencyRate{}, errors.New("invalid currency code")
+	}
+	if currency == services.DefaultCurrency {
+		return services.CurrencyRate{}, errors.New("base currency rate cannot be changed")
+	}
+	if value <= 0 {
+		return services.CurrencyRate{}, errors.New("rate must be positive")
+	}
+	if roundTo == 0 {
+		roundTo = 1
+	}
+	if roundTo < 0 {
+		return services.CurrencyRate{}, errors.New("round_to must be positive")
+	}
+	return services.CurrencyRate{Currency: currency, Rate: value, RoundTo: roundTo}, nil
+}
+
+// requestedCurrency возвращает валюту, в которой клиент хочет видеть цены: параметр currency
+// имеет приоритет над заголовком Accept-Currency. Пустая строка означает, что пересчет не нужен.
+// Для неизвестной валюты из параметра возвращается ошибка; из заголовка выбирается первая
+// поддерживаемая валюта, а если таких нет, цены остаются в исходной валюте.
+func requestedCurrency(c *gin.Context, rates services.Rates) (string, error) {
+	if code := c.Query("currency"); code != "" {
+		code = strings.ToUpper(strings.TrimSpace(code))
+		if _, ok := rates[code]; !ok {
+			return "", services.ErrUnknownCurrency
+		}
+		return code, nil
+	}
+	for _, part := range strings.Split(c.GetHeader("Accept-Currency"), ",") {
+		code := strings.ToUpper(strings.TrimSpace(strings.SplitN(part, ";", 2)[0]))
+		if _, ok := rates[code]; ok {
+			return code, nil
+		}
+	}
+	return "", nil
+}
+
+// convertBookPrices пересчитывает цены книг в валюту, запрошенную клиентом. Возвращает false,
+// если ответ с ошибкой уже отправлен.
+func convertBookPrices(c *gin.Context, books ...*services.Book) bool {
+	c.Writer.Header().Add("Vary", "Accept-Currency")
+	if c.Query("currency") == "" && c.GetHeader("Accept-Currency") == "" {
+		return true
+	}
+
+	rates, err := services.LoadRates(services.Db)
+	if err != nil {
+		utils.HandleError(c, http.StatusInternalServerError, "Failed to load currency rates")
+		return false
+	}
+	target, err := requestedCurrency(c, rates)
+	if err != nil {
+		utils.HandleError(c, http.StatusBadRequest, err.Error())
+		return false
+	}
+	if target == "" {
+		return true
+	}
+
+	for _, book := range books {
+		if book.Price == nil || book.Currency == target {
+			continue
+		}
+		price, _, err := rates.Convert(*book.Price, book.Currency, target)
+		if err != nil {
+			// Для валюты книги нет курса: оставляем цену как есть
+			continue
+		}
+		book.Price = &price
+		if book.RegularPrice != nil {
+			regular, _, _ := rates.Convert(*book.RegularPrice, book.Currency, target)
+			book.RegularPrice = &regular
+		}
+		book.Currency = target
+	}
+	return true
+}
+
+// convertBookListPrices пересчитывает цены списка книг; см. convertBookPrices.
+func convertBookListPrices(c *gin.Context, books []services.Book) bool {
+	pointers := make([]*services.Book, len(books))
+	for i := range books {
+		pointers[i] = &books[i]
+	}
+	return convertBookPrices(c, pointers...)
+}
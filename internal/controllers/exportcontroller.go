@@ -0,0 +1,179 @@
+package controllers
+
+import (
+	"Projectmugen/internal/export"
+	"Projectmugen/internal/models"
+	"Projectmugen/internal/services"
+	"Projectmugen/internal/utils"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// bookExportColumns описывает колонки, доступные для выгрузки каталога.
+var bookExportColumns = map[string]func(b *services.Book) interface{}{
+	"id":     func(b *services.Book) interface{} { return b.ID },
+	"title":  func(b *services.Book) interface{} { return b.Title },
+	"author": func(b *services.Book) interface{} { return b.Author },
+	"year":   func(b *services.Book) interface{} { return b.Year },
+	"publisher_id": func(b *services.Book) interface{} {
+		if b.PublisherID == nil {
+			return nil
+		}
+		return *b.PublisherID
+	},
+	"category_id": func(b *services.Book) interface{} {
+		if b.CategoryID == nil {
+			return nil
+		}
+		return *b.CategoryID
+	},
+	"work_id": func(b *services.Book) interface{} {
+		if b.WorkID == nil {
+			return nil
+		}
+		return *b.WorkID
+	},
+	"series_id": func(b *services.Book) interface{} {
+		if b.SeriesID == nil {
+			return nil
+		}
+		return *b.SeriesID
+	},
+	"series_volume": func(b *services.Book) interface{} {
+		if b.SeriesVolume == nil {
+			return nil
+		}
+		return *b.SeriesVolume
+	},
+	"stock": func(b *services.Book) interface{} { return b.Stock },
+	"price": func(b *services.Book) interface{} {
+		if b.Price == nil {
+			return nil
+		}
+		return *b.Price
+	},
+	"currency": func(b *services.Book) interface{} { return b.Currency },
+	"isbn13": func(b *services.Book) interface{} {
+		if b.ISBN13 == nil {
+			return nil
+		}
+		return *b.ISBN13
+	},
+}
+
+// defaultExportColumns задает набор и порядок колонок, если параметр columns не передан.
+var defaultExportColumns = []string{"id", "title", "author", "year", "publisher_id", "category_id", "isbn13", "stock", "price", "currency"}
+
+// ExportBooks обрабатывает запрос на потоковую выгрузку каталога.
+// @Summary Выгрузка каталога книг
+// @Description Потоково выгружает отфильтрованный список книг в формате CSV, NDJSON или XLSX, не загружая его целиком в память. Название и автор ищутся без учета опечаток.
+// @Tags books
+// @Produce text/csv
+// @Produce application/x-ndjson
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param format query string false "Формат выгрузки (csv, ndjson, xlsx)" default(csv)
+// @Param columns query string false "Список колонок через запятую (id, title, author, year, publisher_id, category_id, work_id, series_id, series_volume, isbn13, stock, price, currency)"
+// @Param title query string false "Фильтр по заголовку книги"
+// @Param author query string false "Фильтр по автору"
+// @Param startYear query int false "Год издания от"
+// @Param endYear query int false "Год издания до"
+// @Param publisher_id query int false "Фильтр по издателю"
+// @Param category_id query int false "Фильтр по рубрике"
+// @Param work_id query int false "Фильтр по произведению"
+// @Param series_id query int false "Фильтр по серии"
+// @Param language query string false "Фильтр по языку текста книги"
+// @Param tags query []string false "Фильтр по тегам (через запятую или повтором параметра)" collectionFormat(multi)
+// @Param tag_mode query string false "all — книги со всеми тегами, any — с любым из них" Enums(all, any) default(all)
+// @Param available query bool false "Только книги в наличии (true) или отсутствующие (false)"
+// @Success 200 {file} file "Файл выгрузки"
+// @Failure 400 {object} models.ErrorResponse "Invalid export parameters"
+// @Failure 500 {object} models.ErrorResponse "Failed to export books"
+// @Router /books/export [get]
+func ExportBooks(c *gin.Context) {
+	format := strings.ToLower(c.DefaultQuery("format", export.FormatCSV))
+
+	var filter models.BookFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		utils.HandleError(c, http.StatusBadRequest, "Invalid filter")
+		return
+	}
+	// Выгрузка содержит ровно то, что подходит под фильтр, без поиска с опечатками
+	filter.Exact = true
+
+	columns := defaultExportColumns
+	if raw := c.Query("columns"); raw != "" {
+		columns = nil
+		for _, column := range strings.Split(raw, ",") {
+			column = strings.TrimSpace(strings.ToLower(column))
+			if _, ok := bookExportColumns[column]; !ok {
+				utils.HandleError(c, http.StatusBadRequest, "Unknown column: "+column)
+				return
+			}
+			columns = append(columns, column)
+		}
+	}
+
+	writer, err := export.NewWriter(format, c.Writer)
+	if err != nil {
+		utils.HandleError(c, http.StatusBadRequest, "Unsupported format")
+		return
+	}
+
+	rows, err := applyBookFilters(services.Db.Model(&services.Book{}), filter).Order("id asc").Rows()
+	if err != nil {
+		utils.HandleError(c, http.StatusInternalServerError, "Failed to export books")
+		return
+	}
+	defer rows.Close()
+
+	filename := fmt.Sprintf("books-%s.%s", time.Now().Format("20060102-150405"), format)
+	c.Header("Content-Type", export.ContentType(format))
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	c.Status(http.StatusOK)
+
+	if err := writer.WriteHeader(columns); err != nil {
+		abortExport(c, err)
+		return
+	}
+	for rows.Next() {
+		var book services.Book
+		if err := services.Db.ScanRows(rows, &book); err != nil {
+			abortExport(c, err)
+			return
+		}
+		values := make([]interface{}, len(columns))
+		for i, column := range columns {
+			values[i] = bookExportColumns[column](&book)
+		}
+		if err := writer.WriteRow(values); err != nil {
+			abortExport(c, err)
+			return
+		}
+		c.Writer.Flush()
+	}
+	if err := rows.Err(); err != nil {
+		abortExport(c, err)
+		return
+	}
+	if err := writer.Close(); err != nil {
+		abortExport(c, err)
+	}
+}
+
+// abortExport прерывает выгрузку после ошибки. Статус и заголовки уже отправлены, поэтому
+// соединение разрывается: клиент получает незавершенный ответ, а не обрезанный файл,
+// неотличимый от полного.
+func abortExport(c *gin.Context, err error) {
+	log.Println("export:", err)
+	conn, _, err := c.Writer.Hijack()
+	if err != nil {
+		log.Println("export: failed to abort the response:", err)
+		return
+	}
+	conn.Close()
+}
@@ -0,0 +1,121 @@
+package controllers
+
+import (
+	"Projectmugen/internal/models"
+	"Projectmugen/internal/services"
+	"Projectmugen/internal/utils"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ImportBooks обрабатывает запрос на пакетный импорт книг с дедупликацией по ISBN.
+// @Summary Импорт книг
+// @Description Принимает массив книг. Книги с уже известным ISBN-13 обновляются, повторы внутри пакета пропускаются, книги без ISBN создаются.
+// @Tags books
+// @Accept json
+// @Produce json
+// @Param books body []services.Book true "Импортируемые книги"
+// @Success 200 {object} models.ImportBooksResponse
+// @Failure 400 {object} models.ErrorResponse "Invalid request"
+// @Failure 500 {object} models.ErrorResponse "Failed to import books"
+// @Router /books/import [post]
+func ImportBooks(c *gin.Context) {
+	var books []services.Book
+	if err := c.BindJSON(&books); err != nil {
+		utils.HandleError(c, http.StatusBadRequest, "Invalid request")
+		return
+	}
+
+	result := models.ImportBooksResponse{Errors: []models.ImportError{}}
+	seen := make(map[string]bool)
+
+	err := services.Db.Transaction(func(tx *gorm.DB) error {
+		for i := range books {
+			book := books[i]
+			book.ID = 0
+			book.Version = 1
+			book.CreatedAt = time.Time{}
+			book.DeletedAt = gorm.DeletedAt{}
+			book.Stock = 0
+			book.Price, book.RegularPrice, book.Currency, book.OnSale = nil, nil, "", false
+
+			if err := services.NormalizeBookISBN(&book); err != nil {
+				result.Skipped++
+				result.Errors = append(result.Errors, models.ImportError{Index: i, Message: err.Error()})
+				continue
+			}
+			language, err := services.NormalizeLanguage(book.Language)
+			if err != nil {
+				result.Skipped++
+				result.Errors = append(result.Errors, models.ImportError{Index: i, Message: err.Error()})
+				continue
+			}
+			book.Language = language
+
+			if book.ISBN13 == nil {
+				if err := createImportedBook(tx, &book, currentUsername(c)); err != nil {
+					return err
+				}
+				result.Created++
+				continue
+			}
+
+			if seen[*book.ISBN13] {
+				result.Skipped++
+				result.Errors = append(result.Errors, models.ImportError{Index: i, Message: "duplicate ISBN in batch"})
+				continue
+			}
+			seen[*book.ISBN13] = true
+
+			var existing services.Book
+			err = tx.Where("isbn13 = ?", *book.ISBN13).First(&existing).Error
+			switch {
+			case errors.Is(err, gorm.ErrRecordNotFound):
+				if err := createImportedBook(tx, &book, currentUsername(c)); err != nil {
+					return err
+				}
+				result.Created++
+			case err != nil:
+				return err
+			default:
+				if err := services.BumpBookVersion(tx, existing.ID, existing.Version); err != nil {
+					return err
+				}
+				book.ID = existing.ID
+				book.Version = existing.Version + 1
+				book.Stock = existing.Stock
+				book.CreatedAt = existing.CreatedAt
+				book.Price, book.RegularPrice, book.Currency, book.OnSale = existing.Price, existing.RegularPrice, existing.Currency, existing.OnSale
+				if err := tx.Omit(clause.Associations, "Stock", "Price", "RegularPrice", "Currency", "OnSale").Save(&book).Error; err != nil {
+					return err
+				}
+				if err := services.RecordBookRevision(tx, services.RevisionUpdate, currentUsername(c), &existing, &book); err != nil {
+					return err
+				}
+				result.Updated++
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		utils.HandleError(c, http.StatusInternalServerError, "Failed to import books")
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func createImportedBook(tx *gorm.DB, book *services.Book, username string) error {
+	if err := tx.Omit(clause.Associations).Create(book).Error; err != nil {
+		return err
+	}
+	if err := services.LinkAuthorsFromString(tx, book); err != nil {
+		return err
+	}
+	return services.RecordBookRevision(tx, services.RevisionCreate, username, nil, book)
+}
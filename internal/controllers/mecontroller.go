@@ -0,0 +1,108 @@
+package controllers
+
+import (
+	"Projectmugen/internal/services"
+	"Projectmugen/internal/utils"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm/clause"
+)
+
+// GetMyRecommendations обрабатывает запрос на получение персональных рекомендаций.
+// @Summary Персональные рекомендации
+// @Description Возвращает книги, подобранные по заказам, списку желаний и отзывам пользователя, с объяснением вида «because you bought X». Рекомендации пересчитываются в фоне; пока истории нет, возвращаются популярные книги.
+// @Tags recommendations
+// @Accept json
+// @Produce json
+// @Param limit query int false "Количество рекомендаций" default(20)
+// @Param currency query string false "Валюта отображения цен (ISO 4217); приоритетнее Accept-Currency"
+// @Param Accept-Currency header string false "Предпочитаемые валюты отображения цен через запятую"
+// @Success 200 {array} services.Recommendation
+// @Router /me/recommendations [get]
+func GetMyRecommendations(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if limit <= 0 || limit > services.UserRecommendationsPerUser {
+		limit = 20
+	}
+
+	recommendations, err := services.UserRecommendations(currentUsername(c), limit)
+	if err != nil {
+		utils.HandleError(c, http.StatusInternalServerError, "Failed to load recommendations")
+		return
+	}
+	if !convertRecommendationPrices(c, recommendations) {
+		return
+	}
+	c.JSON(http.StatusOK, recommendations)
+}
+
+// GetWishlist обрабатывает запрос на получение списка желаний пользователя.
+// @Summary Список желаний
+// @Description Возвращает книги из списка желаний текущего пользователя, начиная с последних добавленных.
+// @Tags wishlist
+// @Accept json
+// @Produce json
+// @Success 200 {array} services.WishlistItem
+// @Router /me/wishlist [get]
+func GetWishlist(c *gin.Context) {
+	items := []services.WishlistItem{}
+	services.Db.Preload("Book").Preload("Book.Cover").
+		Joins("JOIN books ON books.id = wishlist_items.book_id AND books.deleted_at IS NULL").
+		Where("wishlist_items.username = ?", currentUsername(c)).
+		Order("wishlist_items.created_at desc").
+		Find(&items)
+	c.JSON(http.StatusOK, items)
+}
+
+// AddToWishlist обрабатывает добавление книги в список желаний.
+// @Summary Добавление книги в список желаний
+// @Description Добавляет книгу в список желаний текущего пользователя. Повторное добавление ничего не меняет.
+// @Tags wishlist
+// @Accept json
+// @Produce json
+// @Param bookId path string true "Идентификатор книги"
+// @Success 200 {object} services.WishlistItem
+// @Failure 404 {object} models.ErrorResponse "Book not found"
+// @Router /me/wishlist/{bookId} [put]
+func AddToWishlist(c *gin.Context) {
+	var book services.Book
+	if err := services.Db.First(&book, c.Param("bookId")).Error; err != nil {
+		utils.HandleError(c, http.StatusNotFound, "Book not found")
+		return
+	}
+
+	item := services.WishlistItem{Username: currentUsername(c), BookID: book.ID}
+	if err := services.Db.Clauses(clause.OnConflict{DoNothing: true}).Create(&item).Error; err != nil {
+		utils.HandleError(c, http.StatusInternalServerError, "Failed to update wishlist")
+		return
+	}
+	services.Db.Where("username = ? AND book_id = ?", item.Username, item.BookID).First(&item)
+	item.Book = &book
+	c.JSON(http.StatusOK, item)
+}
+
+// RemoveFromWishlist обрабатывает удаление книги из списка желаний.
+// @Summary Удаление книги из списка желаний
+// @Description Удаляет книгу из списка желаний текущего пользователя.
+// @Tags wishlist
+// @Accept json
+// @Produce json
+// @Param bookId path string true "Идентификатор книги"
+// @Success 200 {object} models.MessageResponse "Removed from wishlist"
+// @Failure 404 {object} models.ErrorResponse "Book is not in wishlist"
+// @Router /me/wishlist/{bookId} [delete]
+func RemoveFromWishlist(c *gin.Context) {
+	result := services.Db.Where("username = ? AND book_id = ?", currentUsername(c), c.Param("bookId")).
+		Delete(&services.WishlistItem{})
+	if result.Error != nil {
+		utils.HandleError(c, http.StatusInternalServerError, "Failed to update wishlist")
+		return
+	}
+	if result.RowsAffected == 0 {
+		utils.HandleError(c, http.StatusNotFound, "Book is not in wishlist")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Removed from wishlist"})
+}
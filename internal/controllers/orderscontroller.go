@@ -0,0 +1,335 @@
+package controllers
+
+import (
+	"Projectmugen/internal/models"
+	"Projectmugen/internal/services"
+	"Projectmugen/internal/utils"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// CreateOrder обрабатывает запрос на оформление заказа.
+// @Summary Оформление заказа
+// @Description Оформляет заказ текущего пользователя. В одной транзакции проверяет книги и количества, блокирует и списывает остатки, фиксирует цены в строках заказа и считает сумму. Валюта заказа выбирается как при показе цен (currency или Accept-Currency), по умолчанию — базовая валюта магазина. Право скачивать электронные версии купленных книг покупатель получает после оплаты заказа.
+// @Tags orders
+// @Accept json
+// @Produce json
+// @Param order body models.CreateOrderRequest true "Книги и количества"
+// @Param currency query string false "Валюта заказа (ISO 4217); приоритетнее Accept-Currency"
+// @Param Accept-Currency header string false "Предпочитаемые валюты через запятую"
+// @Success 201 {object} models.Order
+// @Failure 400 {object} models.ErrorResponse "Invalid request"
+// @Failure 409 {object} models.ErrorResponse "book 1: insufficient stock"
+// @Router /orders [post]
+func CreateOrder(c *gin.Context) {
+	var req models.CreateOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.HandleError(c, http.StatusBadRequest, "Invalid request")
+		return
+	}
+
+	order, ok := placeOrder(c, req.Products)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusCreated, order)
+}
+
+// GetMyOrders обрабатывает запрос на получение заказов текущего пользователя.
+// @Summary Мои заказы
+// @Description Возвращает заказы текущего пользователя со строками, по умолчанию начиная с последних. Поддерживает фильтры по статусу и дате оформления, сортировку и пагинацию.
+// @Tags orders
+// @Accept json
+// @Produce json
+// @Param page query int false "Номер страницы" default(1)
+// @Param limit query int false "Количество заказов на странице" default(10)
+// @Param status query []string false "Статусы (через запятую или повтором параметра)" collectionFormat(multi)
+// @Param from query string false "Оформлены начиная с даты (YYYY-MM-DD)"
+// @Param to query string false "Оформлены по дату включительно (YYYY-MM-DD)"
+// @Param sort query string false "Поле для сортировки" Enums(id, created_at, total, status) default(created_at)
+// @Param order query string false "Порядок сортировки" Enums(asc, desc) default(desc)
+// @Success 200 {object} models.OrderResponse
+// @Failure 400 {object} models.ErrorResponse "Invalid filter"
+// @Router /me/orders [get]
+func GetMyOrders(c *gin.Context) {
+	listOrders(c, services.Db.Where("username = ?", currentUsername(c)))
+}
+
+// GetAdminOrders обрабатывает запрос на получение заказов всех пользователей.
+// @Summary Заказы магазина
+// @Description Возвращает заказы всех пользователей со строками, по умолчанию начиная с последних. Поддерживает фильтры по покупателю, статусу и дате оформления, сортировку и пагинацию.
+// @Tags orders
+// @Accept json
+// @Produce json
+// @Param page query int false "Номер страницы" default(1)
+// @Param limit query int false "Количество заказов на странице" default(10)
+// @Param username query string false "Покупатель"
+// @Param status query []string false "Статусы (через запятую или повтором параметра)" collectionFormat(multi)
+// @Param from query string false "Оформлены начиная с даты (YYYY-MM-DD)"
+// @Param to query string false "Оформлены по дату включительно (YYYY-MM-DD)"
+// @Param sort query string false "Поле для сортировки" Enums(id, created_at, total, status) default(created_at)
+// @Param order query string false "Порядок сортировки" Enums(asc, desc) default(desc)
+// @Success 200 {object} models.OrderResponse
+// @Failure 400 {object} models.ErrorResponse "Invalid filter"
+// @Router /admin/orders [get]
+func GetAdminOrders(c *gin.Context) {
+	query := services.Db
+	if username := c.Query("username"); username != "" {
+		query = query.Where("username = ?", username)
+	}
+	listOrders(c, query)
+}
+
+// GetOrder обрабатывает запрос на получение заказа.
+// @Summary Заказ
+// @Description Возвращает заказ со строками, книгами каталога в поле product и историей статусов. Покупатель видит только свои заказы.
+// @Tags orders
+// @Accept json
+// @Produce json
+// @Param id path string true "Идентификатор заказа"
+// @Success 200 {object} models.Order
+// @Failure 404 {object} models.ErrorResponse "Order not found"
+// @Router /orders/{id} [get]
+func GetOrder(c *gin.Context) {
+	var order models.Order
+	query := services.WithOrderDetails(services.Db)
+	if c.GetString("role") != "admin" {
+		query = query.Where("username = ?", currentUsername(c))
+	}
+	if err := query.First(&order, c.Param("id")).Error; err != nil {
+		utils.HandleError(c, http.StatusNotFound, "Order not found")
+		return
+	}
+	c.JSON(http.StatusOK, order)
+}
+
+// listOrders отвечает страницей заказов из query с фильтрами и сортировкой из параметров запроса.
+func listOrders(c *gin.Context, query *gorm.DB) {
+	var filter models.OrderFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		utils.HandleError(c, http.StatusBadRequest, "Invalid filter")
+		return
+	}
+	var statuses []string
+	for _, value := range filter.Status {
+		for _, status := range strings.Split(value, ",") {
+			status = strings.TrimSpace(status)
+			if !services.IsOrderStatus(status) {
+				utils.HandleError(c, http.StatusBadRequest, "Invalid filter")
+				return
+			}
+			statuses = append(statuses, status)
+		}
+	}
+
+	pageInt, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limitInt, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if pageInt < 1 {
+		pageInt = 1
+	}
+	if limitInt < 1 || limitInt > 100 {
+		limitInt = 10
+	}
+
+	query = query.Model(&models.Order{})
+	if len(statuses) > 0 {
+		query = query.Where("status IN ?", statuses)
+	}
+	if !filter.From.IsZero() {
+		query = query.Where("created_at >= ?", filter.From)
+	}
+	if !filter.To.IsZero() {
+		query = query.Where("created_at < ?", filter.To.AddDate(0, 0, 1))
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		utils.HandleError(c, http.StatusInternalServerError, "Failed to load orders")
+		return
+	}
+
+	sort, order := filter.Sort, filter.Order
+	if sort == "" {
+		sort = "created_at"
+	}
+	if order == "" {
+		order = "desc"
+	}
+	orders := []models.Order{}
+	err := query.Preload("Products", func(tx *gorm.DB) *gorm.DB { return tx.Order("product_id asc") }).
+		Order(sort + " " + order + ", id " + order).
+		Limit(limitInt).
+		Offset((pageInt - 1) * limitInt).
+		Find(&orders).Error
+	if err != nil {
+		utils.HandleError(c, http.StatusInternalServerError, "Failed to load orders")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.OrderResponse{Data: orders, Total: total, Page: pageInt, Limit: limitInt})
+}
+
+// CancelOrder обрабатывает отмену заказа покупателем.
+// @Summary Отмена заказа
+// @Description Отменяет заказ текущего пользователя, пока он не оплачен. Книги возвращаются на склад, право на скачивание купленных по заказу электронных версий отзывается.
+// @Tags orders
+// @Accept json
+// @Produce json
+// @Param id path string true "Идентификатор заказа"
+// @Param request body models.CancelOrderRequest false "Причина отмены"
+// @Success 200 {object} models.Order
+// @Failure 404 {object} models.ErrorResponse "Order not found"
+// @Failure 409 {object} models.ErrorResponse "invalid order status transition: shipped -> cancelled"
+// @Router /orders/{id}/cancel [post]
+func CancelOrder(c *gin.Context) {
+	var req models.CancelOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		utils.HandleError(c, http.StatusBadRequest, "Invalid request")
+		return
+	}
+	transitionOrder(c, models.OrderCancelled, req.Note)
+}
+
+// UpdateOrderStatus обрабатывает смену статуса заказа сотрудником магазина.
+// @Summary Смена статуса заказа
+// @Description Переводит заказ в новый статус. Допустимые переходы: pending → paid или cancelled, paid → packed или refunded, packed → shipped или refunded, shipped → delivered, delivered → refunded. Время перехода и запись истории сохраняются. При оплате покупатель получает право скачивать электронные версии купленных книг. При отмене и возврате неотправленного заказа книги возвращаются на склад; право на скачивание электронных версий отзывается.
+// @Tags orders
+// @Accept json
+// @Produce json
+// @Param id path string true "Идентификатор заказа"
+// @Param request body models.OrderStatusRequest true "Новый статус"
+// @Success 200 {object} models.Order
+// @Failure 400 {object} models.ErrorResponse "Invalid request"
+// @Failure 404 {object} models.ErrorResponse "Order not found"
+// @Failure 409 {object} models.ErrorResponse "invalid order status transition: pending -> shipped"
+// @Router /orders/{id}/status [post]
+func UpdateOrderStatus(c *gin.Context) {
+	var req models.OrderStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil || !services.IsOrderStatus(req.Status) {
+		utils.HandleError(c, http.StatusBadRequest, "Invalid request")
+		return
+	}
+	transitionOrder(c, req.Status, req.Note)
+}
+
+// GetOrderHistory обрабатывает запрос на получение истории статусов заказа.
+// @Summary История статусов заказа
+// @Description Возвращает изменения статуса заказа от оформления до текущего статуса. Покупатель видит только свои заказы.
+// @Tags orders
+// @Accept json
+// @Produce json
+// @Param id path string true "Идентификатор заказа"
+// @Success 200 {array} models.OrderStatusChange
+// @Failure 404 {object} models.ErrorResponse "Order not found"
+// @Router /orders/{id}/history [get]
+func GetOrderHistory(c *gin.Context) {
+	order, ok := findOrder(c)
+	if !ok {
+		return
+	}
+	history, err := services.OrderHistory(order.ID)
+	if err != nil {
+		utils.HandleError(c, http.StatusInternalServerError, "Failed to load order history")
+		return
+	}
+	c.JSON(http.StatusOK, history)
+}
+
+// findOrder находит заказ из пути запроса. Чужие заказы доступны только администратору,
+// для остальных они не существуют. Возвращает false, если ответ с ошибкой уже отправлен.
+func findOrder(c *gin.Context) (*models.Order, bool) {
+	var order models.Order
+	query := services.Db
+	if c.GetString("role") != "admin" {
+		query = query.Where("username = ?", currentUsername(c))
+	}
+	if err := query.First(&order, c.Param("id")).Error; err != nil {
+		utils.HandleError(c, http.StatusNotFound, "Order not found")
+		return nil, false
+	}
+	return &order, true
+}
+
+// transitionOrder переводит заказ из пути запроса в статус to и отвечает обновленным заказом.
+func transitionOrder(c *gin.Context, to, note string) {
+	orderID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.HandleError(c, http.StatusNotFound, "Order not found")
+		return
+	}
+
+	order, err := services.TransitionOrder(orderID, to, currentUsername(c), c.GetString("role"), note)
+	switch {
+	case err == nil:
+		c.JSON(http.StatusOK, order)
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		utils.HandleError(c, http.StatusNotFound, "Order not found")
+	case errors.Is(err, services.ErrInvalidTransition):
+		utils.HandleError(c, http.StatusConflict, err.Error())
+	case errors.Is(err, services.ErrTransitionForbidden):
+		utils.HandleError(c, http.StatusForbidden, err.Error())
+	default:
+		utils.HandleError(c, http.StatusInternalServerError, "Failed to update order status")
+	}
+}
+
+// placeOrder оформляет заказ текущего пользователя в валюте клиента и отвечает на ошибки.
+// Возвращает false, если ответ с ошибкой уже отправлен.
+func placeOrder(c *gin.Context, products []models.ProductInOrder) (*models.Order, bool) {
+	currency, rates, ok := orderCurrency(c)
+	if !ok {
+		return nil, false
+	}
+	order, err := services.PlaceOrder(currentUsername(c), products, currency, rates)
+	if err != nil {
+		respondOrderError(c, err)
+		return nil, false
+	}
+	return order, true
+}
+
+// orderCurrency выбирает валюту заказа как при показе цен; по умолчанию — базовая валюта
+// магазина. Возвращает false, если ответ с ошибкой уже отправлен.
+func orderCurrency(c *gin.Context) (string, services.Rates, bool) {
+	rates, err := services.LoadRates(services.Db)
+	if err != nil {
+		utils.HandleError(c, http.StatusInternalServerError, "Failed to load currency rates")
+		return "", nil, false
+	}
+	currency, err := requestedCurrency(c, rates)
+	if err != nil {
+		utils.HandleError(c, http.StatusBadRequest, err.Error())
+		return "", nil, false
+	}
+	if currency == "" {
+		currency = services.DefaultCurrency
+	}
+	return currency, rates, true
+}
+
+// respondOrderError отвечает на ошибку оформления заказа.
+func respondOrderError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrInsufficientStock):
+		utils.HandleError(c, http.StatusConflict, err.Error())
+	case errors.Is(err, services.ErrEmptyOrder), errors.Is(err, services.ErrInvalidQuantity),
+		errors.Is(err, services.ErrNotForSale), errors.Is(err, services.ErrUnknownCurrency):
+		utils.HandleError(c, http.StatusBadRequest, err.Error())
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		message := "Book not found"
+		var lineErr *services.OrderLineError
+		if errors.As(err, &lineErr) {
+			message = fmt.Sprintf("book %d not found", lineErr.BookID)
+		}
+		utils.HandleError(c, http.StatusBadRequest, message)
+	default:
+		utils.HandleError(c, http.StatusInternalServerError, "Failed to place order")
+	}
+}
@@ -0,0 +1,161 @@
+package controllers
+
+import (
+	"Projectmugen/internal/models"
+	"Projectmugen/internal/services"
+	"Projectmugen/internal/utils"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// GetBookPrices обрабатывает запрос на получение истории цен книги.
+// @Summary История цен книги
+// @Description Возвращает записи истории цен книги, начиная с последних. Суммы указаны в минимальных единицах валюты. Запланированные, но еще не начавшиеся цены видны только администраторам.
+// @Tags prices
+// @Accept json
+// @Produce json
+// @Param id path string true "Идентификатор книги"
+// @Param page query int false "Номер страницы" default(1)
+// @Param limit query int false "Количество записей на странице" default(20)
+// @Success 200 {object} services.BookPrice "total": int64, "page": int, "limit": int
+// @Failure 404 {object} models.ErrorResponse "Book not found"
+// @Router /books/{id}/prices [get]
+func GetBookPrices(c *gin.Context) {
+	var book services.Book
+	if err := services.Db.First(&book, c.Param("id")).Error; err != nil {
+		utils.HandleError(c, http.StatusNotFound, "Book not found")
+		return
+	}
+
+	pageInt, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limitInt, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if pageInt < 1 {
+		pageInt = 1
+	}
+	if limitInt < 1 || limitInt > 100 {
+		limitInt = 20
+	}
+	offset := (pageInt - 1) * limitInt
+
+	var prices []services.BookPrice
+	var total int64
+	query := services.Db.Model(&services.BookPrice{}).Where("book_id = ?", book.ID)
+	if c.GetString("role") != "admin" {
+		query = query.Where("starts_at <= ?", time.Now())
+	}
+	query.Count(&total)
+	query.Order("starts_at desc, id desc").Limit(limitInt).Offset(offset).Find(&prices)
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":  prices,
+		"total": total,
+		"page":  pageInt,
+		"limit": limitInt,
+	})
+}
+
+// CreateBookPrice обрабатывает запрос на назначение цены книги.
+// @Summary Назначение цены книги
+// @Description Добавляет в историю базовую цену или распродажу. Цена с датой начала в будущем применяется автоматически в указанное время; распродажа действует до ends_at и на это время перекрывает базовую цену.
+// @Tags prices
+// @Accept json
+// @Produce json
+// @Param id path string true "Идентификатор книги"
+// @Param request body models.SetPriceRequest true "Новая цена"
+// @Success 201 {object} services.BookPrice
+// @Failure 400 {object} models.ErrorResponse "Invalid price"
+// @Failure 404 {object} models.ErrorResponse "Book not found"
+// @Router /books/{id}/prices [post]
+func CreateBookPrice(c *gin.Context) {
+	var book services.Book
+	if err := services.Db.First(&book, c.Param("id")).Error; err != nil {
+		utils.HandleError(c, http.StatusNotFound, "Book not found")
+		return
+	}
+
+	var req models.SetPriceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.HandleError(c, http.StatusBadRequest, "Invalid request")
+		return
+	}
+
+	now := time.Now()
+	price := services.BookPrice{
+		BookID:   book.ID,
+		Kind:     req.Kind,
+		Amount:   *req.Amount,
+		Currency: req.Currency,
+		StartsAt: now,
+		EndsAt:   req.EndsAt,
+		Username: currentUsername(c),
+	}
+	if price.Kind == "" {
+		price.Kind = services.PriceRegular
+	}
+	if req.StartsAt != nil {
+		// История цен не переписывается задним числом
+		if req.StartsAt.Before(now) {
+			utils.HandleError(c, http.StatusBadRequest, "starts_at must not be in the past")
+			return
+		}
+		price.StartsAt = *req.StartsAt
+	}
+	if err := services.ValidatePrice(&price); err != nil {
+		utils.HandleError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err := services.Db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&price).Error; err != nil {
+			return err
+		}
+		_, err := services.ApplyBookPrices(tx, now, book.ID)
+		return err
+	})
+	if err != nil {
+		utils.HandleError(c, http.StatusInternalServerError, "Failed to save price")
+		return
+	}
+	c.JSON(http.StatusCreated, price)
+}
+
+// DeleteBookPrice обрабатывает запрос на отмену запланированной цены.
+// @Summary Отмена запланированной цены
+// @Description Удаляет цену или распродажу, которая еще не начала действовать. Вступившие в силу записи являются историей и не удаляются.
+// @Tags prices
+// @Accept json
+// @Produce json
+// @Param id path string true "Идентификатор книги"
+// @Param priceId path string true "Идентификатор записи цены"
+// @Success 200 {object} models.MessageResponse "Price deleted"
+// @Failure 404 {object} models.ErrorResponse "Price not found"
+// @Failure 409 {object} models.ErrorResponse "only scheduled prices can be removed"
+// @Router /books/{id}/prices/{priceId} [delete]
+func DeleteBookPrice(c *gin.Context) {
+	var price services.BookPrice
+	err := services.Db.Where("id = ? AND book_id = ?", c.Param("priceId"), c.Param("id")).First(&price).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		utils.HandleError(c, http.StatusNotFound, "Price not found")
+		return
+	}
+	if err != nil {
+		utils.HandleError(c, http.StatusInternalServerError, "Failed to delete price")
+		return
+	}
+
+	result := services.Db.Where("id = ? AND starts_at > ?", price.ID, time.Now()).Delete(&services.BookPrice{})
+	if result.Error != nil {
+		utils.HandleError(c, http.StatusInternalServerError, "Failed to delete price")
+		return
+	}
+	if result.RowsAffected == 0 {
+		utils.HandleError(c, http.StatusConflict, services.ErrPriceImmutable.Error())
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Price deleted"})
+}
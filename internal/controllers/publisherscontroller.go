@@ -0,0 +1,172 @@
+package controllers
+
+import (
+	"Projectmugen/internal/services"
+	"Projectmugen/internal/utils"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// GetPublishers обрабатывает запрос на получение списка издателей.
+// @Summary Получение списка издателей
+// @Description Возвращает список издателей с пагинацией и фильтром по названию.
+// @Tags publishers
+// @Accept json
+// @Produce json
+// @Param page query int false "Номер страницы" default(1)
+// @Param limit query int false "Количество издателей на странице" default(10)
+// @Param name query string false "Фильтр по названию"
+// @Success 200 {object} services.Publisher "total": int64, "page": int, "limit": int
+// @Router /publishers [get]
+func GetPublishers(c *gin.Context) {
+	var publishers []services.Publisher
+	var total int64
+
+	pageInt, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limitInt, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	offset := (pageInt - 1) * limitInt
+
+	query := services.Db.Model(&services.Publisher{})
+	if name := c.Query("name"); name != "" {
+		query = query.Where("name ILIKE ?", "%"+name+"%")
+	}
+
+	query.Count(&total)
+	query.Order("name asc").Limit(limitInt).Offset(offset).Find(&publishers)
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":  publishers,
+		"total": total,
+		"page":  pageInt,
+		"limit": limitInt,
+	})
+}
+
+// GetPublisherByID обрабатывает запрос на получение издателя вместе с его книгами.
+// @Summary Получение издателя по ID
+// @Description Возвращает издателя с указанным идентификатором и список его книг.
+// @Tags publishers
+// @Accept json
+// @Produce json
+// @Param id path string true "Идентификатор издателя"
+// @Success 200 {object} services.Publisher
+// @Failure 404 {object} models.ErrorResponse "Publisher not found"
+// @Router /publishers/{id} [get]
+func GetPublisherByID(c *gin.Context) {
+	id := c.Param("id")
+	var publisher services.Publisher
+
+	err := services.Db.Preload("Books", func(db *gorm.DB) *gorm.DB {
+		return db.Order("title asc")
+	}).First(&publisher, id).Error
+	if err != nil {
+		utils.HandleError(c, http.StatusNotFound, "Publisher not found")
+		return
+	}
+	c.JSON(http.StatusOK, publisher)
+}
+
+// CreatePublisher обрабатывает запрос на создание издателя.
+// @Summary Создание издателя
+// @Description Создает нового издателя. Название должно быть уникальным.
+// @Tags publishers
+// @Accept json
+// @Produce json
+// @Param publisher body services.Publisher true "Данные издателя"
+// @Success 201 {object} services.Publisher
+// @Failure 400 {object} models.ErrorResponse "Invalid request"
+// @Failure 409 {object} models.ErrorResponse "Publisher already exists"
+// @Router /publishers [post]
+func CreatePublisher(c *gin.Context) {
+	var publisher services.Publisher
+	if err := c.BindJSON(&publisher); err != nil || publisher.Name == "" {
+		utils.HandleError(c, http.StatusBadRequest, "Invalid request")
+		return
+	}
+	publisher.ID = 0
+	publisher.Books = nil
+
+	if err := services.Db.Create(&publisher).Error; err != nil {
+		handlePublisherWriteError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, publisher)
+}
+
+// UpdatePublisher обрабатывает запрос на обновление издателя.
+// @Summary Обновление издателя по ID
+// @Description Обновляет данные издателя с указанным идентификатором.
+// @Tags publishers
+// @Accept json
+// @Produce json
+// @Param id path string true "Идентификатор издателя"
+// @Param publisher body services.Publisher true "Обновленные данные издателя"
+// @Success 200 {object} services.Publisher
+// @Failure 400 {object} models.ErrorResponse "Invalid request"
+// @Failure 404 {object} models.ErrorResponse "Publisher not found"
+// @Failure 409 {object} models.ErrorResponse "Publisher already exists"
+// @Router /publishers/{id} [put]
+func UpdatePublisher(c *gin.Context) {
+	var publisher services.Publisher
+	if err := services.Db.First(&publisher, c.Param("id")).Error; err != nil {
+		utils.HandleError(c, http.StatusNotFound, "Publisher not found")
+		return
+	}
+
+	var input services.Publisher
+	if err := c.BindJSON(&input); err != nil || input.Name == "" {
+		utils.HandleError(c, http.StatusBadRequest, "Invalid request")
+		return
+	}
+
+	publisher.Name = input.Name
+	publisher.Country = input.Country
+	publisher.Website = input.Website
+	if err := services.Db.Save(&publisher).Error; err != nil {
+		handlePublisherWriteError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, publisher)
+}
+
+// DeletePublisher обрабатывает запрос на удаление издателя.
+// @Summary Удаление издателя по ID
+// @Description Удаляет издателя; у его книг ссылка на издателя обнуляется, версии книг увеличиваются, а изменения попадают в историю.
+// @Tags publishers
+// @Accept json
+// @Produce json
+// @Param id path string true "Идентификатор издателя"
+// @Success 200 {object} models.MessageResponse "Publisher deleted"
+// @Failure 404 {object} models.ErrorResponse "Publisher not found"
+// @Router /publishers/{id} [delete]
+func DeletePublisher(c *gin.Context) {
+	var publisher services.Publisher
+	if err := services.Db.First(&publisher, c.Param("id")).Error; err != nil {
+		utils.HandleError(c, http.StatusNotFound, "Publisher not found")
+		return
+	}
+
+	err := services.Db.Transaction(func(tx *gorm.DB) error {
+		if err := services.DetachBooks(tx, "publisher_id", publisher.ID, currentUsername(c)); err != nil {
+			return err
+		}
+		return tx.Delete(&publisher).Error
+	})
+	if err != nil {
+		utils.HandleError(c, http.StatusInternalServerError, "Failed to delete publisher")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Publisher deleted"})
+}
+
+func handlePublisherWriteError(c *gin.Context, err error) {
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		utils.HandleError(c, http.StatusConflict, "Publisher already exists")
+		return
+	}
+	utils.HandleError(c, http.StatusInternalServerError, "Failed to save publisher")
+}
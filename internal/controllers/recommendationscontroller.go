@@ -0,0 +1,55 @@
+package controllers
+
+import (
+	"Projectmugen/internal/services"
+	"Projectmugen/internal/utils"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetAlsoBought обрабатывает запрос на получение рекомендаций «с этой книгой покупают».
+// @Summary С этой книгой покупают
+// @Description Возвращает книги, которые чаще всего покупают вместе с указанной (по предвычисленной статистике заказов). Если данных мало, список дополняется книгами того же автора и той же рубрики; причина указана в поле reason.
+// @Tags recommendations
+// @Accept json
+// @Produce json
+// @Param id path string true "Идентификатор книги"
+// @Param limit query int false "Количество рекомендаций" default(10)
+// @Param currency query string false "Валюта отображения цен (ISO 4217); приоритетнее Accept-Currency"
+// @Param Accept-Currency header string false "Предпочитаемые валюты отображения цен через запятую"
+// @Success 200 {array} services.Recommendation
+// @Failure 404 {object} models.ErrorResponse "Book not found"
+// @Router /books/{id}/also-bought [get]
+func GetAlsoBought(c *gin.Context) {
+	var book services.Book
+	if err := services.Db.First(&book, c.Param("id")).Error; err != nil {
+		utils.HandleError(c, http.StatusNotFound, "Book not found")
+		return
+	}
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if limit <= 0 || limit > 50 {
+		limit = 10
+	}
+
+	recommendations, err := services.AlsoBoughtFor(&book, limit)
+	if err != nil {
+		utils.HandleError(c, http.StatusInternalServerError, "Failed to load recommendations")
+		return
+	}
+	if !convertRecommendationPrices(c, recommendations) {
+		return
+	}
+	c.JSON(http.StatusOK, recommendations)
+}
+
+// convertRecommendationPrices пересчитывает цены рекомендованных книг; см. convertBookPrices.
+func convertRecommendationPrices(c *gin.Context, recommendations []services.Recommendation) bool {
+	books := make([]*services.Book, len(recommendations))
+	for i := range recommendations {
+		books[i] = &recommendations[i].Book
+	}
+	return convertBookPrices(c, books...)
+}
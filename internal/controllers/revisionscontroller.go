@@ -0,0 +1,107 @@
+package controllers
+
+import (
+	"Projectmugen/internal/services"
+	"Projectmugen/internal/utils"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// GetBookHistory обрабатывает запрос на получение истории изменений книги.
+// @Summary История изменений книги
+// @Description Возвращает ревизии книги (кто, когда и какие поля изменил), начиная с последней. Доступна и для книг в корзине.
+// @Tags books
+// @Accept json
+// @Produce json
+// @Param id path string true "Идентификатор книги"
+// @Param page query int false "Номер страницы" default(1)
+// @Param limit query int false "Количество ревизий на странице" default(20)
+// @Success 200 {object} services.BookRevision "total": int64, "page": int, "limit": int
+// @Failure 404 {object} models.ErrorResponse "Book not found"
+// @Router /books/{id}/history [get]
+func GetBookHistory(c *gin.Context) {
+	var book services.Book
+	if err := services.Db.Unscoped().First(&book, c.Param("id")).Error; err != nil {
+		utils.HandleError(c, http.StatusNotFound, "Book not found")
+		return
+	}
+
+	pageInt, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limitInt, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	offset := (pageInt - 1) * limitInt
+
+	var revisions []services.BookRevision
+	var total int64
+	query := services.Db.Model(&services.BookRevision{}).Where("book_id = ?", book.ID)
+	query.Count(&total)
+	query.Order("id desc").Limit(limitInt).Offset(offset).Find(&revisions)
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":  revisions,
+		"total": total,
+		"page":  pageInt,
+		"limit": limitInt,
+	})
+}
+
+// RollbackBook обрабатывает запрос на откат книги к указанной ревизии.
+// @Summary Откат книги к ревизии
+// @Description Возвращает поля книги к состоянию после указанной ревизии. Откат сам сохраняется как новая ревизия.
+// @Tags books
+// @Accept json
+// @Produce json
+// @Param id path string true "Идентификатор книги"
+// @Param revision path string true "Идентификатор ревизии"
+// @Success 200 {object} services.Book
+// @Failure 400 {object} models.ErrorResponse "Cannot roll back to a delete revision"
+// @Failure 404 {object} models.ErrorResponse "Revision not found"
+// @Failure 409 {object} models.ErrorResponse "Book with this ISBN already exists"
+// @Router /books/{id}/history/{revision}/rollback [post]
+func RollbackBook(c *gin.Context) {
+	var before services.Book
+	if err := services.Db.First(&before, c.Param("id")).Error; err != nil {
+		utils.HandleError(c, http.StatusNotFound, "Book not found")
+		return
+	}
+
+	var revision services.BookRevision
+	err := services.Db.Where("book_id = ?", before.ID).First(&revision, c.Param("revision")).Error
+	if err != nil {
+		utils.HandleError(c, http.StatusNotFound, "Revision not found")
+		return
+	}
+	if revision.Action == services.RevisionDelete {
+		utils.HandleError(c, http.StatusBadRequest, "Cannot roll back to a delete revision")
+		return
+	}
+
+	values, err := services.ApplyBookSnapshot(revision.Snapshot)
+	if err != nil {
+		utils.HandleError(c, http.StatusInternalServerError, "Invalid revision snapshot")
+		return
+	}
+
+	var after services.Book
+	err = services.Db.Transaction(func(tx *gorm.DB) error {
+		if err := services.BumpBookVersion(tx, before.ID, before.Version); err != nil {
+			return err
+		}
+		if err := tx.Model(&before).Omit(clause.Associations).Updates(values).Error; err != nil {
+			return err
+		}
+		if err := tx.First(&after, before.ID).Error; err != nil {
+			return err
+		}
+		return services.RecordBookRevision(tx, services.RevisionRollback, currentUsername(c), &before, &after)
+	})
+	if err != nil {
+		handleBookWriteError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, after)
+}
@@ -0,0 +1,49 @@
+package controllers
+
+import (
+	"Projectmugen/internal/services"
+	"Projectmugen/internal/utils"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+)
+
+// suggestMinLength — минимальная длина запроса, с которой выдаются подсказки.
+const suggestMinLength = 2
+
+// SuggestBooks обрабатывает запрос подсказок для строки поиска.
+// @Summary Подсказки поиска
+// @Description Возвращает подсказки для строки поиска: названия книг, авторов и рубрики. Сначала идут совпадения с началом названия или слова, затем похожие написания. Для запросов короче двух символов возвращается пустой список. Ответ кэшируется на короткое время.
+// @Tags books
+// @Accept json
+// @Produce json
+// @Param q query string true "Начало строки поиска"
+// @Param limit query int false "Количество подсказок (не больше 20)" default(8)
+// @Success 200 {array} services.Suggestion
+// @Failure 500 {object} models.ErrorResponse "Failed to load suggestions"
+// @Router /books/suggest [get]
+func SuggestBooks(c *gin.Context) {
+	q := strings.TrimSpace(c.Query("q"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "8"))
+	if limit <= 0 || limit > 20 {
+		limit = 8
+	}
+
+	c.Header("Cache-Control", fmt.Sprintf("private, max-age=%d", int(services.SuggestCacheTTL.Seconds())))
+	if utf8.RuneCountInString(q) < suggestMinLength {
+		c.JSON(http.StatusOK, []services.Suggestion{})
+		return
+	}
+
+	suggestions, err := services.Suggest(c.Request.Context(), q, limit)
+	if err != nil {
+		c.Header("Cache-Control", "no-store")
+		utils.HandleError(c, http.StatusInternalServerError, "Failed to load suggestions")
+		return
+	}
+	c.JSON(http.StatusOK, suggestions)
+}
@@ -0,0 +1,267 @@
+package controllers
+
+import (
+	"Projectmugen/internal/models"
+	"Projectmugen/internal/services"
+	"Projectmugen/internal/utils"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// filteredBookIDs разбирает фильтры каталога из строки запроса и возвращает подзапрос
+// с идентификаторами подходящих книг. Название и автор сравниваются без опечаток: статистика
+// должна быть точной. Возвращает false, если ответ с ошибкой уже отправлен.
+func filteredBookIDs(c *gin.Context) (*gorm.DB, bool) {
+	var filter models.BookFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		utils.HandleError(c, http.StatusBadRequest, "Invalid filter")
+		return nil, false
+	}
+	filter.Exact = true
+	return applyBookFilters(services.Db.Model(&services.Book{}), filter).Select("books.id"), true
+}
+
+// GetBooksByPeriodStats обрабатывает запрос на подсчет книг по годам или десятилетиям издания.
+// @Summary Книги по годам издания
+// @Description Возвращает количество книг по годам или десятилетиям издания. Принимает те же фильтры, что и список книг.
+// @Tags stats
+// @Accept json
+// @Produce json
+// @Param group query string false "Группировка: year — по годам, decade — по десятилетиям" Enums(year, decade) default(year)
+// @Param title query string false "Фильтр по заголовку книги"
+// @Param author query string false "Фильтр по автору"
+// @Param startYear query int false "Год издания от"
+// @Param endYear query int false "Год издания до"
+// @Param publisher_id query int false "Фильтр по издателю"
+// @Param category_id query int false "Фильтр по рубрике"
+// @Param work_id query int false "Фильтр по произведению"
+// @Param series_id query int false "Фильтр по серии"
+// @Param language query string false "Фильтр по языку текста книги"
+// @Param tags query []string false "Фильтр по тегам (через запятую или повтором параметра)" collectionFormat(multi)
+// @Param tag_mode query string false "all — книги со всеми тегами, any — с любым из них" Enums(all, any) default(all)
+// @Param available query bool false "Только книги в наличии (true) или отсутствующие (false)"
+// @Success 200 {array} models.PeriodBookCount
+// @Failure 400 {object} models.ErrorResponse "Invalid filter"
+// @Router /stats/books/by-year [get]
+func GetBooksByPeriodStats(c *gin.Context) {
+	ids, ok := filteredBookIDs(c)
+	if !ok {
+		return
+	}
+	period := "year"
+	switch c.DefaultQuery("group", "year") {
+	case "year":
+	case "decade":
+		period = "(year / 10) * 10"
+	default:
+		utils.HandleError(c, http.StatusBadRequest, "group must be year or decade")
+		return
+	}
+
+	result := []models.PeriodBookCount{}
+	err := services.Db.Model(&services.Book{}).
+		Select(period+" AS period, COUNT(*) AS count").
+		Where("id IN (?)", ids).
+		Group("period").
+		Order("period asc").
+		Scan(&result).Error
+	if err != nil {
+		utils.HandleError(c, http.StatusInternalServerError, "Failed to compute statistics")
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// GetBooksByPublisherStats обрабатывает запрос на подсчет книг по издателям.
+// @Summary Книги по издателям
+// @Description Возвращает количество книг каждого издателя, от крупных к мелким; книги без издателя идут с publisher_id = null. Принимает те же фильтры, что и список книг.
+// @Tags stats
+// @Accept json
+// @Produce json
+// @Param title query string false "Фильтр по заголовку книги"
+// @Param author query string false "Фильтр по автору"
+// @Param startYear query int false "Год издания от"
+// @Param endYear query int false "Год издания до"
+// @Param publisher_id query int false "Фильтр по издателю"
+// @Param category_id query int false "Фильтр по рубрике"
+// @Param work_id query int false "Фильтр по произведению"
+// @Param series_id query int false "Фильтр по серии"
+// @Param language query string false "Фильтр по языку текста книги"
+// @Param tags query []string false "Фильтр по тегам (через запятую или повтором параметра)" collectionFormat(multi)
+// @Param tag_mode query string false "all — книги со всеми тегами, any — с любым из них" Enums(all, any) default(all)
+// @Param available query bool false "Только книги в наличии (true) или отсутствующие (false)"
+// @Success 200 {array} models.PublisherBookCount
+// @Failure 400 {object} models.ErrorResponse "Invalid filter"
+// @Router /stats/books/by-publisher [get]
+func GetBooksByPublisherStats(c *gin.Context) {
+	ids, ok := filteredBookIDs(c)
+	if !ok {
+		return
+	}
+
+	result := []models.PublisherBookCount{}
+	err := services.Db.Model(&services.Book{}).
+		Select("books.publisher_id, COALESCE(publishers.name, '') AS name, COUNT(*) AS count").
+		Joins("LEFT JOIN publishers ON publishers.id = books.publisher_id").
+		Where("books.id IN (?)", ids).
+		Group("books.publisher_id, publishers.name").
+		Order("count desc, name asc").
+		Scan(&result).Error
+	if err != nil {
+		utils.HandleError(c, http.StatusInternalServerError, "Failed to compute statistics")
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// GetBooksByCategoryStats обрабатывает запрос на подсчет книг по рубрикам.
+// @Summary Книги по рубрикам
+// @Description Возвращает количество книг в каждой рубрике, от крупных к мелким; книги без рубрики идут с category_id = null. Принимает те же фильтры, что и список книг.
+// @Tags stats
+// @Accept json
+// @Produce json
+// @Param title query string false "Фильтр по заголовку книги"
+// @Param author query string false "Фильтр по автору"
+// @Param startYear query int false "Год издания от"
+// @Param endYear query int false "Год издания до"
+// @Param publisher_id query int false "Фильтр по издателю"
+// @Param category_id query int false "Фильтр по рубрике"
+// @Param work_id query int false "Фильтр по произведению"
+// @Param series_id query int false "Фильтр по серии"
+// @Param language query string false "Фильтр по языку текста книги"
+// @Param tags query []string false "Фильтр по тегам (через запятую или повтором параметра)" collectionFormat(multi)
+// @Param tag_mode query string false "all — книги со всеми тегами, any — с любым из них" Enums(all, any) default(all)
+// @Param available query bool false "Только книги в наличии (true) или отсутствующие (false)"
+// @Success 200 {array} models.CategoryBookCount
+// @Failure 400 {object} models.ErrorResponse "Invalid filter"
+// @Router /stats/books/by-category [get]
+func GetBooksByCategoryStats(c *gin.Context) {
+	ids, ok := filteredBookIDs(c)
+	if !ok {
+		return
+	}
+
+	result := []models.CategoryBookCount{}
+	err := services.Db.Model(&services.Book{}).
+		Select("books.category_id, COALESCE(categories.name, '') AS name, COUNT(*) AS count").
+		Joins("LEFT JOIN categories ON categories.id = books.category_id").
+		Where("books.id IN (?)", ids).
+		Group("books.category_id, categories.name").
+		Order("count desc, name asc").
+		Scan(&result).Error
+	if err != nil {
+		utils.HandleError(c, http.StatusInternalServerError, "Failed to compute statistics")
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// GetAuthorRatingStats обрабатывает запрос на получение среднего рейтинга авторов.
+// @Summary Средний рейтинг авторов
+// @Description Возвращает средний рейтинг отзывов на книги каждого автора, от лучших к худшим. Учитываются только книги, подходящие под фильтры списка книг.
+// @Tags stats
+// @Accept json
+// @Produce json
+// @Param min_reviews query int false "Минимальное число отзывов, чтобы автор попал в рейтинг" default(1)
+// @Param title query string false "Фильтр по заголовку книги"
+// @Param author query string false "Фильтр по автору"
+// @Param startYear query int false "Год издания от"
+// @Param endYear query int false "Год издания до"
+// @Param publisher_id query int false "Фильтр по издателю"
+// @Param category_id query int false "Фильтр по рубрике"
+// @Param work_id query int false "Фильтр по произведению"
+// @Param series_id query int false "Фильтр по серии"
+// @Param language query string false "Фильтр по языку текста книги"
+// @Param tags query []string false "Фильтр по тегам (через запятую или повтором параметра)" collectionFormat(multi)
+// @Param tag_mode query string false "all — книги со всеми тегами, any — с любым из них" Enums(all, any) default(all)
+// @Param available query bool false "Только книги в наличии (true) или отсутствующие (false)"
+// @Success 200 {array} models.AuthorRating
+// @Failure 400 {object} models.ErrorResponse "Invalid filter"
+// @Router /stats/authors/ratings [get]
+func GetAuthorRatingStats(c *gin.Context) {
+	ids, ok := filteredBookIDs(c)
+	if !ok {
+		return
+	}
+	minReviews, _ := strconv.Atoi(c.DefaultQuery("min_reviews", "1"))
+
+	result := []models.AuthorRating{}
+	err := services.Db.Model(&models.Review{}).
+		Select(`authors.id AS author_id, authors.name AS name,
+			ROUND(AVG(reviews.rating)::numeric, 2) AS average_rating,
+			COUNT(reviews.id) AS reviews, COUNT(DISTINCT reviews.product_id) AS books`).
+		Joins("JOIN book_authors ON book_authors.book_id = reviews.product_id AND book_authors.role = ?", services.RoleAuthor).
+		Joins("JOIN authors ON authors.id = book_authors.author_id").
+		Where("reviews.product_id IN (?)", ids).
+		Group("authors.id, authors.name").
+		Having("COUNT(reviews.id) >= ?", minReviews).
+		Order("average_rating desc, reviews desc, name asc").
+		Scan(&result).Error
+	if err != nil {
+		utils.HandleError(c, http.StatusInternalServerError, "Failed to compute statistics")
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// GetNewArrivalsStats обрабатывает запрос на подсчет новых поступлений по месяцам.
+// @Summary Новые поступления по месяцам
+// @Description Возвращает количество книг, добавленных в каталог, за каждый из последних months месяцев, включая текущий; месяцы без поступлений идут с нулем. Принимает те же фильтры, что и список книг.
+// @Tags stats
+// @Accept json
+// @Produce json
+// @Param months query int false "Количество месяцев (не больше 120)" default(12)
+// @Param title query string false "Фильтр по заголовку книги"
+// @Param author query string false "Фильтр по автору"
+// @Param startYear query int false "Год издания от"
+// @Param endYear query int false "Год издания до"
+// @Param publisher_id query int false "Фильтр по издателю"
+// @Param category_id query int false "Фильтр по рубрике"
+// @Param work_id query int false "Фильтр по произведению"
+// @Param series_id query int false "Фильтр по серии"
+// @Param language query string false "Фильтр по языку текста книги"
+// @Param tags query []string false "Фильтр по тегам (через запятую или повтором параметра)" collectionFormat(multi)
+// @Param tag_mode query string false "all — книги со всеми тегами, any — с любым из них" Enums(all, any) default(all)
+// @Param available query bool false "Только книги в наличии (true) или отсутствующие (false)"
+// @Success 200 {array} models.MonthBookCount
+// @Failure 400 {object} models.ErrorResponse "Invalid filter"
+// @Router /stats/books/new-arrivals [get]
+func GetNewArrivalsStats(c *gin.Context) {
+	ids, ok := filteredBookIDs(c)
+	if !ok {
+		return
+	}
+	months, _ := strconv.Atoi(c.DefaultQuery("months", "12"))
+	if months <= 0 || months > 120 {
+		months = 12
+	}
+
+	now := time.Now()
+	start := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location()).AddDate(0, -(months - 1), 0)
+	var rows []models.MonthBookCount
+	err := services.Db.Model(&services.Book{}).
+		Select("to_char(date_trunc('month', created_at), 'YYYY-MM') AS month, COUNT(*) AS count").
+		Where("id IN (?)", ids).
+		Where("created_at >= ?", start).
+		Group("month").
+		Scan(&rows).Error
+	if err != nil {
+		utils.HandleError(c, http.StatusInternalServerError, "Failed to compute statistics")
+		return
+	}
+	counts := make(map[string]int64, len(rows))
+	for _, row := range rows {
+		counts[row.Month] = row.Count
+	}
+
+	result := make([]models.MonthBookCount, months)
+	for i := range result {
+		month := start.AddDate(0, i, 0).Format("2006-01")
+		result[i] = models.MonthBookCount{Month: month, Count: counts[month]}
+	}
+	c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,101 @@
+package controllers
+
+import (
+	"Projectmugen/internal/models"
+	"Projectmugen/internal/services"
+	"Projectmugen/internal/utils"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// AdjustBookStock обрабатывает запрос на ручное изменение остатка книги.
+// @Summary Изменение остатка книги
+// @Description Изменяет количество экземпляров на складе и записывает движение в журнал. Поступление (receiving) только увеличивает остаток, списание брака (damage) только уменьшает, корректировка (correction) работает в обе стороны.
+// @Tags stock
+// @Accept json
+// @Produce json
+// @Param id path string true "Идентификатор книги"
+// @Param request body models.StockAdjustmentRequest true "Изменение остатка"
+// @Success 200 {object} services.StockMovement
+// @Failure 400 {object} models.ErrorResponse "Invalid request"
+// @Failure 404 {object} models.ErrorResponse "Book not found"
+// @Failure 409 {object} models.ErrorResponse "insufficient stock"
+// @Router /books/{id}/stock [post]
+func AdjustBookStock(c *gin.Context) {
+	var book services.Book
+	if err := services.Db.First(&book, c.Param("id")).Error; err != nil {
+		utils.HandleError(c, http.StatusNotFound, "Book not found")
+		return
+	}
+
+	var req models.StockAdjustmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.HandleError(c, http.StatusBadRequest, "Invalid request")
+		return
+	}
+	if !services.ValidManualAdjustment(req.Reason, req.Delta) {
+		utils.HandleError(c, http.StatusBadRequest, "Invalid reason or delta")
+		return
+	}
+
+	var movement *services.StockMovement
+	err := services.Db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		movement, err = services.AdjustStock(tx, book.ID, req.Delta, req.Reason, currentUsername(c), req.Note, nil)
+		return err
+	})
+	switch {
+	case errors.Is(err, services.ErrInsufficientStock):
+		utils.HandleError(c, http.StatusConflict, err.Error())
+		return
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		utils.HandleError(c, http.StatusNotFound, "Book not found")
+		return
+	case err != nil:
+		utils.HandleError(c, http.StatusInternalServerError, "Failed to adjust stock")
+		return
+	}
+
+	c.JSON(http.StatusOK, movement)
+}
+
+// GetStockMovements обрабатывает запрос на получение журнала движения остатков книги.
+// @Summary Журнал движения остатков
+// @Description Возвращает движения остатков книги, начиная с последних.
+// @Tags stock
+// @Accept json
+// @Produce json
+// @Param id path string true "Идентификатор книги"
+// @Param page query int false "Номер страницы" default(1)
+// @Param limit query int false "Количество записей на странице" default(20)
+// @Success 200 {object} services.StockMovement "total": int64, "page": int, "limit": int
+// @Failure 404 {object} models.ErrorResponse "Book not found"
+// @Router /books/{id}/stock/movements [get]
+func GetStockMovements(c *gin.Context) {
+	var book services.Book
+	if err := services.Db.Unscoped().First(&book, c.Param("id")).Error; err != nil {
+		utils.HandleError(c, http.StatusNotFound, "Book not found")
+		return
+	}
+
+	pageInt, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limitInt, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	offset := (pageInt - 1) * limitInt
+
+	var movements []services.StockMovement
+	var total int64
+	query := services.Db.Model(&services.StockMovement{}).Where("book_id = ?", book.ID)
+	query.Count(&total)
+	query.Order("id desc").Limit(limitInt).Offset(offset).Find(&movements)
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":  movements,
+		"total": total,
+		"page":  pageInt,
+		"limit": limitInt,
+	})
+}
@@ -0,0 +1,248 @@
+package controllers
+
+import (
+	"Projectmugen/internal/models"
+	"Projectmugen/internal/services"
+	"Projectmugen/internal/utils"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// GetTagCloud обрабатывает запрос на получение облака тегов.
+// @Summary Облако тегов
+// @Description Возвращает теги с числом книг каталога, от самых популярных.
+// @Tags tags
+// @Accept json
+// @Produce json
+// @Param limit query int false "Максимальное количество тегов (0 — без ограничения)" default(0)
+// @Param min_count query int false "Минимальное число книг с тегом" default(1)
+// @Success 200 {array} services.TagCount
+// @Router /tags [get]
+func GetTagCloud(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "0"))
+	minCount, _ := strconv.ParseInt(c.DefaultQuery("min_count", "1"), 10, 64)
+
+	cloud, err := services.TagCloud(limit, minCount)
+	if err != nil {
+		utils.HandleError(c, http.StatusInternalServerError, "Failed to load tags")
+		return
+	}
+	c.JSON(http.StatusOK, cloud)
+}
+
+// AddBookTags обрабатывает запрос на добавление тегов книге.
+// @Summary Добавление тегов книге
+// @Description Добавляет книге теги; несуществующие теги создаются. Теги приводятся к нижнему регистру, пробелы заменяются дефисами. Возвращает все теги книги.
+// @Tags tags
+// @Accept json
+// @Produce json
+// @Param id path string true "Идентификатор книги"
+// @Param request body models.BookTagsRequest true "Теги"
+// @Success 200 {array} services.Tag
+// @Failure 400 {object} models.ErrorResponse "Invalid request"
+// @Failure 404 {object} models.ErrorResponse "Book not found"
+// @Router /books/{id}/tags [post]
+func AddBookTags(c *gin.Context) {
+	var book services.Book
+	if err := services.Db.First(&book, c.Param("id")).Error; err != nil {
+		utils.HandleError(c, http.StatusNotFound, "Book not found")
+		return
+	}
+
+	var req models.BookTagsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.HandleError(c, http.StatusBadRequest, "Invalid request")
+		return
+	}
+	names := make([]string, 0, len(req.Tags))
+	for _, name := range req.Tags {
+		tag, err := services.NormalizeTag(name)
+		if err != nil {
+			utils.HandleError(c, http.StatusBadRequest, err.Error())
+			return
+		}
+		names = append(names, tag)
+	}
+
+	err := services.Db.Transaction(func(tx *gorm.DB) error {
+		if err := services.BumpBookVersion(tx, book.ID, 0); err != nil {
+			return err
+		}
+		return services.AttachTags(tx, book.ID, names)
+	})
+	if err != nil {
+		utils.HandleError(c, http.StatusInternalServerError, "Failed to save tags")
+		return
+	}
+	respondBookTags(c, &book)
+}
+
+// RemoveBookTag обрабатывает запрос на снятие тега с книги.
+// @Summary Снятие тега с книги
+// @Description Снимает тег с книги; сам тег остается. Возвращает оставшиеся теги книги.
+// @Tags tags
+// @Accept json
+// @Produce json
+// @Param id path string true "Идентификатор книги"
+// @Param tag path string true "Название тега"
+// @Success 200 {array} services.Tag
+// @Failure 404 {object} models.ErrorResponse "Book not found"
+// @Router /books/{id}/tags/{tag} [delete]
+func RemoveBookTag(c *gin.Context) {
+	var book services.Book
+	if err := services.Db.First(&book, c.Param("id")).Error; err != nil {
+		utils.HandleError(c, http.StatusNotFound, "Book not found")
+		return
+	}
+
+	name, err := services.NormalizeTag(c.Param("tag"))
+	if err == nil {
+		err = services.Db.Transaction(func(tx *gorm.DB) error {
+			result := tx.Where("book_id = ? AND tag_id IN (SELECT id FROM tags WHERE name = ?)", book.ID, name).
+				Delete(&services.BookTag{})
+			if result.Error != nil || result.RowsAffected == 0 {
+				return result.Error
+			}
+			return services.BumpBookVersion(tx, book.ID, 0)
+		})
+		if err != nil {
+			utils.HandleError(c, http.StatusInternalServerError, "Failed to remove tag")
+			return
+		}
+	}
+	respondBookTags(c, &book)
+}
+
+// RenameTag обрабатывает запрос на переименование тега.
+// @Summary Переименование тега
+// @Description Переименовывает тег у всех книг сразу. Если тег с новым названием уже есть, возвращается 409 — такие теги нужно объединить.
+// @Tags tags
+// @Accept json
+// @Produce json
+// @Param id path string true "Идентификатор тега"
+// @Param request body models.RenameTagRequest true "Новое название"
+// @Success 200 {object} services.Tag
+// @Failure 400 {object} models.ErrorResponse "Invalid request"
+// @Failure 404 {object} models.ErrorResponse "Tag not found"
+// @Failure 409 {object} models.ErrorResponse "Tag already exists, merge the tags instead"
+// @Router /tags/{id} [put]
+func RenameTag(c *gin.Context) {
+	var tag services.Tag
+	if err := services.Db.First(&tag, c.Param("id")).Error; err != nil {
+		utils.HandleError(c, http.StatusNotFound, "Tag not found")
+		return
+	}
+
+	var req models.RenameTagRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.HandleError(c, http.StatusBadRequest, "Invalid request")
+		return
+	}
+	name, err := services.NormalizeTag(req.Name)
+	if err != nil {
+		utils.HandleError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	tag.Name = name
+	err = services.Db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&tag).Error; err != nil {
+			return err
+		}
+		return services.BumpTaggedBooks(tx, tag.ID)
+	})
+	if err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			utils.HandleError(c, http.StatusConflict, "Tag already exists, merge the tags instead")
+			return
+		}
+		utils.HandleError(c, http.StatusInternalServerError, "Failed to save tag")
+		return
+	}
+	c.JSON(http.StatusOK, tag)
+}
+
+// MergeTag обрабатывает запрос на объединение тегов.
+// @Summary Объединение тегов
+// @Description Переносит все книги с тега на тег into_id и удаляет исходный тег.
+// @Tags tags
+// @Accept json
+// @Produce json
+// @Param id path string true "Идентификатор объединяемого тега"
+// @Param request body models.MergeTagsRequest true "Тег, в который вливается исходный"
+// @Success 200 {object} services.Tag
+// @Failure 400 {object} models.ErrorResponse "Invalid request"
+// @Failure 404 {object} models.ErrorResponse "Tag not found"
+// @Router /tags/{id}/merge [post]
+func MergeTag(c *gin.Context) {
+	var source services.Tag
+	if err := services.Db.First(&source, c.Param("id")).Error; err != nil {
+		utils.HandleError(c, http.StatusNotFound, "Tag not found")
+		return
+	}
+
+	var req models.MergeTagsRequest
+	if err := c.ShouldBindJSON(&req); err != nil || req.IntoID == source.ID {
+		utils.HandleError(c, http.StatusBadRequest, "Invalid request")
+		return
+	}
+	var target services.Tag
+	if err := services.Db.First(&target, req.IntoID).Error; err != nil {
+		utils.HandleError(c, http.StatusNotFound, "Tag not found")
+		return
+	}
+
+	if err := services.MergeTags(source.ID, target.ID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			utils.HandleError(c, http.StatusNotFound, "Tag not found")
+			return
+		}
+		utils.HandleError(c, http.StatusInternalServerError, "Failed to merge tags")
+		return
+	}
+	c.JSON(http.StatusOK, target)
+}
+
+// DeleteTag обрабатывает запрос на удаление тега.
+// @Summary Удаление тега
+// @Description Удаляет тег и снимает его со всех книг.
+// @Tags tags
+// @Accept json
+// @Produce json
+// @Param id path string true "Идентификатор тега"
+// @Success 200 {object} models.MessageResponse "Tag deleted"
+// @Failure 404 {object} models.ErrorResponse "Tag not found"
+// @Router /tags/{id} [delete]
+func DeleteTag(c *gin.Context) {
+	var tag services.Tag
+	if err := services.Db.First(&tag, c.Param("id")).Error; err != nil {
+		utils.HandleError(c, http.StatusNotFound, "Tag not found")
+		return
+	}
+
+	err := services.Db.Transaction(func(tx *gorm.DB) error {
+		if err := services.BumpTaggedBooks(tx, tag.ID); err != nil {
+			return err
+		}
+		return tx.Delete(&tag).Error
+	})
+	if err != nil {
+		utils.HandleError(c, http.StatusInternalServerError, "Failed to delete tag")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Tag deleted"})
+}
+
+// respondBookTags отвечает текущим списком тегов книги.
+func respondBookTags(c *gin.Context, book *services.Book) {
+	tags := []services.Tag{}
+	if err := services.Db.Model(book).Order("name asc").Association("Tags").Find(&tags); err != nil {
+		utils.HandleError(c, http.StatusInternalServerError, "Failed to load tags")
+		return
+	}
+	c.JSON(http.StatusOK, tags)
+}
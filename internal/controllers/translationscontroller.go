@@ -0,0 +1,156 @@
+package controllers
+
+import (
+	"Projectmugen/internal/models"
+	"Projectmugen/internal/services"
+	"Projectmugen/internal/utils"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// GetBookTranslations обрабатывает запрос на получение переводов книги.
+// @Summary Переводы книги
+// @Description Возвращает все переводы названия и описания книги.
+// @Tags translations
+// @Accept json
+// @Produce json
+// @Param id path string true "Идентификатор книги"
+// @Success 200 {array} services.BookTranslation
+// @Failure 404 {object} models.ErrorResponse "Book not found"
+// @Router /books/{id}/translations [get]
+func GetBookTranslations(c *gin.Context) {
+	var book services.Book
+	if err := services.Db.First(&book, c.Param("id")).Error; err != nil {
+		utils.HandleError(c, http.StatusNotFound, "Book not found")
+		return
+	}
+
+	translations := []services.BookTranslation{}
+	services.Db.Where("book_id = ?", book.ID).Order("locale asc").Find(&translations)
+	c.JSON(http.StatusOK, translations)
+}
+
+// SetBookTranslation обрабатывает запрос на добавление или замену перевода книги.
+// @Summary Перевод книги на язык
+// @Description Создает или заменяет перевод названия и описания книги на указанный язык. Версия книги увеличивается.
+// @Tags translations
+// @Accept json
+// @Produce json
+// @Param id path string true "Идентификатор книги"
+// @Param locale path string true "Язык перевода, тег BCP 47 (en, en-US)"
+// @Param translation body models.BookTranslationRequest true "Перевод"
+// @Success 200 {object} services.BookTranslation
+// @Failure 400 {object} models.ErrorResponse "Invalid request"
+// @Failure 404 {object} models.ErrorResponse "Book not found"
+// @Router /books/{id}/translations/{locale} [put]
+func SetBookTranslation(c *gin.Context) {
+	var book services.Book
+	if err := services.Db.First(&book, c.Param("id")).Error; err != nil {
+		utils.HandleError(c, http.StatusNotFound, "Book not found")
+		return
+	}
+
+	locale, err := services.NormalizeLanguage(c.Param("locale"))
+	if err != nil || locale == "" {
+		utils.HandleError(c, http.StatusBadRequest, services.ErrInvalidLanguage.Error())
+		return
+	}
+	var req models.BookTranslationRequest
+	if err := c.ShouldBindJSON(&req); err != nil || strings.TrimSpace(req.Title) == "" {
+		utils.HandleError(c, http.StatusBadRequest, "Invalid request")
+		return
+	}
+
+	translation := services.BookTranslation{
+		BookID:      book.ID,
+		Locale:      locale,
+		Title:       strings.TrimSpace(req.Title),
+		Description: req.Description,
+	}
+	err = services.Db.Transaction(func(tx *gorm.DB) error {
+		if err := services.BumpBookVersion(tx, book.ID, 0); err != nil {
+			return err
+		}
+		return tx.Save(&translation).Error
+	})
+	if err != nil {
+		utils.HandleError(c, http.StatusInternalServerError, "Failed to save translation")
+		return
+	}
+	c.JSON(http.StatusOK, translation)
+}
+
+// DeleteBookTranslation обрабатывает запрос на удаление перевода книги.
+// @Summary Удаление перевода книги
+// @Description Удаляет перевод книги на указанный язык. Версия книги увеличивается.
+// @Tags translations
+// @Accept json
+// @Produce json
+// @Param id path string true "Идентификатор книги"
+// @Param locale path string true "Язык перевода"
+// @Success 200 {object} models.MessageResponse "Translation deleted"
+// @Failure 404 {object} models.ErrorResponse "Book or translation not found"
+// @Router /books/{id}/translations/{locale} [delete]
+func DeleteBookTranslation(c *gin.Context) {
+	var book services.Book
+	if err := services.Db.First(&book, c.Param("id")).Error; err != nil {
+		utils.HandleError(c, http.StatusNotFound, "Book not found")
+		return
+	}
+	locale, err := services.NormalizeLanguage(c.Param("locale"))
+	if err != nil || locale == "" {
+		utils.HandleError(c, http.StatusNotFound, "Translation not found")
+		return
+	}
+
+	err = services.Db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("book_id = ? AND locale = ?", book.ID, locale).Delete(&services.BookTranslation{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return services.BumpBookVersion(tx, book.ID, 0)
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		utils.HandleError(c, http.StatusNotFound, "Translation not found")
+		return
+	}
+	if err != nil {
+		utils.HandleError(c, http.StatusInternalServerError, "Failed to delete translation")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Translation deleted"})
+}
+
+// localizeBooks подставляет в книги перевод на язык клиента: параметр lang приоритетнее
+// заголовка Accept-Language. Возвращает false, если ответ с ошибкой уже отправлен.
+func localizeBooks(c *gin.Context, books ...*services.Book) bool {
+	c.Writer.Header().Add("Vary", "Accept-Language")
+	header := c.GetHeader("Accept-Language")
+	if lang := c.Query("lang"); lang != "" {
+		header = lang
+	}
+	if err := services.LocalizeBooks(books, services.ParseLanguagePreferences(header)); err != nil {
+		utils.HandleError(c, http.StatusInternalServerError, "Failed to load translations")
+		return false
+	}
+	if len(books) == 1 && books[0].Locale != "" {
+		c.Header("Content-Language", books[0].Locale)
+	}
+	return true
+}
+
+// localizeBookList подставляет переводы в список книг; см. localizeBooks.
+func localizeBookList(c *gin.Context, books []services.Book) bool {
+	pointers := make([]*services.Book, len(books))
+	for i := range books {
+		pointers[i] = &books[i]
+	}
+	return localizeBooks(c, pointers...)
+}
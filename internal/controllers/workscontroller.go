@@ -0,0 +1,330 @@
+package controllers
+
+import (
+	"Projectmugen/internal/models"
+	"Projectmugen/internal/services"
+	"Projectmugen/internal/utils"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// GetWorks обрабатывает запрос на получение списка произведений.
+// @Summary Получение списка произведений
+// @Description Возвращает произведения с пагинацией; параметр title фильтрует по названию.
+// @Tags works
+// @Accept json
+// @Produce json
+// @Param page query int false "Номер страницы" default(1)
+// @Param limit query int false "Количество произведений на странице" default(10)
+// @Param title query string false "Фильтр по названию"
+// @Success 200 {object} services.Work "total": int64, "page": int, "limit": int
+// @Router /works [get]
+func GetWorks(c *gin.Context) {
+	var works []services.Work
+	var total int64
+
+	pageInt, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limitInt, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	offset := (pageInt - 1) * limitInt
+
+	query := services.Db.Model(&services.Work{})
+	if title := c.Query("title"); title != "" {
+		query = query.Where("title ILIKE ?", "%"+title+"%")
+	}
+
+	query.Count(&total)
+	query.Order("title asc").Limit(limitInt).Offset(offset).Find(&works)
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":  works,
+		"total": total,
+		"page":  pageInt,
+		"limit": limitInt,
+	})
+}
+
+// CreateWork обрабатывает запрос на создание произведения.
+// @Summary Создание произведения
+// @Description Создает произведение, к которому затем привязываются издания через поле work_id книги.
+// @Tags works
+// @Accept json
+// @Produce json
+// @Param work body services.Work true "Данные произведения"
+// @Success 201 {object} services.Work
+// @Failure 400 {object} models.ErrorResponse "Invalid request"
+// @Router /works [post]
+func CreateWork(c *gin.Context) {
+	var work services.Work
+	if err := c.BindJSON(&work); err != nil || strings.TrimSpace(work.Title) == "" {
+		utils.HandleError(c, http.StatusBadRequest, "Invalid request")
+		return
+	}
+	work.ID = 0
+	work.Editions = nil
+
+	if err := services.Db.Create(&work).Error; err != nil {
+		utils.HandleError(c, http.StatusInternalServerError, "Failed to save work")
+		return
+	}
+	c.JSON(http.StatusCreated, work)
+}
+
+// UpdateWork обрабатывает запрос на обновление произведения.
+// @Summary Обновление произведения по ID
+// @Description Обновляет название, автора и описание произведения.
+// @Tags works
+// @Accept json
+// @Produce json
+// @Param id path string true "Идентификатор произведения"
+// @Param work body services.Work true "Обновленные данные произведения"
+// @Success 200 {object} services.Work
+// @Failure 400 {object} models.ErrorResponse "Invalid request"
+// @Failure 404 {object} models.ErrorResponse "Work not found"
+// @Router /works/{id} [put]
+func UpdateWork(c *gin.Context) {
+	var work services.Work
+	if err := services.Db.First(&work, c.Param("id")).Error; err != nil {
+		utils.HandleError(c, http.StatusNotFound, "Work not found")
+		return
+	}
+
+	var input services.Work
+	if err := c.BindJSON(&input); err != nil || strings.TrimSpace(input.Title) == "" {
+		utils.HandleError(c, http.StatusBadRequest, "Invalid request")
+		return
+	}
+
+	work.Title = input.Title
+	work.Author = input.Author
+	work.Description = input.Description
+	if err := services.Db.Save(&work).Error; err != nil {
+		utils.HandleError(c, http.StatusInternalServerError, "Failed to save work")
+		return
+	}
+	c.JSON(http.StatusOK, work)
+}
+
+// DeleteWork обрабатывает запрос на удаление произведения.
+// @Summary Удаление произведения по ID
+// @Description Удаляет произведение; его издания остаются в каталоге как отдельные книги, их версии увеличиваются, а изменения попадают в историю.
+// @Tags works
+// @Accept json
+// @Produce json
+// @Param id path string true "Идентификатор произведения"
+// @Success 200 {object} models.MessageResponse "Work deleted"
+// @Failure 404 {object} models.ErrorResponse "Work not found"
+// @Router /works/{id} [delete]
+func DeleteWork(c *gin.Context) {
+	var work services.Work
+	if err := services.Db.First(&work, c.Param("id")).Error; err != nil {
+		utils.HandleError(c, http.StatusNotFound, "Work not found")
+		return
+	}
+
+	err := services.Db.Transaction(func(tx *gorm.DB) error {
+		if err := services.DetachBooks(tx, "work_id", work.ID, currentUsername(c)); err != nil {
+			return err
+		}
+		return tx.Delete(&work).Error
+	})
+	if err != nil {
+		utils.HandleError(c, http.StatusInternalServerError, "Failed to delete work")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Work deleted"})
+}
+
+// GetWorkEditions обрабатывает запрос на получение изданий произведения.
+// @Summary Издания произведения
+// @Description Возвращает все издания произведения, от новых к старым.
+// @Tags works
+// @Accept json
+// @Produce json
+// @Param id path string true "Идентификатор произведения"
+// @Param currency query string false "Валюта отображения цен (ISO 4217); приоритетнее Accept-Currency"
+// @Param Accept-Currency header string false "Предпочитаемые валюты отображения цен через запятую"
+// @Param lang query string false "Язык названия и описания (тег BCP 47); приоритетнее Accept-Language"
+// @Param Accept-Language header string false "Предпочитаемые языки; без подходящего перевода отдается оригинал"
+// @Success 200 {array} services.Book
+// @Failure 404 {object} models.ErrorResponse "Work not found"
+// @Router /works/{id}/editions [get]
+func GetWorkEditions(c *gin.Context) {
+	var work services.Work
+	if err := services.Db.First(&work, c.Param("id")).Error; err != nil {
+		utils.HandleError(c, http.StatusNotFound, "Work not found")
+		return
+	}
+
+	books := []services.Book{}
+	services.Db.Preload("Publisher").Preload("Cover").
+		Where("work_id = ?", work.ID).
+		Order("year desc, id desc").
+		Find(&books)
+	if !localizeBookList(c, books) || !convertBookListPrices(c, books) {
+		return
+	}
+	c.JSON(http.StatusOK, books)
+}
+
+// GetSeries обрабатывает запрос на получение списка серий.
+// @Summary Получение списка серий
+// @Description Возвращает все серии в алфавитном порядке.
+// @Tags series
+// @Accept json
+// @Produce json
+// @Success 200 {array} services.Series
+// @Router /series [get]
+func GetSeries(c *gin.Context) {
+	series := []services.Series{}
+	services.Db.Order("name asc").Find(&series)
+	c.JSON(http.StatusOK, series)
+}
+
+// CreateSeries обрабатывает запрос на создание серии.
+// @Summary Создание серии
+// @Description Создает новую серию. Название должно быть уникальным.
+// @Tags series
+// @Accept json
+// @Produce json
+// @Param series body services.Series true "Данные серии"
+// @Success 201 {object} services.Series
+// @Failure 400 {object} models.ErrorResponse "Invalid request"
+// @Failure 409 {object} models.ErrorResponse "Series already exists"
+// @Router /series [post]
+func CreateSeries(c *gin.Context) {
+	var series services.Series
+	if err := c.BindJSON(&series); err != nil || strings.TrimSpace(series.Name) == "" {
+		utils.HandleError(c, http.StatusBadRequest, "Invalid request")
+		return
+	}
+	series.ID = 0
+	series.Books = nil
+
+	if err := services.Db.Create(&series).Error; err != nil {
+		handleSeriesWriteError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, series)
+}
+
+// UpdateSeries обрабатывает запрос на обновление серии.
+// @Summary Обновление серии по ID
+// @Description Обновляет название и описание серии.
+// @Tags series
+// @Accept json
+// @Produce json
+// @Param id path string true "Идентификатор серии"
+// @Param series body services.Series true "Обновленные данные серии"
+// @Success 200 {object} services.Series
+// @Failure 400 {object} models.ErrorResponse "Invalid request"
+// @Failure 404 {object} models.ErrorResponse "Series not found"
+// @Failure 409 {object} models.ErrorResponse "Series already exists"
+// @Router /series/{id} [put]
+func UpdateSeries(c *gin.Context) {
+	var series services.Series
+	if err := services.Db.First(&series, c.Param("id")).Error; err != nil {
+		utils.HandleError(c, http.StatusNotFound, "Series not found")
+		return
+	}
+
+	var input services.Series
+	if err := c.BindJSON(&input); err != nil || strings.TrimSpace(input.Name) == "" {
+		utils.HandleError(c, http.StatusBadRequest, "Invalid request")
+		return
+	}
+
+	series.Name = input.Name
+	series.Description = input.Description
+	if err := services.Db.Save(&series).Error; err != nil {
+		handleSeriesWriteError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, series)
+}
+
+// DeleteSeries обрабатывает запрос на удаление серии.
+// @Summary Удаление серии по ID
+// @Description Удаляет серию; книги из нее остаются в каталоге без серии, их версии увеличиваются, а изменения попадают в историю.
+// @Tags series
+// @Accept json
+// @Produce json
+// @Param id path string true "Идентификатор серии"
+// @Success 200 {object} models.MessageResponse "Series deleted"
+// @Failure 404 {object} models.ErrorResponse "Series not found"
+// @Router /series/{id} [delete]
+func DeleteSeries(c *gin.Context) {
+	var series services.Series
+	if err := services.Db.First(&series, c.Param("id")).Error; err != nil {
+		utils.HandleError(c, http.StatusNotFound, "Series not found")
+		return
+	}
+
+	err := services.Db.Transaction(func(tx *gorm.DB) error {
+		if err := services.DetachBooks(tx, "series_id", series.ID, currentUsername(c)); err != nil {
+			return err
+		}
+		return tx.Delete(&series).Error
+	})
+	if err != nil {
+		utils.HandleError(c, http.StatusInternalServerError, "Failed to delete series")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Series deleted"})
+}
+
+// GetSeriesBooks обрабатывает запрос на получение книг серии.
+// @Summary Книги серии
+// @Description Возвращает книги серии по порядку томов; книги без номера тома идут в конце.
+// @Description С collapse_editions=true из изданий одного произведения остается одно.
+// @Tags series
+// @Accept json
+// @Produce json
+// @Param id path string true "Идентификатор серии"
+// @Param collapse_editions query bool false "Показывать по одному изданию каждого произведения"
+// @Param currency query string false "Валюта отображения цен (ISO 4217); приоритетнее Accept-Currency"
+// @Param Accept-Currency header string false "Предпочитаемые валюты отображения цен через запятую"
+// @Param lang query string false "Язык названия и описания (тег BCP 47); приоритетнее Accept-Language"
+// @Param Accept-Language header string false "Предпочитаемые языки; без подходящего перевода отдается оригинал"
+// @Success 200 {array} services.Book
+// @Failure 404 {object} models.ErrorResponse "Series not found"
+// @Router /series/{id}/books [get]
+func GetSeriesBooks(c *gin.Context) {
+	var series services.Series
+	if err := services.Db.First(&series, c.Param("id")).Error; err != nil {
+		utils.HandleError(c, http.StatusNotFound, "Series not found")
+		return
+	}
+
+	filter := models.BookFilter{SeriesID: &series.ID}
+	query := applyBookFilters(services.Db.Model(&services.Book{}), filter)
+	collapse := c.Query("collapse_editions") == "true"
+	if collapse {
+		query = collapseEditions(query, filter)
+	}
+
+	books := []services.Book{}
+	query.Preload("Cover").Order("series_volume asc nulls last, year asc, id asc").Find(&books)
+	if collapse {
+		if err := fillEditionCounts(books); err != nil {
+			utils.HandleError(c, http.StatusInternalServerError, "Failed to load books")
+			return
+		}
+	}
+	if !localizeBookList(c, books) || !convertBookListPrices(c, books) {
+		return
+	}
+	c.JSON(http.StatusOK, books)
+}
+
+func handleSeriesWriteError(c *gin.Context, err error) {
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		utils.HandleError(c, http.StatusConflict, "Series already exists")
+		return
+	}
+	utils.HandleError(c, http.StatusInternalServerError, "Failed to save series")
+}
@@ -0,0 +1,68 @@
+package export
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Форматы выгрузки каталога.
+const (
+	FormatCSV    = "csv"
+	FormatNDJSON = "ndjson"
+	FormatXLSX   = "xlsx"
+)
+
+// Writer построчно записывает выгрузку в поток, не накапливая данные в памяти.
+type Writer interface {
+	// WriteHeader записывает заголовок с именами колонок. Вызывается один раз до строк.
+	WriteHeader(columns []string) error
+	// WriteRow записывает одну строку; порядок значений совпадает с порядком колонок.
+	WriteRow(values []interface{}) error
+	// Close дописывает хвост файла и сбрасывает буферы. Базовый io.Writer не закрывается.
+	Close() error
+}
+
+// NewWriter создает Writer для указанного формата.
+func NewWriter(format string, w io.Writer) (Writer, error) {
+	switch strings.ToLower(format) {
+	case FormatCSV:
+		return newCSVWriter(w), nil
+	case FormatNDJSON:
+		return newNDJSONWriter(w), nil
+	case FormatXLSX:
+		return newXLSXWriter(w), nil
+	}
+	return nil, fmt.Errorf("unsupported export format %q", format)
+}
+
+// ContentType возвращает MIME-тип для формата выгрузки.
+func ContentType(format string) string {
+	switch strings.ToLower(format) {
+	case FormatCSV:
+		return "text/csv; charset=utf-8"
+	case FormatNDJSON:
+		return "application/x-ndjson"
+	case FormatXLSX:
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+	return "application/octet-stream"
+}
+
+func toString(v interface{}) string {
+	if s, ok := v.(string); ok {
+		return s
+	}
+	return fmt.Sprint(v)
+}
+
+func formatNumber(v interface{}) string {
+	switch n := v.(type) {
+	case float32:
+		return strconv.FormatFloat(float64(n), 'f', -1, 32)
+	case float64:
+		return strconv.FormatFloat(n, 'f', -1, 64)
+	}
+	return fmt.Sprint(v)
+}
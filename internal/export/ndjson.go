@@ -0,0 +1,52 @@
+package export
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+)
+
+type ndjsonWriter struct {
+	w       io.Writer
+	columns []string
+}
+
+func newNDJSONWriter(w io.Writer) *ndjsonWriter {
+	return &ndjsonWriter{w: w}
+}
+
+// WriteHeader запоминает имена колонок: в NDJSON они становятся ключами объектов.
+func (nw *ndjsonWriter) WriteHeader(columns []string) error {
+	nw.columns = columns
+	return nil
+}
+
+func (nw *ndjsonWriter) WriteRow(values []interface{}) error {
+	// Собираем объект вручную, чтобы сохранить порядок выбранных колонок
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, column := range nw.columns {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, _ := json.Marshal(column)
+		buf.Write(key)
+		buf.WriteByte(':')
+		var value interface{}
+		if i < len(values) {
+			value = values[i]
+		}
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+		buf.Write(encoded)
+	}
+	buf.WriteString("}\n")
+	_, err := nw.w.Write(buf.Bytes())
+	return err
+}
+
+func (nw *ndjsonWriter) Close() error {
+	return nil
+}
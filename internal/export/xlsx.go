@@ -0,0 +1,150 @@
+package export
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/xml"
+	"io"
+	"strconv"
+)
+
+// Статические части книги XLSX с одним листом.
+const (
+	xlsxContentTypes = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+		`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+		`<Default Extension="xml" ContentType="application/xml"/>` +
+		`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+		`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+		`</Types>`
+	xlsxRootRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+		`</Relationships>`
+	xlsxWorkbook = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+		`<sheets><sheet name="Books" sheetId="1" r:id="rId1"/></sheets>` +
+		`</workbook>`
+	xlsxWorkbookRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>` +
+		`</Relationships>`
+	xlsxSheetHead = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`
+	xlsxSheetTail = `</sheetData></worksheet>`
+)
+
+// xlsxWriter пишет книгу XLSX потоково: служебные части записываются сразу,
+// а лист формируется построчно внутри zip-архива без промежуточного буфера.
+type xlsxWriter struct {
+	dst   io.Writer
+	zw    *zip.Writer
+	sheet *bufio.Writer
+	row   int
+	err   error
+}
+
+func newXLSXWriter(w io.Writer) *xlsxWriter {
+	return &xlsxWriter{dst: w, zw: zip.NewWriter(w)}
+}
+
+func (xw *xlsxWriter) open() error {
+	if xw.sheet != nil || xw.err != nil {
+		return xw.err
+	}
+	parts := []struct{ name, body string }{
+		{"[Content_Types].xml", xlsxContentTypes},
+		{"_rels/.rels", xlsxRootRels},
+		{"xl/workbook.xml", xlsxWorkbook},
+		{"xl/_rels/workbook.xml.rels", xlsxWorkbookRels},
+	}
+	for _, part := range parts {
+		f, err := xw.zw.Create(part.name)
+		if err != nil {
+			xw.err = err
+			return err
+		}
+		if _, err := io.WriteString(f, part.body); err != nil {
+			xw.err = err
+			return err
+		}
+	}
+	f, err := xw.zw.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		xw.err = err
+		return err
+	}
+	xw.sheet = bufio.NewWriter(f)
+	_, xw.err = xw.sheet.WriteString(xlsxSheetHead)
+	return xw.err
+}
+
+func (xw *xlsxWriter) WriteHeader(columns []string) error {
+	values := make([]interface{}, len(columns))
+	for i, column := range columns {
+		values[i] = column
+	}
+	return xw.WriteRow(values)
+}
+
+func (xw *xlsxWriter) WriteRow(values []interface{}) error {
+	if err := xw.open(); err != nil {
+		return err
+	}
+	xw.row++
+	rowRef := strconv.Itoa(xw.row)
+	xw.sheet.WriteString(`<row r="` + rowRef + `">`)
+	for i, value := range values {
+		ref := columnName(i) + rowRef
+		switch v := value.(type) {
+		case nil:
+			continue
+		case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+			xw.sheet.WriteString(`<c r="` + ref + `"><v>`)
+			xw.sheet.WriteString(formatNumber(v))
+			xw.sheet.WriteString(`</v></c>`)
+		default:
+			xw.sheet.WriteString(`<c r="` + ref + `" t="inlineStr"><is><t xml:space="preserve">`)
+			xml.EscapeText(xw.sheet, []byte(toString(v)))
+			xw.sheet.WriteString(`</t></is></c>`)
+		}
+	}
+	_, err := xw.sheet.WriteString(`</row>`)
+	if err != nil {
+		xw.err = err
+		return err
+	}
+	// Отдаем строку в архив, чтобы не держать лист в буфере целиком
+	if err := xw.sheet.Flush(); err != nil {
+		xw.err = err
+		return err
+	}
+	if f, ok := xw.dst.(interface{ Flush() }); ok && xw.row%100 == 0 {
+		xw.zw.Flush()
+		f.Flush()
+	}
+	return nil
+}
+
+func (xw *xlsxWriter) Close() error {
+	if err := xw.open(); err != nil {
+		return err
+	}
+	if _, err := xw.sheet.WriteString(xlsxSheetTail); err != nil {
+		return err
+	}
+	if err := xw.sheet.Flush(); err != nil {
+		return err
+	}
+	return xw.zw.Close()
+}
+
+// columnName переводит индекс колонки (с нуля) в буквенное обозначение: A, B, ..., Z, AA.
+func columnName(index int) string {
+	name := ""
+	for index >= 0 {
+		name = string(rune('A'+index%26)) + name
+		index = index/26 - 1
+	}
+	return name
+}
@@ -1,8 +1,40 @@
 package models
 
+import "time"
+
+// Статусы заказа. Допустимые переходы между ними задаются в services.TransitionOrder.
+const (
+	OrderPending   = "pending"   // оформлен, ожидает оплаты
+	OrderPaid      = "paid"      // оплачен
+	OrderPacked    = "packed"    // собран на складе
+	OrderShipped   = "shipped"   // передан в доставку
+	OrderDelivered = "delivered" // получен покупателем
+	OrderCancelled = "cancelled" // отменен до оплаты
+	OrderRefunded  = "refunded"  // деньги возвращены
+)
+
 type Order struct {
 	ID       int            `gorm:"primaryKey" json:"order_id"`
 	UserID   int            `json:"user_id"`
+	Username string         `gorm:"index" json:"username"` // Владелец заказа; пользователи идентифицируются по имени
 	Products []OrderProduct `gorm:"foreignKey:OrderID" json:"products"`
-	User     User           `json:"user" gorm:"foreignKey:UserID" swaggerignore:"true"`
+	// Currency и ExchangeRate фиксируют валюту заказа и курс пересчета из базовой валюты
+	// магазина на момент покупки, чтобы последующие изменения курсов не меняли сумму заказа
+	Currency     string  `json:"currency"`
+	ExchangeRate float64 `gorm:"type:numeric(20,10)" json:"exchange_rate"`
+	// Total — сумма заказа в минимальных единицах Currency; считается сервером по строкам заказа
+	Total     int64     `gorm:"not null;default:0" json:"total"`
+	CreatedAt time.Time `gorm:"index" json:"created_at"`
+	// Status меняется только через services.TransitionOrder; время перехода в каждый статус
+	// хранится в отдельном поле, вся последовательность изменений — в History
+	Status      string              `gorm:"size:16;not null;default:pending;index" json:"status"`
+	PaidAt      *time.Time          `json:"paid_at,omitempty"`
+	PackedAt    *time.Time          `json:"packed_at,omitempty"`
+	ShippedAt   *time.Time          `json:"shipped_at,omitempty"`
+	DeliveredAt *time.Time          `json:"delivered_at,omitempty"`
+	CancelledAt *time.Time          `json:"cancelled_at,omitempty"`
+	RefundedAt  *time.Time          `json:"refunded_at,omitempty"`
+	History     []OrderStatusChange `gorm:"foreignKey:OrderID;constraint:OnDelete:CASCADE" json:"history,omitempty"`
+	// Пользователи хранятся вне базы, поэтому таблица users при миграции не создается
+	User User `json:"user" gorm:"foreignKey:UserID;-:migration" swaggerignore:"true"`
 }
@@ -1,8 +1,14 @@
 package models
 
+// OrderProduct — строка заказа. ProductID ссылается на книгу каталога; по строкам заказов
+// строятся рекомендации «с этой книгой покупают». Название и цена фиксируются на момент
+// заказа, чтобы последующие изменения каталога не меняли заказ.
 type OrderProduct struct {
-	OrderID   int `gorm:"primaryKey" json:"order_id"`
-	ProductID int `gorm:"primaryKey" json:"product_id"`
-	Quantity  int `json:"quantity"`
-	Product   Product `gorm:"foreignKey:ProductID" json:"product"`
+	OrderID   int     `gorm:"primaryKey" json:"order_id"`
+	ProductID int     `gorm:"primaryKey;index" json:"product_id"`
+	Title     string  `json:"title"`
+	Quantity  int     `json:"quantity"`
+	UnitPrice int64   `gorm:"not null;default:0" json:"unit_price"` // Цена за экземпляр в валюте заказа
+	LineTotal int64   `gorm:"not null;default:0" json:"line_total"`
+	Product   Product `gorm:"foreignKey:ProductID;-:migration" json:"product"`
 }
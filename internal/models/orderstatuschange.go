@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// OrderStatusChange — запись истории статусов заказа: кто и когда перевел заказ из From в To.
+// У первой записи, созданной при оформлении заказа, From пустой.
+type OrderStatusChange struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	OrderID   int       `gorm:"index;not null" json:"order_id"`
+	From      string    `gorm:"column:from_status;size:16" json:"from,omitempty"`
+	To        string    `gorm:"column:to_status;size:16;not null" json:"to"`
+	Username  string    `json:"username"`
+	Note      string    `json:"note,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -5,7 +5,8 @@ type Product struct {
 	Name         string  `json:"name"`
 	Description  string  `json:"description"`
 	CategoryID   int     `json:"category_id"`
-	Price        float64 `json:"price"`
+	Price        int64   `json:"price"`    // Цена в минимальных единицах валюты (копейки, тиыны, центы)
+	Currency     string  `json:"currency"` // Код валюты ISO 4217
 	Manufacturer string  `json:"manufacturer"`
 	Rating       float64 `json:"rating" grom:"default:0.0"`
 }
@@ -1,5 +1,7 @@
 package models
 
+import "time"
+
 type CreateOrderRequest struct {
 	Products []ProductInOrder `json:"products,omitempty"` // Опциональный список продуктов
 }
@@ -25,3 +27,121 @@ type CreateReviewRequest struct {
 	ReviewText string `json:"review_text"`
 	Rating     int    `json:"rating"`
 }
+
+// BookFilter описывает фильтры каталога; принимается как из строки запроса, так и из тела.
+type BookFilter struct {
+	Title       string   `form:"title" json:"title,omitempty"`
+	Author      string   `form:"author" json:"author,omitempty"`
+	StartYear   *int     `form:"startYear" json:"start_year,omitempty"`
+	EndYear     *int     `form:"endYear" json:"end_year,omitempty"`
+	PublisherID *uint    `form:"publisher_id" json:"publisher_id,omitempty"`
+	CategoryID  *uint    `form:"category_id" json:"category_id,omitempty"`
+	WorkID      *uint    `form:"work_id" json:"work_id,omitempty"`
+	SeriesID    *uint    `form:"series_id" json:"series_id,omitempty"`
+	Language    string   `form:"language" json:"language,omitempty"`                                   // Язык текста; en подходит и для en-US, и для en-GB
+	Tags        []string `form:"tags" json:"tags,omitempty"`                                           // Теги; можно повторять параметр или перечислять через запятую
+	TagMode     string   `form:"tag_mode" json:"tag_mode,omitempty" binding:"omitempty,oneof=all any"` // all — все теги сразу (по умолчанию), any — любой из них
+	Available   *bool    `form:"available" json:"available,omitempty"`
+	Exact       bool     `form:"exact" json:"exact,omitempty"` // Отключает поиск с опечатками по названию и автору
+}
+
+// IsEmpty сообщает, что ни один фильтр не задан.
+func (f BookFilter) IsEmpty() bool {
+	return f.Title == "" && f.Author == "" && f.StartYear == nil && f.EndYear == nil && f.PublisherID == nil && f.CategoryID == nil && f.WorkID == nil && f.SeriesID == nil && f.Language == "" && len(f.Tags) == 0 && f.Available == nil
+}
+
+type ReassignPublisherRequest struct {
+	PublisherID uint       `json:"publisher_id" binding:"required"`
+	Filter      BookFilter `json:"filter"`
+}
+
+type BookAuthorInput struct {
+	AuthorID uint   `json:"author_id" binding:"required"`
+	Role     string `json:"role"` // author, translator, illustrator или editor; по умолчанию author
+}
+
+type SetBookAuthorsRequest struct {
+	Authors []BookAuthorInput `json:"authors"`
+}
+
+// BookInput — редактируемые поля книги. PUT заменяет ими книгу целиком,
+// PATCH применяет патч к текущим значениям этих полей.
+type BookInput struct {
+	Title       string `json:"title"`
+	Author      string `json:"author"`
+	Year        int    `json:"year"`
+	Description string `json:"description"`
+	Language    string `json:"language"` // Язык названия и описания, тег BCP 47 (ru, en-US)
+	PublisherID *uint  `json:"publisher_id"`
+	CategoryID  *uint  `json:"category_id"`
+	WorkID      *uint  `json:"work_id"`
+	SeriesID    *uint  `json:"series_id"`
+	// SeriesVolume — номер тома в серии; допустим только вместе с SeriesID
+	SeriesVolume *int    `json:"series_volume"`
+	ISBN13       *string `json:"isbn13"`
+	ISBN10       *string `json:"isbn10"`
+}
+
+type StockAdjustmentRequest struct {
+	Delta  int    `json:"delta" binding:"required"`  // Изменение остатка: положительное — приход, отрицательное — списание
+	Reason string `json:"reason" binding:"required"` // receiving, damage или correction
+	Note   string `json:"note"`
+}
+
+type SetPriceRequest struct {
+	Amount   *int64     `json:"amount" binding:"required"` // Цена в минимальных единицах валюты
+	Currency string     `json:"currency"`                  // Код валюты ISO 4217; по умолчанию PRICE_DEFAULT_CURRENCY
+	Kind     string     `json:"kind"`                      // regular или sale; по умолчанию regular
+	StartsAt *time.Time `json:"starts_at"`                 // Начало действия; по умолчанию сразу
+	EndsAt   *time.Time `json:"ends_at"`                   // Окончание действия; обязательно для распродажи
+}
+
+type SetCurrencyRateRequest struct {
+	Rate    float64 `json:"rate" binding:"required"` // Стоимость единицы валюты в базовой валюте магазина
+	RoundTo int64   `json:"round_to"`                // Шаг округления в минимальных единицах; по умолчанию 1
+}
+
+type BookTagsRequest struct {
+	Tags []string `json:"tags" binding:"required,min=1"`
+}
+
+type RenameTagRequest struct {
+	Name string `json:"name" binding:"required"`
+}
+
+type MergeTagsRequest struct {
+	IntoID uint `json:"into_id" binding:"required"` // Тег, в который вливается исходный
+}
+
+type BookTranslationRequest struct {
+	Title       string `json:"title" binding:"required"`
+	Description string `json:"description"`
+}
+
+type GrantEntitlementRequest struct {
+	Username  string     `json:"username" binding:"required"`
+	Source    string     `json:"source"`     // loan или grant; по умолчанию grant
+	ExpiresAt *time.Time `json:"expires_at"` // Окончание доступа; обязательно для loan
+}
+
+type AddCartItemRequest struct {
+	BookID   uint `json:"book_id" binding:"required"`
+	Quantity int  `json:"quantity"` // По умолчанию 1
+}
+
+type OrderStatusRequest struct {
+	Status string `json:"status" binding:"required"` // paid, packed, shipped, delivered, cancelled или refunded
+	Note   string `json:"note"`
+}
+
+type CancelOrderRequest struct {
+	Note string `json:"note"` // Причина отмены
+}
+
+type OrderFilter struct {
+	Status []string  `form:"status" json:"status,omitempty"`                                                  // Статусы; можно повторять параметр или перечислять через запятую
+	From   time.Time `form:"from" time_format:"2006-01-02" json:"from,omitempty"`                             // Заказы, оформленные начиная с этой даты
+	To     time.Time `form:"to" time_format:"2006-01-02" json:"to,omitempty"`                                 // Заказы, оформленные по эту дату включительно
+	Sort   string    `form:"sort" json:"sort,omitempty" binding:"omitempty,oneof=id created_at total status"` // По умолчанию created_at
+	Order  string    `form:"order" json:"order,omitempty" binding:"omitempty,oneof=asc desc"`                 // По умолчанию desc
+}
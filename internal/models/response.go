@@ -1,5 +1,7 @@
 package models
 
+import "time"
+
 type ProductResponse struct {
 	Data  []Product `json:"data"`
 	Total int64     `json:"total"`
@@ -31,3 +33,60 @@ type UserInfoResponse struct {
 	Name string `json:"name"`
 	Role string `json:"role"`
 }
+
+type ImportError struct {
+	Index   int    `json:"index"`   // Позиция записи во входном массиве
+	Message string `json:"message"` // Причина, по которой запись пропущена
+}
+
+type ImportBooksResponse struct {
+	Created int           `json:"created"`
+	Updated int           `json:"updated"`
+	Skipped int           `json:"skipped"`
+	Errors  []ImportError `json:"errors"`
+}
+
+type ReassignPublisherResponse struct {
+	Updated int64 `json:"updated"`
+}
+
+type AuthorBookCount struct {
+	AuthorID uint   `json:"author_id"`
+	Name     string `json:"name"`
+	Count    int    `json:"count"`
+}
+
+type PeriodBookCount struct {
+	Period int   `json:"period"` // Год или первый год десятилетия
+	Count  int64 `json:"count"`
+}
+
+type PublisherBookCount struct {
+	PublisherID *uint  `json:"publisher_id"` // nil — книги без издателя
+	Name        string `json:"name"`
+	Count       int64  `json:"count"`
+}
+
+type CategoryBookCount struct {
+	CategoryID *uint  `json:"category_id"` // nil — книги без рубрики
+	Name       string `json:"name"`
+	Count      int64  `json:"count"`
+}
+
+type AuthorRating struct {
+	AuthorID      uint    `json:"author_id"`
+	Name          string  `json:"name"`
+	AverageRating float64 `json:"average_rating"`
+	Reviews       int64   `json:"reviews"`
+	Books         int64   `json:"books"` // Книги автора, у которых есть отзывы
+}
+
+type MonthBookCount struct {
+	Month string `json:"month"` // Месяц в формате YYYY-MM
+	Count int64  `json:"count"`
+}
+
+type DownloadLinkResponse struct {
+	URL       string    `json:"url"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
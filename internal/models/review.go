@@ -1,11 +1,13 @@
 package models
 
 type Review struct {
-	ID         int     `gorm:"primaryKey" json:"id"`
-	ReviewText string  `json:"review_text"`
-	Rating     int     `json:"rating"`
-	UserID     int     `json:"user_id" gorm:"foreignKey:UserID"`
-	ProductID  int     `json:"product_id" gorm:"foreignKey:ProductID"`
-	Product    Product `json:"product" gorm:"foreignKey:ProductID" swaggerignore:"true"`
-	User       User    `json:"user" gorm:"foreignKey:UserID" swaggerignore:"true"`
+	ID         int    `gorm:"primaryKey" json:"id"`
+	ReviewText string `json:"review_text"`
+	Rating     int    `json:"rating"`
+	UserID     int    `json:"user_id" gorm:"foreignKey:UserID"`
+	// Username — автор отзыва; пользователи хранятся вне базы и идентифицируются по имени
+	Username  string  `gorm:"index" json:"username"`
+	ProductID int     `json:"product_id" gorm:"foreignKey:ProductID;index"`
+	Product   Product `json:"product" gorm:"foreignKey:ProductID;-:migration" swaggerignore:"true"`
+	User      User    `json:"user" gorm:"foreignKey:UserID;-:migration" swaggerignore:"true"`
 }
@@ -0,0 +1,284 @@
+package patch
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// assertJSONEqual сравнивает документы по значению, а не по байтам: порядок ключей
+// объекта после json.Marshal не совпадает с исходным.
+func assertJSONEqual(t *testing.T, got []byte, want string) {
+	t.Helper()
+	var gotValue, wantValue interface{}
+	if err := json.Unmarshal(got, &gotValue); err != nil {
+		t.Fatalf("result is not JSON: %v (%s)", err, got)
+	}
+	if err := json.Unmarshal([]byte(want), &wantValue); err != nil {
+		t.Fatalf("bad expectation %s: %v", want, err)
+	}
+	if !reflect.DeepEqual(gotValue, wantValue) {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
+
+// Примеры из приложения A RFC 6902.
+func TestApplyJSONPatchRFCExamples(t *testing.T) {
+	tests := []struct {
+		name  string
+		doc   string
+		patch string
+		want  string // пустая строка — ожидается ошибка
+	}{
+		{
+			name:  "A.1 adding an object member",
+			doc:   `{"foo": "bar"}`,
+			patch: `[{"op": "add", "path": "/baz", "value": "qux"}]`,
+			want:  `{"baz": "qux", "foo": "bar"}`,
+		},
+		{
+			name:  "A.2 adding an array element",
+			doc:   `{"foo": ["bar", "baz"]}`,
+			patch: `[{"op": "add", "path": "/foo/1", "value": "qux"}]`,
+			want:  `{"foo": ["bar", "qux", "baz"]}`,
+		},
+		{
+			name:  "A.3 removing an object member",
+			doc:   `{"baz": "qux", "foo": "bar"}`,
+			patch: `[{"op": "remove", "path": "/baz"}]`,
+			want:  `{"foo": "bar"}`,
+		},
+		{
+			name:  "A.4 removing an array element",
+			doc:   `{"foo": ["bar", "qux", "baz"]}`,
+			patch: `[{"op": "remove", "path": "/foo/1"}]`,
+			want:  `{"foo": ["bar", "baz"]}`,
+		},
+		{
+			name:  "A.5 replacing a value",
+			doc:   `{"baz": "qux", "foo": "bar"}`,
+			patch: `[{"op": "replace", "path": "/baz", "value": "boo"}]`,
+			want:  `{"baz": "boo", "foo": "bar"}`,
+		},
+		{
+			name:  "A.6 moving a value",
+			doc:   `{"foo": {"bar": "baz", "waldo": "fred"}, "qux": {"corge": "grault"}}`,
+			patch: `[{"op": "move", "from": "/foo/waldo", "path": "/qux/thud"}]`,
+			want:  `{"foo": {"bar": "baz"}, "qux": {"corge": "grault", "thud": "fred"}}`,
+		},
+		{
+			name:  "A.7 moving an array element",
+			doc:   `{"foo": ["all", "grass", "cows", "eat"]}`,
+			patch: `[{"op": "move", "from": "/foo/1", "path": "/foo/3"}]`,
+			want:  `{"foo": ["all", "cows", "eat", "grass"]}`,
+		},
+		{
+			name: "A.8 testing a value: success",
+			doc:  `{"baz": "qux", "foo": ["a", 2, "c"]}`,
+			patch: `[
+				{"op": "test", "path": "/baz", "value": "qux"},
+				{"op": "test", "path": "/foo/1", "value": 2}
+			]`,
+			want: `{"baz": "qux", "foo": ["a", 2, "c"]}`,
+		},
+		{
+			name:  "A.9 testing a value: error",
+			doc:   `{"baz": "qux"}`,
+			patch: `[{"op": "test", "path": "/baz", "value": "bar"}]`,
+		},
+		{
+			name:  "A.10 adding a nested member object",
+			doc:   `{"foo": "bar"}`,
+			patch: `[{"op": "add", "path": "/child", "value": {"grandchild": {}}}]`,
+			want:  `{"foo": "bar", "child": {"grandchild": {}}}`,
+		},
+		{
+			name:  "A.11 ignoring unrecognized elements",
+			doc:   `{"foo": "bar"}`,
+			patch: `[{"op": "add", "path": "/baz", "value": "qux", "xyz": 123}]`,
+			want:  `{"foo": "bar", "baz": "qux"}`,
+		},
+		{
+			name:  "A.12 adding to a nonexistent target",
+			doc:   `{"foo": "bar"}`,
+			patch: `[{"op": "add", "path": "/baz/bat", "value": "qux"}]`,
+		},
+		{
+			name:  "A.14 ~ escape ordering",
+			doc:   `{"/": 9, "~1": 10}`,
+			patch: `[{"op": "test", "path": "/~01", "value": 10}]`,
+			want:  `{"/": 9, "~1": 10}`,
+		},
+		{
+			name:  "A.15 comparing strings and numbers",
+			doc:   `{"/": 9, "~1": 10}`,
+			patch: `[{"op": "test", "path": "/~01", "value": "10"}]`,
+		},
+		{
+			name:  "A.16 adding an array value",
+			doc:   `{"foo": ["bar"]}`,
+			patch: `[{"op": "add", "path": "/foo/-", "value": ["abc", "def"]}]`,
+			want:  `{"foo": ["bar", ["abc", "def"]]}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ApplyJSONPatch([]byte(tt.doc), []byte(tt.patch))
+			if tt.want == "" {
+				if err == nil {
+					t.Fatalf("expected error, got %s", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			assertJSONEqual(t, got, tt.want)
+		})
+	}
+}
+
+func TestApplyJSONPatchEdgeCases(t *testing.T) {
+	tests := []struct {
+		name  string
+		doc   string
+		patch string
+		want  string // пустая строка — ожидается ошибка
+	}{
+		{
+			name:  "slash escape in member name",
+			doc:   `{"a/b": 1}`,
+			patch: `[{"op": "replace", "path": "/a~1b", "value": 2}]`,
+			want:  `{"a/b": 2}`,
+		},
+		{
+			name:  "tilde escape in member name",
+			doc:   `{"m~n": 1}`,
+			patch: `[{"op": "remove", "path": "/m~0n"}]`,
+			want:  `{}`,
+		},
+		{
+			name:  "replace whole document",
+			doc:   `{"foo": "bar"}`,
+			patch: `[{"op": "replace", "path": "", "value": [1, 2]}]`,
+			want:  `[1, 2]`,
+		},
+		{
+			name:  "add replaces existing member",
+			doc:   `{"foo": "bar"}`,
+			patch: `[{"op": "add", "path": "/foo", "value": "baz"}]`,
+			want:  `{"foo": "baz"}`,
+		},
+		{
+			name:  "add at end index",
+			doc:   `{"foo": [1, 2]}`,
+			patch: `[{"op": "add", "path": "/foo/2", "value": 3}]`,
+			want:  `{"foo": [1, 2, 3]}`,
+		},
+		{
+			name:  "add past end index",
+			doc:   `{"foo": [1, 2]}`,
+			patch: `[{"op": "add", "path": "/foo/3", "value": 3}]`,
+		},
+		{
+			name:  "leading zero index",
+			doc:   `{"foo": [1, 2]}`,
+			patch: `[{"op": "remove", "path": "/foo/01"}]`,
+		},
+		{
+			name:  "negative index",
+			doc:   `{"foo": [1, 2]}`,
+			patch: `[{"op": "remove", "path": "/foo/-1"}]`,
+		},
+		{
+			name:  "dash is only valid for add",
+			doc:   `{"foo": [1, 2]}`,
+			patch: `[{"op": "remove", "path": "/foo/-"}]`,
+		},
+		{
+			name:  "remove missing member",
+			doc:   `{"foo": "bar"}`,
+			patch: `[{"op": "remove", "path": "/baz"}]`,
+		},
+		{
+			name:  "replace missing member",
+			doc:   `{"foo": "bar"}`,
+			patch: `[{"op": "replace", "path": "/baz", "value": 1}]`,
+		},
+		{
+			name:  "move into own child",
+			doc:   `{"a": {"b": {}}}`,
+			patch: `[{"op": "move", "from": "/a", "path": "/a/b/c"}]`,
+		},
+		{
+			name:  "move to the same location",
+			doc:   `{"a": 1}`,
+			patch: `[{"op": "move", "from": "/a", "path": "/a"}]`,
+			want:  `{"a": 1}`,
+		},
+		{
+			name:  "move between nested arrays",
+			doc:   `{"a": [[1, 2], [3]]}`,
+			patch: `[{"op": "move", "from": "/a/0/1", "path": "/a/1/0"}]`,
+			want:  `{"a": [[1], [2, 3]]}`,
+		},
+		{
+			name: "copy is independent of the source",
+			doc:  `{"a": {"x": 1}}`,
+			patch: `[
+				{"op": "copy", "from": "/a", "path": "/b"},
+				{"op": "replace", "path": "/b/x", "value": 2}
+			]`,
+			want: `{"a": {"x": 1}, "b": {"x": 2}}`,
+		},
+		{
+			name:  "test deep equality of objects",
+			doc:   `{"a": {"x": [1, {"y": null}]}}`,
+			patch: `[{"op": "test", "path": "/a", "value": {"x": [1, {"y": null}]}}]`,
+			want:  `{"a": {"x": [1, {"y": null}]}}`,
+		},
+		{
+			name:  "pointer without leading slash",
+			doc:   `{"foo": "bar"}`,
+			patch: `[{"op": "remove", "path": "foo"}]`,
+		},
+		{
+			name:  "add without value",
+			doc:   `{"foo": "bar"}`,
+			patch: `[{"op": "add", "path": "/baz"}]`,
+		},
+		{
+			name:  "unknown operation",
+			doc:   `{"foo": "bar"}`,
+			patch: `[{"op": "frobnicate", "path": "/foo"}]`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ApplyJSONPatch([]byte(tt.doc), []byte(tt.patch))
+			if tt.want == "" {
+				if err == nil {
+					t.Fatalf("expected error, got %s", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			assertJSONEqual(t, got, tt.want)
+		})
+	}
+}
+
+func TestApplyJSONPatchErrors(t *testing.T) {
+	if _, err := ApplyJSONPatch([]byte(`{}`), []byte(`{"op": "add"}`)); !errors.Is(err, ErrInvalidPatch) {
+		t.Errorf("non-array patch: got %v, want ErrInvalidPatch", err)
+	}
+	_, err := ApplyJSONPatch([]byte(`{"a": 1}`), []byte(`[{"op": "test", "path": "/a", "value": 2}]`))
+	if !errors.Is(err, ErrTestFailed) {
+		t.Errorf("failed test: got %v, want ErrTestFailed", err)
+	}
+}
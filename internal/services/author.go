@@ -0,0 +1,176 @@
+package services
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Роли участия автора в книге.
+const (
+	RoleAuthor      = "author"
+	RoleTranslator  = "translator"
+	RoleIllustrator = "illustrator"
+	RoleEditor      = "editor"
+)
+
+// ValidAuthorRole сообщает, что роль входит в список поддерживаемых.
+func ValidAuthorRole(role string) bool {
+	switch role {
+	case RoleAuthor, RoleTranslator, RoleIllustrator, RoleEditor:
+		return true
+	}
+	return false
+}
+
+type Author struct {
+	ID               uint     `gorm:"primaryKey" json:"id"`
+	Name             string   `gorm:"not null" json:"name"`
+	AlternativeNames []string `gorm:"serializer:json" json:"alternative_names"`
+	Bio              string   `json:"bio"`
+	BirthYear        *int     `json:"birth_year"`
+	DeathYear        *int     `json:"death_year"`
+	// NameKey — ключ сопоставления вариантов имени («фамилия|инициал»), по нему склеиваются дубликаты
+	NameKey string       `gorm:"index" json:"-"`
+	Books   []BookAuthor `gorm:"foreignKey:AuthorID" json:"books,omitempty"`
+}
+
+// BookAuthor связывает книгу и автора с указанием роли. Один человек может участвовать
+// в книге в нескольких ролях, поэтому роль входит в первичный ключ.
+type BookAuthor struct {
+	BookID   uint    `gorm:"primaryKey" json:"book_id"`
+	AuthorID uint    `gorm:"primaryKey" json:"author_id"`
+	Role     string  `gorm:"primaryKey;default:author" json:"role"`
+	Position int     `json:"position"`
+	Book     *Book   `gorm:"constraint:OnDelete:CASCADE" json:"book,omitempty"`
+	Author   *Author `gorm:"constraint:OnDelete:CASCADE" json:"author,omitempty"`
+}
+
+var authorSeparators = regexp.MustCompile(`\s*(?:[,;&/]|\s+и\s+|\s+and\s+)\s*`)
+
+// ParseAuthorNames разбивает строку авторов вида «Ильф И., Петров Е.» на отдельные имена.
+func ParseAuthorNames(raw string) []string {
+	var names []string
+	for _, part := range authorSeparators.Split(raw, -1) {
+		part = strings.Join(strings.Fields(part), " ")
+		if part != "" {
+			names = append(names, part)
+		}
+	}
+	return names
+}
+
+// AuthorNameKey строит ключ сопоставления из фамилии и первого инициала, чтобы
+// «Толстой Л.Н.», «Л. Н. Толстой» и «Лев Толстой» считались одним автором.
+func AuthorNameKey(name string) string {
+	var full, initials []string
+	for _, token := range strings.FieldsFunc(name, func(r rune) bool {
+		return unicode.IsSpace(r) || r == '.'
+	}) {
+		if len([]rune(token)) == 1 || strings.Contains(name, token+".") {
+			initials = append(initials, token)
+		} else {
+			full = append(full, token)
+		}
+	}
+	if len(full) == 0 {
+		return strings.ToLower(strings.Join(initials, " "))
+	}
+
+	surname := full[len(full)-1]
+	var first string
+	switch {
+	case len(initials) > 0:
+		// «Фамилия И.О.» или «И.О. Фамилия»: фамилия — единственное полное слово
+		surname = full[0]
+		first = initials[0]
+	case len(full) > 1:
+		// «Имя Фамилия» или «Имя Отчество Фамилия»
+		first = full[0]
+	}
+
+	key := strings.ToLower(surname)
+	if first != "" {
+		key += "|" + strings.ToLower(string([]rune(first)[0]))
+	}
+	return key
+}
+
+// FindOrCreateAuthor ищет автора по ключу имени и создает его, если такого нет.
+// Новые варианты написания сохраняются в AlternativeNames; более полная форма
+// имени становится основной.
+func FindOrCreateAuthor(tx *gorm.DB, name string) (*Author, error) {
+	key := AuthorNameKey(name)
+	var author Author
+	err := tx.Where("name_key = ?", key).First(&author).Error
+	if err == gorm.ErrRecordNotFound {
+		author = Author{Name: name, NameKey: key, AlternativeNames: []string{}}
+		return &author, tx.Create(&author).Error
+	}
+	if err != nil {
+		return nil, err
+	}
+	if author.Name == name || containsString(author.AlternativeNames, name) {
+		return &author, nil
+	}
+
+	if len([]rune(name)) > len([]rune(author.Name)) && !strings.Contains(name, ".") {
+		author.AlternativeNames = append(author.AlternativeNames, author.Name)
+		author.Name = name
+	} else {
+		author.AlternativeNames = append(author.AlternativeNames, name)
+	}
+	return &author, tx.Save(&author).Error
+}
+
+// LinkAuthorsFromString создает связи книги с авторами, разобранными из строки Book.Author.
+func LinkAuthorsFromString(tx *gorm.DB, book *Book) error {
+	for i, name := range ParseAuthorNames(book.Author) {
+		author, err := FindOrCreateAuthor(tx, name)
+		if err != nil {
+			return err
+		}
+		link := BookAuthor{BookID: book.ID, AuthorID: author.ID, Role: RoleAuthor, Position: i}
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&link).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// BumpAuthorBooks увеличивает версии всех книг автора authorID, включая удаленные:
+// переименование или удаление автора меняет их представление.
+func BumpAuthorBooks(tx *gorm.DB, authorID uint) error {
+	return tx.Exec("UPDATE books SET version = version + 1 WHERE id IN (SELECT book_id FROM book_authors WHERE author_id = ?)", authorID).Error
+}
+
+// migrateLegacyAuthors разбирает строки авторов у книг, у которых еще нет связей с авторами.
+func migrateLegacyAuthors(db *gorm.DB) error {
+	var books []Book
+	err := db.Where("author <> ''").
+		Where("NOT EXISTS (SELECT 1 FROM book_authors WHERE book_authors.book_id = books.id)").
+		Find(&books).Error
+	if err != nil {
+		return err
+	}
+	return db.Transaction(func(tx *gorm.DB) error {
+		for i := range books {
+			if err := LinkAuthorsFromString(tx, &books[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,171 @@
+package services
+
+import (
+	"Projectmugen/internal/utils"
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Форматы файлов книг.
+const (
+	FormatEPUB = "epub"
+	FormatPDF  = "pdf"
+)
+
+var ErrUnsupportedBookFile = errors.New("only EPUB and PDF files are supported")
+
+// BookFileMaxBytes — максимальный размер загружаемого файла книги в байтах.
+var BookFileMaxBytes = int64(utils.GetEnvInt("BOOK_FILE_MAX_BYTES", 200<<20))
+
+// DownloadURLTTL — время жизни подписанной ссылки на скачивание.
+var DownloadURLTTL = utils.GetEnvDuration("DOWNLOAD_URL_TTL", 5*time.Minute)
+
+// downloadSigningKey подписывает ссылки на скачивание; по умолчанию совпадает с ключом JWT.
+var downloadSigningKey = []byte(utils.GetEnv("DOWNLOAD_SIGNING_KEY", string(JwtKey)))
+
+var bookFileContentTypes = map[string]string{
+	FormatEPUB: "application/epub+zip",
+	FormatPDF:  "application/pdf",
+}
+
+// BookFile — электронная версия книги в одном из форматов. У книги может быть
+// не больше одного файла каждого формата; файл хранится в PrivateStorage.
+type BookFile struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	BookID      uint      `gorm:"uniqueIndex:idx_book_files_book_format;not null" json:"book_id"`
+	Format      string    `gorm:"uniqueIndex:idx_book_files_book_format;not null" json:"format"`
+	ContentType string    `json:"content_type"`
+	Size        int64     `json:"size"`
+	Key         string    `json:"-"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// detectBookFormat определяет формат по сигнатуре файла. EPUB — это zip-архив,
+// первой записью которого по спецификации идет несжатый файл mimetype.
+func detectBookFormat(head []byte) (string, bool) {
+	if bytes.HasPrefix(head, []byte("%PDF-")) {
+		return FormatPDF, true
+	}
+	if bytes.HasPrefix(head, []byte("PK\x03\x04")) && len(head) >= 58 &&
+		string(head[30:58]) == "mimetypeapplication/epub+zip" {
+		return FormatEPUB, true
+	}
+	return "", false
+}
+
+// SaveBookFile проверяет формат файла и сохраняет его как электронную версию книги,
+// заменяя ранее загруженный файл того же формата. Версия книги увеличивается,
+// изменение записывается в историю от имени username.
+func SaveBookFile(ctx context.Context, bookID uint, username string, r io.Reader, size int64) (*BookFile, error) {
+	reader := bufio.NewReader(r)
+	head, _ := reader.Peek(58)
+	format, ok := detectBookFormat(head)
+	if !ok {
+		return nil, ErrUnsupportedBookFile
+	}
+
+	token, err := randomToken()
+	if err != nil {
+		return nil, err
+	}
+	file := &BookFile{
+		BookID:      bookID,
+		Format:      format,
+		ContentType: bookFileContentTypes[format],
+		Size:        size,
+		Key:         fmt.Sprintf("books/%d/%s.%s", bookID, token, format),
+	}
+	if err := PrivateStorage.Put(ctx, file.Key, reader, size, file.ContentType); err != nil {
+		return nil, err
+	}
+
+	var previous *BookFile
+	err = Db.Transaction(func(tx *gorm.DB) error {
+		// Блокировка строки книги упорядочивает параллельные загрузки одного формата
+		if err := BumpBookVersion(tx, bookID, 0); err != nil {
+			return err
+		}
+		var existing BookFile
+		err := tx.Where("book_id = ? AND format = ?", bookID, format).First(&existing).Error
+		if err == nil {
+			previous = &existing
+		} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		err = tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "book_id"}, {Name: "format"}},
+			DoUpdates: clause.AssignmentColumns([]string{"content_type", "size", "key", "created_at"}),
+		}).Create(file).Error
+		if err != nil {
+			return err
+		}
+		return RecordBookFileRevision(tx, username, bookID, previous, file)
+	})
+	if err != nil {
+		deleteObjects(ctx, PrivateStorage, []string{file.Key})
+		return nil, err
+	}
+	if previous != nil {
+		deleteObjects(ctx, PrivateStorage, []string{previous.Key})
+	}
+	return file, nil
+}
+
+// DeleteBookFile удаляет файл книги, увеличивает версию книги и записывает изменение
+// в историю от имени username. Возвращает gorm.ErrRecordNotFound, если файла нет.
+func DeleteBookFile(ctx context.Context, bookID, fileID uint, username string) error {
+	var file BookFile
+	if err := Db.Where("book_id = ?", bookID).First(&file, fileID).Error; err != nil {
+		return err
+	}
+	err := Db.Transaction(func(tx *gorm.DB) error {
+		if err := BumpBookVersion(tx, bookID, 0); err != nil {
+			return err
+		}
+		result := tx.Delete(&file)
+		if result.Error != nil {
+			return result.Error
+		}
+		// Файл удален параллельным запросом, пока книга не была заблокирована
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return RecordBookFileRevision(tx, username, bookID, &file, nil)
+	})
+	if err != nil {
+		return err
+	}
+	deleteObjects(ctx, PrivateStorage, []string{file.Key})
+	return nil
+}
+
+// SignDownload подписывает право пользователя скачать файл до момента expires. Роль
+// пользователя на момент выдачи ссылки входит в подпись: маршрут скачивания не проходит
+// авторизацию и узнает роль только из ссылки.
+func SignDownload(fileID uint, username, role string, expires time.Time) string {
+	mac := hmac.New(sha256.New, downloadSigningKey)
+	fmt.Fprintf(mac, "%d\n%s\n%s\n%d", fileID, username, role, expires.Unix())
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyDownload проверяет подпись и срок действия ссылки на скачивание.
+func VerifyDownload(fileID uint, username, role, expires, signature string) bool {
+	unix, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || time.Now().Unix() > unix {
+		return false
+	}
+	expected := SignDownload(fileID, username, role, time.Unix(unix, 0))
+	return hmac.Equal([]byte(expected), []byte(signature))
+}
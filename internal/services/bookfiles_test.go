@@ -0,0 +1,46 @@
+package services
+
+import (
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestVerifyDownload(t *testing.T) {
+	expires := time.Now().Add(time.Minute)
+	unix := strconv.FormatInt(expires.Unix(), 10)
+	signature := SignDownload(7, "alice", "admin", expires)
+
+	if !VerifyDownload(7, "alice", "admin", unix, signature) {
+		t.Fatal("valid link rejected")
+	}
+	tests := []struct {
+		name           string
+		fileID         uint
+		username, role string
+		expires        string
+	}{
+		{"other file", 8, "alice", "admin", unix},
+		{"other user", 7, "bob", "admin", unix},
+		{"role removed", 7, "alice", "", unix},
+		{"role forged", 7, "alice", "user", unix},
+		{"expiry extended", 7, "alice", "admin", strconv.FormatInt(expires.Unix()+3600, 10)},
+		{"malformed expiry", 7, "alice", "admin", "soon"},
+	}
+	for _, tt := range tests {
+		if VerifyDownload(tt.fileID, tt.username, tt.role, tt.expires, signature) {
+			t.Errorf("%s: tampered link accepted", tt.name)
+		}
+	}
+
+	// Ссылка без роли не дает права администратора
+	plain := SignDownload(7, "alice", "", expires)
+	if VerifyDownload(7, "alice", "admin", unix, plain) {
+		t.Error("role added to an unprivileged link")
+	}
+
+	past := time.Now().Add(-time.Second)
+	if VerifyDownload(7, "alice", "", strconv.FormatInt(past.Unix(), 10), SignDownload(7, "alice", "", past)) {
+		t.Error("expired link accepted")
+	}
+}
@@ -0,0 +1,367 @@
+package services
+
+import (
+	"Projectmugen/internal/models"
+	"Projectmugen/internal/utils"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Причины, по которым строку корзины нельзя оформить как есть (CartItem.Issue).
+const (
+	CartIssueNotFound          = "not_found"
+	CartIssueNotForSale        = "not_for_sale"
+	CartIssueInsufficientStock = "insufficient_stock"
+	CartIssuePriceChanged      = "price_changed"
+)
+
+var ErrCartNotReady = errors.New("cart contains unavailable books")
+
+// CartTTL — срок хранения корзины с момента последнего изменения.
+var CartTTL = utils.GetEnvDuration("CART_TTL", 30*24*time.Hour)
+
+// CartPurgeInterval — период удаления просроченных корзин.
+var CartPurgeInterval = utils.GetEnvDuration("CART_PURGE_INTERVAL", time.Hour)
+
+// Cart — корзина покупателя. У корзины пользователя заполнен Username, у анонимной — Token,
+// по которому клиент ее находит. Суммы и признаки доступности не хранятся: они вычисляются
+// заново при каждом чтении корзины (см. RevalidateCart).
+type Cart struct {
+	ID        uint       `gorm:"primaryKey" json:"id"`
+	Username  *string    `gorm:"uniqueIndex;size:64" json:"-"`
+	Token     *string    `gorm:"uniqueIndex;size:64" json:"token,omitempty"`
+	Items     []CartItem `gorm:"constraint:OnDelete:CASCADE" json:"items"`
+	ExpiresAt time.Time  `gorm:"index" json:"expires_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+	// Currency и Total — валюта и сумма оформляемых строк; Ready — можно ли оформить корзину
+	Currency string `gorm:"-" json:"currency"`
+	Total    int64  `gorm:"-" json:"total"`
+	Ready    bool   `gorm:"-" json:"ready"`
+}
+
+// CartItem — книга в корзине. AddedPrice и AddedCurrency запоминают цену книги на момент
+// добавления или последнего изменения количества, чтобы покупатель видел изменение цены.
+type CartItem struct {
+	CartID        uint      `gorm:"primaryKey" json:"-"`
+	BookID        uint      `gorm:"primaryKey" json:"book_id"`
+	Book          *Book     `gorm:"constraint:OnDelete:CASCADE" json:"book,omitempty"`
+	Quantity      int       `gorm:"not null" json:"quantity"`
+	AddedPrice    *int64    `json:"added_price"`
+	AddedCurrency string    `gorm:"size:3" json:"added_currency,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+	// UnitPrice и LineTotal — текущая цена в валюте корзины; Issue — причина, по которой
+	// строку нельзя оформить, или price_changed, если цена изменилась после добавления
+	UnitPrice int64  `gorm:"-" json:"unit_price"`
+	LineTotal int64  `gorm:"-" json:"line_total"`
+	Issue     string `gorm:"-" json:"issue,omitempty"`
+}
+
+func newCartToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// UserCart возвращает корзину пользователя, создавая ее при необходимости. Просроченная
+// корзина, которую еще не удалила фоновая очистка, очищается.
+func UserCart(username string) (*Cart, error) {
+	cart := Cart{Username: &username, ExpiresAt: time.Now().Add(CartTTL)}
+	if err := Db.Clauses(clause.OnConflict{DoNothing: true}).Create(&cart).Error; err != nil {
+		return nil, err
+	}
+	if err := Db.Where("username = ?", username).First(&cart).Error; err != nil {
+		return nil, err
+	}
+	if cart.ExpiresAt.Before(time.Now()) {
+		if err := ClearCart(&cart); err != nil {
+			return nil, err
+		}
+	}
+	return &cart, nil
+}
+
+// GuestCart находит анонимную корзину по токену. Просроченные корзины не находятся.
+func GuestCart(token string) (*Cart, error) {
+	var cart Cart
+	err := Db.Where("token = ? AND expires_at > ?", token, time.Now()).First(&cart).Error
+	if err != nil {
+		return nil, err
+	}
+	return &cart, nil
+}
+
+// NewGuestCart создает анонимную корзину со случайным токеном.
+func NewGuestCart() (*Cart, error) {
+	token, err := newCartToken()
+	if err != nil {
+		return nil, err
+	}
+	cart := Cart{Token: &token, ExpiresAt: time.Now().Add(CartTTL)}
+	if err := Db.Create(&cart).Error; err != nil {
+		return nil, err
+	}
+	return &cart, nil
+}
+
+// updateCart выполняет изменение корзины в транзакции с блокировкой ее строки и продлевает
+// срок хранения корзины.
+func updateCart(cart *Cart, fn func(tx *gorm.DB) error) error {
+	return Db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&Cart{}, cart.ID).Error; err != nil {
+			return err
+		}
+		if err := fn(tx); err != nil {
+			return err
+		}
+		cart.ExpiresAt = time.Now().Add(CartTTL)
+		return tx.Model(cart).Update("expires_at", cart.ExpiresAt).Error
+	})
+}
+
+// setCartItem записывает количество книги в корзине, запоминая ее текущую цену. Книга должна
+// продаваться и быть на складе в нужном количестве.
+func setCartItem(tx *gorm.DB, cartID, bookID uint, quantity int) error {
+	if quantity <= 0 || quantity > OrderMaxQuantity {
+		return &OrderLineError{BookID: int(bookID), Err: ErrInvalidQuantity}
+	}
+	var book Book
+	if err := tx.First(&book, bookID).Error; err != nil {
+		return &OrderLineError{BookID: int(bookID), Err: err}
+	}
+	if book.Price == nil {
+		return &OrderLineError{BookID: int(bookID), Err: ErrNotForSale}
+	}
+	if book.Stock < quantity {
+		return &OrderLineError{BookID: int(bookID), Err: ErrInsufficientStock}
+	}
+	item := CartItem{CartID: cartID, BookID: bookID, Quantity: quantity, AddedPrice: book.Price, AddedCurrency: book.Currency}
+	return tx.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "cart_id"}, {Name: "book_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"quantity", "added_price", "added_currency"}),
+	}).Create(&item).Error
+}
+
+// cartQuantity возвращает количество книги в корзине (0, если ее там нет).
+func cartQuantity(tx *gorm.DB, cartID, bookID uint) (int, error) {
+	var item CartItem
+	err := tx.Where("cart_id = ? AND book_id = ?", cartID, bookID).Limit(1).Find(&item).Error
+	return item.Quantity, err
+}
+
+// AddCartItem добавляет в корзину quantity экземпляров книги; если книга уже в корзине,
+// количества складываются.
+func AddCartItem(cart *Cart, bookID uint, quantity int) error {
+	if quantity <= 0 {
+		return &OrderLineError{BookID: int(bookID), Err: ErrInvalidQuantity}
+	}
+	return updateCart(cart, func(tx *gorm.DB) error {
+		current, err := cartQuantity(tx, cart.ID, bookID)
+		if err != nil {
+			return err
+		}
+		return setCartItem(tx, cart.ID, bookID, current+quantity)
+	})
+}
+
+// SetCartItemQuantity меняет количество книги, уже лежащей в корзине.
+func SetCartItemQuantity(cart *Cart, bookID uint, quantity int) error {
+	return updateCart(cart, func(tx *gorm.DB) error {
+		current, err := cartQuantity(tx, cart.ID, bookID)
+		if err != nil {
+			return err
+		}
+		if current == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return setCartItem(tx, cart.ID, bookID, quantity)
+	})
+}
+
+// RemoveCartItem удаляет книгу из корзины.
+func RemoveCartItem(cart *Cart, bookID uint) error {
+	return updateCart(cart, func(tx *gorm.DB) error {
+		result := tx.Where("cart_id = ? AND book_id = ?", cart.ID, bookID).Delete(&CartItem{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return nil
+	})
+}
+
+// ClearCart удаляет из корзины все книги.
+func ClearCart(cart *Cart) error {
+	return updateCart(cart, func(tx *gorm.DB) error {
+		return tx.Where("cart_id = ?", cart.ID).Delete(&CartItem{}).Error
+	})
+}
+
+// MergeGuestCart переносит книги анонимной корзины в корзину пользователя и удаляет анонимную
+// корзину. Количества одной книги складываются, но не больше OrderMaxQuantity; наличие
+// на складе не проверяется — его покажет проверка корзины при чтении.
+func MergeGuestCart(token, username string) error {
+	guest, err := GuestCart(token)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	cart, err := UserCart(username)
+	if err != nil {
+		return err
+	}
+	return updateCart(cart, func(tx *gorm.DB) error {
+		var items []CartItem
+		if err := tx.Where("cart_id = ?", guest.ID).Find(&items).Error; err != nil {
+			return err
+		}
+		for _, item := range items {
+			current, err := cartQuantity(tx, cart.ID, item.BookID)
+			if err != nil {
+				return err
+			}
+			item.CartID = cart.ID
+			item.Quantity = min(current+item.Quantity, OrderMaxQuantity)
+			err = tx.Clauses(clause.OnConflict{
+				Columns:   []clause.Column{{Name: "cart_id"}, {Name: "book_id"}},
+				DoUpdates: clause.AssignmentColumns([]string{"quantity"}),
+			}).Create(&item).Error
+			if err != nil {
+				return err
+			}
+		}
+		return tx.Delete(guest).Error
+	})
+}
+
+// RevalidateCart загружает строки корзины с текущими книгами и пересчитывает цены в валюту
+// currency по курсам rates. Строки удаленных, снятых с продажи книг и книг, которых не хватает
+// на складе, помечаются Issue и не входят в сумму; с такими строками корзина не готова
+// к оформлению. Изменение цены после добавления отмечается, но оформлению не мешает.
+func RevalidateCart(cart *Cart, currency string, rates Rates) error {
+	return revalidateCart(Db, cart, currency, rates)
+}
+
+// revalidateCart проверяет корзину, читая ее строки через db; см. RevalidateCart.
+func revalidateCart(db *gorm.DB, cart *Cart, currency string, rates Rates) error {
+	cart.Items = nil
+	err := db.Preload("Book").Preload("Book.Cover").
+		Where("cart_id = ?", cart.ID).
+		Order("created_at asc, book_id asc").
+		Find(&cart.Items).Error
+	if err != nil {
+		return err
+	}
+
+	cart.Currency = currency
+	cart.Total = 0
+	cart.Ready = len(cart.Items) > 0
+	for i := range cart.Items {
+		item := &cart.Items[i]
+		book := item.Book
+		switch {
+		case book == nil:
+			item.Issue = CartIssueNotFound
+		case book.Price == nil:
+			item.Issue = CartIssueNotForSale
+		case book.Stock < item.Quantity:
+			item.Issue = CartIssueInsufficientStock
+		case item.AddedPrice == nil || *item.AddedPrice != *book.Price || item.AddedCurrency != book.Currency:
+			item.Issue = CartIssuePriceChanged
+		}
+		if book != nil {
+			book.Available = book.Stock > 0
+		}
+		if book == nil || book.Price == nil {
+			cart.Ready = false
+			continue
+		}
+		unitPrice, _, err := rates.Convert(*book.Price, book.Currency, currency)
+		if err != nil {
+			return err
+		}
+		item.UnitPrice = unitPrice
+		if item.Issue == CartIssueInsufficientStock {
+			cart.Ready = false
+			continue
+		}
+		item.LineTotal = unitPrice * int64(item.Quantity)
+		cart.Total += item.LineTotal
+	}
+	return nil
+}
+
+// CheckoutCart оформляет содержимое корзины пользователя как заказ (см. PlaceOrder) и в той же
+// транзакции убирает из корзины оформленные книги. Строки корзины читаются под блокировкой ее
+// строки, поэтому книга, добавленная одновременно с оформлением, не пропадает без заказа.
+// Корзина с недоступными книгами не оформляется: возвращается ErrCartNotReady, и покупатель
+// должен сначала поправить корзину.
+func CheckoutCart(cart *Cart, currency string, rates Rates) (*models.Order, error) {
+	var order *models.Order
+	err := Db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&Cart{}, cart.ID).Error; err != nil {
+			return err
+		}
+		if err := revalidateCart(tx, cart, currency, rates); err != nil {
+			return err
+		}
+		if len(cart.Items) == 0 {
+			return ErrEmptyOrder
+		}
+		if !cart.Ready {
+			return ErrCartNotReady
+		}
+		products := make([]models.ProductInOrder, len(cart.Items))
+		bookIDs := make([]uint, len(cart.Items))
+		for i, item := range cart.Items {
+			products[i] = models.ProductInOrder{ProductID: int(item.BookID), Quantity: item.Quantity}
+			bookIDs[i] = item.BookID
+		}
+
+		var err error
+		order, err = placeOrder(tx, *cart.Username, products, currency, rates)
+		if err != nil {
+			return err
+		}
+		return tx.Where("cart_id = ? AND book_id IN ?", cart.ID, bookIDs).Delete(&CartItem{}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return order, nil
+}
+
+// PurgeExpiredCarts удаляет корзины, срок хранения которых истек. Возвращает количество
+// удаленных корзин.
+func PurgeExpiredCarts() (int64, error) {
+	result := Db.Where("expires_at < ?", time.Now()).Delete(&Cart{})
+	return result.RowsAffected, result.Error
+}
+
+// StartCartPurger запускает фоновое удаление просроченных корзин с периодом CartPurgeInterval.
+func StartCartPurger() {
+	go func() {
+		ticker := time.NewTicker(CartPurgeInterval)
+		defer ticker.Stop()
+		for {
+			purged, err := PurgeExpiredCarts()
+			if err != nil {
+				log.Println("cart purge:", err)
+			} else if purged > 0 {
+				log.Printf("cart purge: removed %d carts", purged)
+			}
+			<-ticker.C
+		}
+	}()
+}
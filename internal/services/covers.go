@@ -0,0 +1,216 @@
+package services
+
+import (
+	"Projectmugen/internal/storage"
+	"Projectmugen/internal/utils"
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image"
+	"image/jpeg"
+	_ "image/png"
+	"log"
+	"net/http"
+	"time"
+
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrUnsupportedImage = errors.New("unsupported image type")
+	ErrImageTooLarge    = errors.New("image dimensions are too large")
+)
+
+// CoverMaxBytes — максимальный размер загружаемой обложки в байтах.
+var CoverMaxBytes = int64(utils.GetEnvInt("COVER_MAX_BYTES", 5<<20))
+
+// Допустимые типы обложек и расширения, под которыми сохраняется оригинал.
+var coverContentTypes = map[string]string{
+	"image/jpeg": "jpg",
+	"image/png":  "png",
+	"image/webp": "webp",
+}
+
+// Размеры миниатюр: изображение вписывается в прямоугольник с сохранением пропорций.
+var coverThumbnailSizes = map[string]image.Point{
+	"small":  {X: 160, Y: 240},
+	"medium": {X: 400, Y: 600},
+}
+
+const coverMaxPixels = 40_000_000
+
+type BookCover struct {
+	BookID      uint      `gorm:"primaryKey" json:"-"`
+	ContentType string    `json:"content_type"`
+	Width       int       `json:"width"`
+	Height      int       `json:"height"`
+	OriginalKey string    `json:"-"`
+	SmallKey    string    `json:"-"`
+	MediumKey   string    `json:"-"`
+	UpdatedAt   time.Time `json:"updated_at"`
+
+	URL       string `gorm:"-" json:"url"`
+	SmallURL  string `gorm:"-" json:"small_url"`
+	MediumURL string `gorm:"-" json:"medium_url"`
+}
+
+// AfterFind заполняет публичные ссылки на обложку и миниатюры.
+func (c *BookCover) AfterFind(tx *gorm.DB) error {
+	c.fillURLs()
+	return nil
+}
+
+func (c *BookCover) fillURLs() {
+	if Storage == nil {
+		return
+	}
+	c.URL = Storage.URL(c.OriginalKey)
+	c.SmallURL = Storage.URL(c.SmallKey)
+	c.MediumURL = Storage.URL(c.MediumKey)
+}
+
+func (c *BookCover) keys() []string {
+	return []string{c.OriginalKey, c.SmallKey, c.MediumKey}
+}
+
+// SaveCover проверяет изображение, строит миниатюры и сохраняет обложку книги.
+// Файлы предыдущей обложки удаляются после успешной записи новой; версия книги увеличивается.
+func SaveCover(ctx context.Context, bookID uint, data []byte) (*BookCover, error) {
+	contentType := http.DetectContentType(data)
+	ext, ok := coverContentTypes[contentType]
+	if !ok {
+		return nil, ErrUnsupportedImage
+	}
+
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrUnsupportedImage
+	}
+	if cfg.Width*cfg.Height > coverMaxPixels {
+		return nil, ErrImageTooLarge
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrUnsupportedImage
+	}
+
+	token, err := randomToken()
+	if err != nil {
+		return nil, err
+	}
+	prefix := fmt.Sprintf("covers/%d/%s", bookID, token)
+	cover := &BookCover{
+		BookID:      bookID,
+		ContentType: contentType,
+		Width:       cfg.Width,
+		Height:      cfg.Height,
+		OriginalKey: prefix + "-original." + ext,
+		SmallKey:    prefix + "-small.jpg",
+		MediumKey:   prefix + "-medium.jpg",
+	}
+
+	if err := Storage.Put(ctx, cover.OriginalKey, bytes.NewReader(data), int64(len(data)), contentType); err != nil {
+		return nil, err
+	}
+	for name, key := range map[string]string{"small": cover.SmallKey, "medium": cover.MediumKey} {
+		var buf bytes.Buffer
+		thumb := resizeToFit(img, coverThumbnailSizes[name])
+		if err := jpeg.Encode(&buf, thumb, &jpeg.Options{Quality: 85}); err != nil {
+			deleteObjects(ctx, Storage, cover.keys())
+			return nil, err
+		}
+		if err := Storage.Put(ctx, key, &buf, int64(buf.Len()), "image/jpeg"); err != nil {
+			deleteObjects(ctx, Storage, cover.keys())
+			return nil, err
+		}
+	}
+
+	var previous BookCover
+	hadPrevious := Db.First(&previous, bookID).Error == nil
+	// Ссылки на обложку входят в представление книги, поэтому версия книги увеличивается
+	err = Db.Transaction(func(tx *gorm.DB) error {
+		if err := BumpBookVersion(tx, bookID, 0); err != nil {
+			return err
+		}
+		return tx.Clauses(clause.OnConflict{UpdateAll: true}).Create(cover).Error
+	})
+	if err != nil {
+		deleteObjects(ctx, Storage, cover.keys())
+		return nil, err
+	}
+	if hadPrevious {
+		deleteObjects(ctx, Storage, previous.keys())
+	}
+
+	cover.fillURLs()
+	return cover, nil
+}
+
+// DeleteCover удаляет обложку книги и ее файлы и увеличивает версию книги. Возвращает gorm.ErrRecordNotFound, если обложки нет.
+func DeleteCover(ctx context.Context, bookID uint) error {
+	var cover BookCover
+	if err := Db.First(&cover, bookID).Error; err != nil {
+		return err
+	}
+	err := Db.Transaction(func(tx *gorm.DB) error {
+		if err := BumpBookVersion(tx, bookID, 0); err != nil {
+			return err
+		}
+		return tx.Delete(&cover).Error
+	})
+	if err != nil {
+		return err
+	}
+	deleteObjects(ctx, Storage, cover.keys())
+	return nil
+}
+
+// resizeToFit уменьшает изображение так, чтобы оно вписалось в bounds. Увеличение не выполняется.
+func resizeToFit(src image.Image, bounds image.Point) image.Image {
+	w, h := src.Bounds().Dx(), src.Bounds().Dy()
+	scale := 1.0
+	if sx := float64(bounds.X) / float64(w); sx < scale {
+		scale = sx
+	}
+	if sy := float64(bounds.Y) / float64(h); sy < scale {
+		scale = sy
+	}
+	dw, dh := int(float64(w)*scale+0.5), int(float64(h)*scale+0.5)
+	if dw < 1 {
+		dw = 1
+	}
+	if dh < 1 {
+		dh = 1
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	// JPEG не поддерживает прозрачность, поэтому прозрачные области заливаем белым
+	draw.Draw(dst, dst.Bounds(), image.White, image.Point{}, draw.Src)
+	draw.CatmullRom.Scale(dst, dst.Bounds(), src, src.Bounds(), draw.Over, nil)
+	return dst
+}
+
+// deleteObjects удаляет файлы из хранилища; ошибки только логируются, так как запись в БД уже согласована.
+func deleteObjects(ctx context.Context, store storage.Storage, keys []string) {
+	for _, key := range keys {
+		if key == "" {
+			continue
+		}
+		if err := store.Delete(ctx, key); err != nil {
+			log.Println("storage: failed to delete", key+":", err)
+		}
+	}
+}
+
+func randomToken() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,119 @@
+package services
+
+import (
+	"Projectmugen/internal/utils"
+	"errors"
+	"math"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Режимы округления сконвертированных цен.
+const (
+	RoundHalfUp = "half_up"
+	RoundUp     = "up"
+	RoundDown   = "down"
+)
+
+var ErrUnknownCurrency = errors.New("unsupported currency")
+
+// CurrencyRounding — режим округления при конвертации цен (half_up, up или down).
+var CurrencyRounding = utils.GetEnv("CURRENCY_ROUNDING", RoundHalfUp)
+
+// Количество знаков после запятой для валют, у которых оно отличается от двух.
+var currencyExponents = map[string]int{
+	"JPY": 0,
+	"KRW": 0,
+	"VND": 0,
+	"BHD": 3,
+	"KWD": 3,
+	"OMR": 3,
+}
+
+// CurrencyRate — курс валюты к базовой валюте магазина (DefaultCurrency).
+type CurrencyRate struct {
+	Currency string `gorm:"primaryKey;size:3" json:"currency"`
+	// Rate — стоимость одной единицы валюты в базовой валюте, например 98.5 для EUR при базовой RUB
+	Rate float64 `gorm:"type:numeric(20,10);not null" json:"rate"`
+	// RoundTo — шаг округления сконвертированных цен в минимальных единицах валюты:
+	// 1 — до копейки/цента, 100 — до целых единиц
+	RoundTo   int64     `gorm:"not null;default:1" json:"round_to"`
+	Username  string    `json:"username"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Rates — курсы валют, загруженные для обработки одного запроса.
+type Rates map[string]CurrencyRate
+
+// LoadRates загружает все курсы. Базовая валюта присутствует всегда с курсом 1.
+func LoadRates(db *gorm.DB) (Rates, error) {
+	var list []CurrencyRate
+	if err := db.Find(&list).Error; err != nil {
+		return nil, err
+	}
+	rates := Rates{DefaultCurrency: {Currency: DefaultCurrency, Rate: 1, RoundTo: 1}}
+	for _, rate := range list {
+		if rate.Currency != DefaultCurrency {
+			rates[rate.Currency] = rate
+		}
+	}
+	return rates, nil
+}
+
+// Rate возвращает курс пересчета: сколько единиц валюты to стоит одна единица валюты from.
+func (r Rates) Rate(from, to string) (float64, error) {
+	src, ok := r[from]
+	if !ok {
+		return 0, ErrUnknownCurrency
+	}
+	dst, ok := r[to]
+	if !ok || dst.Rate <= 0 {
+		return 0, ErrUnknownCurrency
+	}
+	return src.Rate / dst.Rate, nil
+}
+
+// Convert пересчитывает сумму в минимальных единицах валюты from в минимальные единицы
+// валюты to с округлением по CurrencyRounding и шагу RoundTo целевой валюты.
+// Возвращает сумму и использованный курс.
+func (r Rates) Convert(amount int64, from, to string) (int64, float64, error) {
+	if from == to {
+		if _, ok := r[to]; !ok {
+			return 0, 0, ErrUnknownCurrency
+		}
+		return amount, 1, nil
+	}
+	rate, err := r.Rate(from, to)
+	if err != nil {
+		return 0, 0, err
+	}
+	value := float64(amount) * rate * math.Pow10(CurrencyExponent(to)-CurrencyExponent(from))
+	return roundAmount(value, r[to].RoundTo, CurrencyRounding), rate, nil
+}
+
+// CurrencyExponent возвращает количество минимальных единиц валюты в виде степени десяти.
+func CurrencyExponent(currency string) int {
+	if exp, ok := currencyExponents[currency]; ok {
+		return exp
+	}
+	return 2
+}
+
+func roundAmount(value float64, step int64, mode string) int64 {
+	if step < 1 {
+		step = 1
+	}
+	units := value / float64(step)
+	// Допуск защищает от ошибок представления float: 1990.0000001 не должно округляться вверх
+	const epsilon = 1e-9
+	switch mode {
+	case RoundUp:
+		units = math.Ceil(units - epsilon)
+	case RoundDown:
+		units = math.Floor(units + epsilon)
+	default:
+		units = math.Round(units)
+	}
+	return int64(units) * step
+}
@@ -0,0 +1,68 @@
+package services
+
+import (
+	"errors"
+	"testing"
+)
+
+func testRates() Rates {
+	return Rates{
+		"RUB": {Currency: "RUB", Rate: 1, RoundTo: 1},
+		"EUR": {Currency: "EUR", Rate: 98.5, RoundTo: 1},
+		"USD": {Currency: "USD", Rate: 90, RoundTo: 100},
+		"JPY": {Currency: "JPY", Rate: 0.6, RoundTo: 1},
+		"KWD": {Currency: "KWD", Rate: 300, RoundTo: 1},
+	}
+}
+
+func TestRatesConvert(t *testing.T) {
+	tests := []struct {
+		name     string
+		amount   int64
+		from, to string
+		mode     string
+		want     int64
+	}{
+		{"same currency", 12345, "RUB", "RUB", RoundHalfUp, 12345},
+		{"half up rounds down below half", 100000, "RUB", "EUR", RoundHalfUp, 1015}, // 1015.228
+		{"half up rounds to nearest", 197, "RUB", "EUR", RoundHalfUp, 2},            // 1.9999 -> 2
+		{"up", 100000, "RUB", "EUR", RoundUp, 1016},
+		{"down", 100000, "RUB", "EUR", RoundDown, 1015},
+		{"round to whole units", 100000, "RUB", "USD", RoundHalfUp, 1100}, // 11.11 -> 11.00
+		{"round to whole units up", 100000, "RUB", "USD", RoundUp, 1200},
+		{"exact value is not rounded up", 9000, "RUB", "USD", RoundUp, 100},
+		{"exact value is not rounded down", 9850, "RUB", "EUR", RoundDown, 100},
+		{"to zero-exponent currency", 1000, "RUB", "JPY", RoundHalfUp, 17}, // 10 RUB = 16.67 JPY
+		{"from zero-exponent currency", 1000, "JPY", "RUB", RoundHalfUp, 60000},
+		{"to three-exponent currency", 100000, "RUB", "KWD", RoundHalfUp, 3333}, // 3.333 KWD
+		{"cross rate", 1000, "EUR", "USD", RoundHalfUp, 1100},                   // 10.94 -> 11.00
+	}
+
+	saved := CurrencyRounding
+	defer func() { CurrencyRounding = saved }()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			CurrencyRounding = tt.mode
+			got, _, err := testRates().Convert(tt.amount, tt.from, tt.to)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Convert(%d, %s, %s) = %d, want %d", tt.amount, tt.from, tt.to, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRatesConvertUnknownCurrency(t *testing.T) {
+	rates := testRates()
+	if _, _, err := rates.Convert(100, "RUB", "GBP"); !errors.Is(err, ErrUnknownCurrency) {
+		t.Errorf("unknown target: got %v, want ErrUnknownCurrency", err)
+	}
+	if _, _, err := rates.Convert(100, "GBP", "RUB"); !errors.Is(err, ErrUnknownCurrency) {
+		t.Errorf("unknown source: got %v, want ErrUnknownCurrency", err)
+	}
+	if _, _, err := rates.Convert(100, "GBP", "GBP"); !errors.Is(err, ErrUnknownCurrency) {
+		t.Errorf("same unknown currency: got %v, want ErrUnknownCurrency", err)
+	}
+}
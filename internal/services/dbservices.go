@@ -1,7 +1,9 @@
 package services
 
 import (
+	"Projectmugen/internal/models"
 	"log"
+	"time"
 
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
@@ -18,19 +20,95 @@ var Db *gorm.DB
 // @Router /initdb [post]
 func InitDB() {
 	dsn := "host=213.171.10.112 user=postgres password=67 dbname=bookdb port=5432 sslmode=disable"
+	dsn += searchConnParams()
 	var err error
-	Db, err = gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	Db, err = gorm.Open(postgres.Open(dsn), &gorm.Config{TranslateError: true})
 	if err != nil {
 		log.Fatal("Failed to connect to database:", err)
 	}
 
-	Db.AutoMigrate(&Book{})
+	if err := Db.SetupJoinTable(&Book{}, "Tags", &BookTag{}); err != nil {
+		log.Fatal("Failed to set up book tags:", err)
+	}
+	Db.AutoMigrate(
+		&Publisher{}, &models.Category{}, &Work{}, &Series{}, &Tag{}, &Book{}, &BookTranslation{}, &Author{}, &BookAuthor{}, &BookCover{}, &BookRevision{},
+		&StockMovement{}, &BookPrice{}, &CurrencyRate{}, &BookFile{}, &BookEntitlement{}, &BookDownload{},
+		&models.Order{}, &models.OrderProduct{}, &models.OrderStatusChange{}, &models.Review{}, &AlsoBought{}, &WishlistItem{}, &UserRecommendation{}, &Cart{}, &CartItem{},
+	)
+
+	// Уникальность ISBN теперь проверяется только среди неудаленных книг
+	if Db.Migrator().HasIndex(&Book{}, "idx_books_isbn13") {
+		Db.Migrator().DropIndex(&Book{}, "idx_books_isbn13")
+	}
+
+	if err := migrateLegacyPublisher(Db); err != nil {
+		log.Fatal("Failed to migrate publishers:", err)
+	}
+	if err := migrateLegacyAuthors(Db); err != nil {
+		log.Fatal("Failed to migrate authors:", err)
+	}
+	if err := backfillBookCreatedAt(Db); err != nil {
+		log.Fatal("Failed to backfill book creation dates:", err)
+	}
+	ensureSearchIndexes(Db)
 }
 
 type Book struct {
-	ID        uint   `gorm:"primaryKey" json:"id"`
-	Title     string `json:"title"`
-	Author    string `json:"author"`
-	Year      int    `json:"year"`
-	Publisher int    `json:"publisher"`
+	ID    uint   `gorm:"primaryKey" json:"id"`
+	Title string `json:"title"`
+	// Author — строка авторов в том виде, в каком она напечатана на книге;
+	// структурированные связи с авторами хранятся в Authors
+	Author  string       `json:"author"`
+	Authors []BookAuthor `gorm:"foreignKey:BookID" json:"authors,omitempty"`
+	Year    int          `json:"year"`
+	// Description и Title даны на языке Language (тег BCP 47); переводы хранятся в Translations
+	Description  string            `json:"description,omitempty"`
+	Language     string            `gorm:"size:16;index" json:"language,omitempty"`
+	Translations []BookTranslation `gorm:"foreignKey:BookID;constraint:OnDelete:CASCADE" json:"-"`
+	// Locale — язык, на котором отданы название и описание; OriginalTitle заполняется, если отдан перевод
+	Locale        string `gorm:"-" json:"locale,omitempty"`
+	OriginalTitle string `gorm:"-" json:"original_title,omitempty"`
+	// PublisherID ссылается на издателя; при удалении издателя ссылка обнуляется
+	PublisherID *uint            `gorm:"index" json:"publisher_id"`
+	Publisher   *Publisher       `gorm:"constraint:OnUpdate:CASCADE,OnDelete:SET NULL" json:"publisher,omitempty"`
+	CategoryID  *uint            `gorm:"index" json:"category_id"`
+	Category    *models.Category `gorm:"constraint:OnUpdate:CASCADE,OnDelete:SET NULL" json:"category,omitempty"`
+	// WorkID объединяет издания одного произведения, SeriesID и SeriesVolume задают место книги в серии
+	WorkID       *uint   `gorm:"index" json:"work_id"`
+	Work         *Work   `gorm:"constraint:OnUpdate:CASCADE,OnDelete:SET NULL" json:"work,omitempty"`
+	SeriesID     *uint   `gorm:"index" json:"series_id"`
+	Series       *Series `gorm:"constraint:OnUpdate:CASCADE,OnDelete:SET NULL" json:"series,omitempty"`
+	SeriesVolume *int    `json:"series_volume,omitempty"`
+	Tags         []Tag   `gorm:"many2many:book_tags;constraint:OnDelete:CASCADE" json:"tags,omitempty"`
+	// EditionCount — число изданий произведения; заполняется только в списке со свернутыми изданиями
+	EditionCount int `gorm:"-" json:"edition_count,omitempty"`
+	// ISBN13 хранится в каноническом виде и уникален среди неудаленных книг; ISBN10 вычисляется из него
+	ISBN13 *string    `gorm:"uniqueIndex:idx_books_isbn13_active,where:deleted_at IS NULL" json:"isbn13,omitempty"`
+	ISBN10 *string    `gorm:"index" json:"isbn10,omitempty"`
+	Cover  *BookCover `gorm:"foreignKey:BookID;constraint:OnDelete:CASCADE" json:"cover,omitempty"`
+	Files  []BookFile `gorm:"foreignKey:BookID;constraint:OnDelete:CASCADE" json:"files,omitempty"`
+	// Stock — количество экземпляров на складе; меняется только через AdjustStock,
+	// чтобы каждое изменение попадало в журнал. Остаток и наличие входят в представление
+	// книги, поэтому изменение остатка увеличивает версию.
+	Stock     int  `gorm:"not null;default:0" json:"stock"`
+	Available bool `gorm:"-" json:"available"`
+	// Price — действующая цена в минимальных единицах валюты, RegularPrice — базовая цена
+	// без учета распродажи. Оба поля вычисляются из истории цен (см. ApplyBookPrices)
+	// и не редактируются напрямую; nil означает, что цена не назначена.
+	Price        *int64 `json:"price"`
+	RegularPrice *int64 `json:"regular_price,omitempty"`
+	Currency     string `gorm:"size:3" json:"currency,omitempty"`
+	OnSale       bool   `gorm:"not null;default:false" json:"on_sale"`
+	// Version увеличивается при каждом изменении книги и используется для ETag/If-Match
+	Version uint `gorm:"not null;default:1" json:"version"`
+	// CreatedAt — момент появления книги в каталоге
+	CreatedAt time.Time `gorm:"index" json:"created_at"`
+	// DeletedAt включает мягкое удаление: такие книги скрыты из обычных запросов
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"deleted_at,omitempty" swaggertype:"string"`
+}
+
+// AfterFind вычисляет признак наличия книги на складе.
+func (b *Book) AfterFind(tx *gorm.DB) error {
+	b.Available = b.Stock > 0
+	return nil
 }
@@ -0,0 +1,74 @@
+package services
+
+import (
+	"Projectmugen/internal/utils"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Основания доступа к электронной версии книги.
+const (
+	EntitlementPurchase = "purchase" // книга куплена
+	EntitlementLoan     = "loan"     // книга выдана на время
+	EntitlementGrant    = "grant"    // доступ выдан администратором бессрочно
+)
+
+var ErrDownloadLimit = errors.New("download limit reached")
+
+// DownloadLimit — максимальное число скачиваний одной книги одним пользователем; 0 — без ограничений.
+var DownloadLimit = utils.GetEnvInt("DOWNLOAD_LIMIT_PER_BOOK", 0)
+
+// BookEntitlement дает пользователю право скачивать файлы книги. Покупка оформляется
+// при оплате заказа, выдача и бессрочный доступ — администратором.
+type BookEntitlement struct {
+	ID        uint       `gorm:"primaryKey" json:"id"`
+	Username  string     `gorm:"index:idx_entitlements_user_book;not null" json:"username"`
+	BookID    uint       `gorm:"index:idx_entitlements_user_book;not null" json:"book_id"`
+	Source    string     `gorm:"not null" json:"source"`
+	OrderID   *uint      `gorm:"index" json:"order_id,omitempty"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"` // nil — бессрочно
+	GrantedBy string     `json:"granted_by,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// BookDownload — запись журнала скачиваний; по нему считается число скачиваний пользователя.
+type BookDownload struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	Username  string    `gorm:"index:idx_downloads_user_book;not null" json:"username"`
+	BookID    uint      `gorm:"index:idx_downloads_user_book;not null" json:"book_id"`
+	FileID    uint      `json:"file_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// DownloadStat — число скачиваний книги одним пользователем.
+type DownloadStat struct {
+	Username       string    `json:"username"`
+	Count          int64     `json:"count"`
+	LastDownloadAt time.Time `json:"last_download_at"`
+}
+
+// HasEntitlement сообщает, есть ли у пользователя действующее право на книгу.
+func HasEntitlement(db *gorm.DB, username string, bookID uint) (bool, error) {
+	var count int64
+	err := db.Model(&BookEntitlement{}).
+		Where("username = ? AND book_id = ? AND (expires_at IS NULL OR expires_at > ?)", username, bookID, time.Now()).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// RecordDownload проверяет лимит скачиваний и записывает скачивание в журнал.
+// Возвращает ErrDownloadLimit, если лимит DownloadLimit исчерпан.
+func RecordDownload(tx *gorm.DB, username string, bookID, fileID uint) error {
+	if DownloadLimit > 0 {
+		var count int64
+		if err := tx.Model(&BookDownload{}).Where("username = ? AND book_id = ?", username, bookID).Count(&count).Error; err != nil {
+			return err
+		}
+		if count >= int64(DownloadLimit) {
+			return ErrDownloadLimit
+		}
+	}
+	return tx.Create(&BookDownload{Username: username, BookID: bookID, FileID: fileID}).Error
+}
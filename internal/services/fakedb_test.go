@@ -0,0 +1,244 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// fakeDB — база в памяти для тестов, которым нужна транзакция gorm без PostgreSQL.
+// Она понимает только запросы оформления заказа: чтение и обновление остатков книг
+// и вставку строк. Транзакция работает с копией состояния: Commit заменяет им зафиксированное
+// состояние, Rollback его отбрасывает. Параллельные транзакции не поддерживаются.
+type fakeDB struct {
+	mu        sync.Mutex
+	committed *fakeState
+	// fail позволяет тесту вернуть ошибку на выбранном запросе
+	fail func(query string, args []driver.Value) error
+}
+
+type fakeState struct {
+	books  map[uint]Book
+	rows   map[string][]map[string]driver.Value
+	nextID int64
+}
+
+func (s *fakeState) clone() *fakeState {
+	c := &fakeState{books: make(map[uint]Book), rows: make(map[string][]map[string]driver.Value), nextID: s.nextID}
+	for id, book := range s.books {
+		c.books[id] = book
+	}
+	for table, rows := range s.rows {
+		c.rows[table] = append([]map[string]driver.Value(nil), rows...)
+	}
+	return c
+}
+
+// newFakeDB подменяет Db базой в памяти с книгами books на время теста.
+func newFakeDB(t *testing.T, books ...Book) *fakeDB {
+	t.Helper()
+	f := &fakeDB{committed: &fakeState{books: make(map[uint]Book), rows: make(map[string][]map[string]driver.Value)}}
+	for _, book := range books {
+		f.committed.books[book.ID] = book
+	}
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: sql.OpenDB(f)}), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	previous := Db
+	Db = db
+	t.Cleanup(func() { Db = previous })
+	return f
+}
+
+// book и rows возвращают зафиксированное состояние.
+func (f *fakeDB) book(id uint) Book {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.committed.books[id]
+}
+
+func (f *fakeDB) rows(table string) []map[string]driver.Value {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.committed.rows[table]
+}
+
+func (f *fakeDB) Connect(context.Context) (driver.Conn, error) { return &fakeConn{db: f}, nil }
+func (f *fakeDB) Driver() driver.Driver                        { return nil }
+
+// fakeConn — соединение с fakeDB; запросы вне транзакции сразу меняют зафиксированное состояние.
+type fakeConn struct {
+	db *fakeDB
+	tx *fakeState
+}
+
+func (c *fakeConn) Prepare(string) (driver.Stmt, error) { return nil, driver.ErrSkip }
+func (c *fakeConn) Close() error                        { return nil }
+
+func (c *fakeConn) Begin() (driver.Tx, error) {
+	c.db.mu.Lock()
+	defer c.db.mu.Unlock()
+	c.tx = c.db.committed.clone()
+	return c, nil
+}
+
+func (c *fakeConn) Commit() error {
+	c.db.mu.Lock()
+	defer c.db.mu.Unlock()
+	c.db.committed, c.tx = c.tx, nil
+	return nil
+}
+
+func (c *fakeConn) Rollback() error {
+	c.tx = nil
+	return nil
+}
+
+func (c *fakeConn) QueryContext(_ context.Context, query string, named []driver.NamedValue) (driver.Rows, error) {
+	columns, values, _, err := c.db.exec(c.tx, query, named)
+	if err != nil {
+		return nil, err
+	}
+	return &fakeRows{columns: columns, values: values}, nil
+}
+
+func (c *fakeConn) ExecContext(_ context.Context, query string, named []driver.NamedValue) (driver.Result, error) {
+	_, _, affected, err := c.db.exec(c.tx, query, named)
+	if err != nil {
+		return nil, err
+	}
+	return driver.RowsAffected(affected), nil
+}
+
+type fakeRows struct {
+	columns []string
+	values  [][]driver.Value
+}
+
+func (r *fakeRows) Columns() []string { return r.columns }
+func (r *fakeRows) Close() error      { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.values) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.values[0])
+	r.values = r.values[1:]
+	return nil
+}
+
+var (
+	fakeSelectBooks = regexp.MustCompile(`^SELECT \* FROM "books" WHERE id IN \(`)
+	fakeSelectStock = regexp.MustCompile(`^SELECT "?stock"? FROM "books" WHERE id = \$1`)
+	fakeCountBooks  = regexp.MustCompile(`^SELECT count\(\*\) FROM "books" WHERE id = \$1`)
+	fakeUpdateStock = regexp.MustCompile(`^UPDATE "books" SET "stock"=stock \+ \$1,"version"=version \+ 1 WHERE \(id = \$2 AND stock \+ \$3 >= 0\)`)
+	fakeInsert      = regexp.MustCompile(`^INSERT INTO "(\w+)" \(([^)]*)\) VALUES .*?(?: RETURNING (.*))?$`)
+)
+
+var fakeBookColumns = []string{"id", "title", "price", "currency", "stock", "version"}
+
+func fakeBookRow(book Book) []driver.Value {
+	var price driver.Value
+	if book.Price != nil {
+		price = *book.Price
+	}
+	return []driver.Value{int64(book.ID), book.Title, price, book.Currency, int64(book.Stock), int64(book.Version)}
+}
+
+func (f *fakeDB) exec(state *fakeState, query string, named []driver.NamedValue) ([]string, [][]driver.Value, int64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	args := make([]driver.Value, len(named))
+	for i, arg := range named {
+		args[i] = arg.Value
+	}
+	if f.fail != nil {
+		if err := f.fail(query, args); err != nil {
+			return nil, nil, 0, err
+		}
+	}
+	if state == nil {
+		state = f.committed
+	}
+
+	switch {
+	case fakeSelectBooks.MatchString(query):
+		var result [][]driver.Value
+		for _, arg := range args {
+			if book, ok := state.books[uint(arg.(int64))]; ok {
+				result = append(result, fakeBookRow(book))
+			}
+		}
+		sort.Slice(result, func(i, j int) bool { return result[i][0].(int64) < result[j][0].(int64) })
+		return fakeBookColumns, result, 0, nil
+
+	case fakeSelectStock.MatchString(query):
+		return []string{"stock"}, [][]driver.Value{{int64(state.books[uint(args[0].(int64))].Stock)}}, 0, nil
+
+	case fakeCountBooks.MatchString(query):
+		var count int64
+		if _, ok := state.books[uint(args[0].(int64))]; ok {
+			count = 1
+		}
+		return []string{"count"}, [][]driver.Value{{count}}, 0, nil
+
+	case fakeUpdateStock.MatchString(query):
+		delta, id := int(args[0].(int64)), uint(args[1].(int64))
+		book, ok := state.books[id]
+		if !ok || book.Stock+delta < 0 {
+			return nil, nil, 0, nil
+		}
+		book.Stock += delta
+		book.Version++
+		state.books[id] = book
+		return nil, nil, 1, nil
+
+	case fakeInsert.MatchString(query):
+		m := fakeInsert.FindStringSubmatch(query)
+		table, columns := m[1], fakeColumns(m[2])
+		var returning []string
+		if m[3] != "" {
+			returning = fakeColumns(m[3])
+		}
+		var result [][]driver.Value
+		for start := 0; start+len(columns) <= len(args); start += len(columns) {
+			row := make(map[string]driver.Value, len(columns)+1)
+			for i, column := range columns {
+				row[column] = args[start+i]
+			}
+			// Идентификатор выдается только таблицам, которые его возвращают
+			if _, ok := row["id"]; !ok && len(returning) > 0 && returning[len(returning)-1] == "id" {
+				state.nextID++
+				row["id"] = state.nextID
+			}
+			state.rows[table] = append(state.rows[table], row)
+			values := make([]driver.Value, len(returning))
+			for i, column := range returning {
+				values[i] = row[column]
+			}
+			result = append(result, values)
+		}
+		return returning, result, int64(len(result)), nil
+	}
+	return nil, nil, 0, fmt.Errorf("fakedb: unexpected query %s", query)
+}
+
+func fakeColumns(list string) []string {
+	columns := strings.Split(list, ",")
+	for i, column := range columns {
+		columns[i] = strings.Trim(strings.TrimSpace(column), `"`)
+	}
+	return columns
+}
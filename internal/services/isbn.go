@@ -0,0 +1,142 @@
+package services
+
+import (
+	"errors"
+	"strings"
+)
+
+var (
+	ErrInvalidISBN  = errors.New("invalid ISBN")
+	ErrISBNMismatch = errors.New("ISBN-10 and ISBN-13 refer to different editions")
+)
+
+// NormalizeISBN проверяет контрольную сумму ISBN-10 или ISBN-13 и возвращает канонический ISBN-13.
+// Дефисы и пробелы игнорируются.
+func NormalizeISBN(raw string) (string, error) {
+	isbn := cleanISBN(raw)
+	switch len(isbn) {
+	case 10:
+		if !validISBN10(isbn) {
+			return "", ErrInvalidISBN
+		}
+		return ISBN10To13(isbn), nil
+	case 13:
+		if !validISBN13(isbn) {
+			return "", ErrInvalidISBN
+		}
+		return isbn, nil
+	}
+	return "", ErrInvalidISBN
+}
+
+// ISBN10To13 переводит корректный ISBN-10 в ISBN-13 с префиксом 978.
+func ISBN10To13(isbn10 string) string {
+	body := "978" + isbn10[:9]
+	return body + string(isbn13CheckDigit(body))
+}
+
+// ISBN13To10 переводит ISBN-13 в ISBN-10. Для префикса 979 ISBN-10 не существует.
+func ISBN13To10(isbn13 string) (string, bool) {
+	if !strings.HasPrefix(isbn13, "978") {
+		return "", false
+	}
+	body := isbn13[3:12]
+	return body + string(isbn10CheckDigit(body)), true
+}
+
+// NormalizeBookISBN приводит ISBN книги к каноническому виду: ISBN-13 всегда заполнен,
+// если передан любой из номеров, а ISBN-10 вычисляется из него, когда это возможно.
+func NormalizeBookISBN(book *Book) error {
+	var isbn13, isbn10 string
+	var err error
+
+	if book.ISBN13 != nil && cleanISBN(*book.ISBN13) != "" {
+		if len(cleanISBN(*book.ISBN13)) != 13 {
+			return ErrInvalidISBN
+		}
+		if isbn13, err = NormalizeISBN(*book.ISBN13); err != nil {
+			return err
+		}
+	}
+	if book.ISBN10 != nil && cleanISBN(*book.ISBN10) != "" {
+		if len(cleanISBN(*book.ISBN10)) != 10 {
+			return ErrInvalidISBN
+		}
+		converted, err := NormalizeISBN(*book.ISBN10)
+		if err != nil {
+			return err
+		}
+		if isbn13 != "" && isbn13 != converted {
+			return ErrISBNMismatch
+		}
+		isbn13 = converted
+	}
+
+	if isbn13 == "" {
+		book.ISBN13, book.ISBN10 = nil, nil
+		return nil
+	}
+	book.ISBN13 = &isbn13
+	if converted, ok := ISBN13To10(isbn13); ok {
+		isbn10 = converted
+		book.ISBN10 = &isbn10
+	} else {
+		book.ISBN10 = nil
+	}
+	return nil
+}
+
+func cleanISBN(raw string) string {
+	var b strings.Builder
+	for _, r := range strings.ToUpper(raw) {
+		if (r >= '0' && r <= '9') || r == 'X' {
+			b.WriteRune(r)
+		} else if r != '-' && r != ' ' {
+			// Посторонний символ делает номер заведомо некорректным
+			b.WriteRune('?')
+		}
+	}
+	return b.String()
+}
+
+func validISBN10(isbn string) bool {
+	for i, r := range isbn {
+		if r == 'X' && i != 9 || r == '?' {
+			return false
+		}
+	}
+	return isbn10CheckDigit(isbn[:9]) == isbn[9]
+}
+
+func validISBN13(isbn string) bool {
+	for _, r := range isbn {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return isbn13CheckDigit(isbn[:12]) == isbn[12]
+}
+
+func isbn10CheckDigit(body string) byte {
+	sum := 0
+	for i := 0; i < 9; i++ {
+		sum += int(body[i]-'0') * (10 - i)
+	}
+	check := (11 - sum%11) % 11
+	if check == 10 {
+		return 'X'
+	}
+	return byte('0' + check)
+}
+
+func isbn13CheckDigit(body string) byte {
+	sum := 0
+	for i := 0; i < 12; i++ {
+		weight := 1
+		if i%2 == 1 {
+			weight = 3
+		}
+		sum += int(body[i]-'0') * weight
+	}
+	return byte('0' + (10-sum%10)%10)
+}
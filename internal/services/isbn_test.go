@@ -0,0 +1,94 @@
+package services
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNormalizeISBN(t *testing.T) {
+	tests := []struct {
+		raw, want string
+		err       error
+	}{
+		{"978-0-306-40615-7", "9780306406157", nil},
+		{"978 0 306 40615 7", "9780306406157", nil},
+		{"0-306-40615-2", "9780306406157", nil},
+		{"080442957X", "9780804429573", nil},
+		{"080442957x", "9780804429573", nil},
+		{"9791090636071", "9791090636071", nil},
+		{"978-0-306-40615-8", "", ErrInvalidISBN}, // неверная контрольная цифра
+		{"0-306-40615-3", "", ErrInvalidISBN},
+		{"08044295X7", "", ErrInvalidISBN}, // X только в последней позиции
+		{"978030640615X", "", ErrInvalidISBN},
+		{"0.306.40615.2", "", ErrInvalidISBN}, // посторонние символы
+		{"030640615", "", ErrInvalidISBN},
+		{"", "", ErrInvalidISBN},
+	}
+	for _, tt := range tests {
+		got, err := NormalizeISBN(tt.raw)
+		if !errors.Is(err, tt.err) || got != tt.want {
+			t.Errorf("NormalizeISBN(%q) = %q, %v; want %q, %v", tt.raw, got, err, tt.want, tt.err)
+		}
+	}
+}
+
+func TestISBNConversion(t *testing.T) {
+	if got := ISBN10To13("080442957X"); got != "9780804429573" {
+		t.Errorf("ISBN10To13 = %s", got)
+	}
+	if got, ok := ISBN13To10("9780804429573"); !ok || got != "080442957X" {
+		t.Errorf("ISBN13To10 = %s, %v", got, ok)
+	}
+	if got, ok := ISBN13To10("9780306406157"); !ok || got != "0306406152" {
+		t.Errorf("ISBN13To10 = %s, %v", got, ok)
+	}
+	if _, ok := ISBN13To10("9791090636071"); ok {
+		t.Error("ISBN13To10 must fail for the 979 prefix")
+	}
+}
+
+func TestNormalizeBookISBN(t *testing.T) {
+	str := func(s string) *string { return &s }
+	tests := []struct {
+		name           string
+		isbn13, isbn10 *string
+		want13, want10 *string
+		err            error
+	}{
+		{"empty", nil, nil, nil, nil, nil},
+		{"blank strings", str(""), str(" "), nil, nil, nil},
+		{"from ISBN-10", nil, str("0-306-40615-2"), str("9780306406157"), str("0306406152"), nil},
+		{"from ISBN-13", str("978-0-306-40615-7"), nil, str("9780306406157"), str("0306406152"), nil},
+		{"979 has no ISBN-10", str("9791090636071"), nil, str("9791090636071"), nil, nil},
+		{"both consistent", str("9780306406157"), str("0306406152"), str("9780306406157"), str("0306406152"), nil},
+		{"both different", str("9780306406157"), str("080442957X"), nil, nil, ErrISBNMismatch},
+		{"ISBN-10 in ISBN-13 field", str("0306406152"), nil, nil, nil, ErrInvalidISBN},
+		{"ISBN-13 in ISBN-10 field", nil, str("9780306406157"), nil, nil, ErrInvalidISBN},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			book := &Book{ISBN13: tt.isbn13, ISBN10: tt.isbn10}
+			err := NormalizeBookISBN(book)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("got error %v, want %v", err, tt.err)
+			}
+			if err != nil {
+				return
+			}
+			if !equalStringPtr(book.ISBN13, tt.want13) || !equalStringPtr(book.ISBN10, tt.want10) {
+				t.Errorf("got %v/%v, want %v/%v", deref(book.ISBN13), deref(book.ISBN10), deref(tt.want13), deref(tt.want10))
+			}
+		})
+	}
+}
+
+func equalStringPtr(a, b *string) bool {
+	return a == nil && b == nil || a != nil && b != nil && *a == *b
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return "<nil>"
+	}
+	return *s
+}
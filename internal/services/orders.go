@@ -0,0 +1,172 @@
+package services
+
+import (
+	"Projectmugen/internal/models"
+	"Projectmugen/internal/utils"
+	"errors"
+	"fmt"
+	"sort"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrEmptyOrder      = errors.New("order must contain at least one book")
+	ErrInvalidQuantity = errors.New("invalid quantity")
+	ErrNotForSale      = errors.New("book is not for sale")
+)
+
+// OrderMaxQuantity — максимальное количество экземпляров одной книги в заказе.
+var OrderMaxQuantity = utils.GetEnvInt("ORDER_MAX_QUANTITY", 100)
+
+// OrderLineError — ошибка в строке заказа с указанием книги.
+type OrderLineError struct {
+	BookID int
+	Err    error
+}
+
+func (e *OrderLineError) Error() string {
+	return fmt.Sprintf("book %d: %v", e.BookID, e.Err)
+}
+
+func (e *OrderLineError) Unwrap() error {
+	return e.Err
+}
+
+// mergeOrderLines проверяет количества и объединяет повторяющиеся книги. Строки упорядочены
+// по идентификатору книги, чтобы параллельные заказы блокировали книги в одном порядке.
+func mergeOrderLines(products []models.ProductInOrder) ([]models.ProductInOrder, error) {
+	if len(products) == 0 {
+		return nil, ErrEmptyOrder
+	}
+	quantities := make(map[int]int)
+	for _, product := range products {
+		if product.Quantity <= 0 {
+			return nil, &OrderLineError{BookID: product.ProductID, Err: ErrInvalidQuantity}
+		}
+		quantities[product.ProductID] += product.Quantity
+		if quantities[product.ProductID] > OrderMaxQuantity {
+			return nil, &OrderLineError{BookID: product.ProductID, Err: ErrInvalidQuantity}
+		}
+	}
+	lines := make([]models.ProductInOrder, 0, len(quantities))
+	for id, quantity := range quantities {
+		lines = append(lines, models.ProductInOrder{ProductID: id, Quantity: quantity})
+	}
+	sort.Slice(lines, func(i, j int) bool { return lines[i].ProductID < lines[j].ProductID })
+	return lines, nil
+}
+
+// PlaceOrder оформляет заказ пользователя в одной транзакции: блокирует книги, проверяет
+// наличие, фиксирует цены в валюте currency по курсам rates и списывает остатки через журнал
+// склада. Заказ создается в статусе pending; право на скачивание электронных версий выдается
+// только после оплаты. Если хотя бы одной книги не хватает, заказ не создается.
+func PlaceOrder(username string, products []models.ProductInOrder, currency string, rates Rates) (*models.Order, error) {
+	var order *models.Order
+	err := Db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		order, err = placeOrder(tx, username, products, currency, rates)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return order, nil
+}
+
+// placeOrder оформляет заказ внутри транзакции tx; см. PlaceOrder.
+func placeOrder(tx *gorm.DB, username string, products []models.ProductInOrder, currency string, rates Rates) (*models.Order, error) {
+	lines, err := mergeOrderLines(products)
+	if err != nil {
+		return nil, err
+	}
+	exchangeRate, err := rates.Rate(DefaultCurrency, currency)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]int, len(lines))
+	for i, line := range lines {
+		ids[i] = line.ProductID
+	}
+	var books []Book
+	err = tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id IN ?", ids).
+		Order("id asc").
+		Find(&books).Error
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[int]*Book, len(books))
+	for i := range books {
+		byID[int(books[i].ID)] = &books[i]
+	}
+
+	order := models.Order{Username: username, Currency: currency, ExchangeRate: exchangeRate, Status: models.OrderPending}
+	for _, line := range lines {
+		book, ok := byID[line.ProductID]
+		if !ok {
+			return nil, &OrderLineError{BookID: line.ProductID, Err: gorm.ErrRecordNotFound}
+		}
+		if book.Price == nil {
+			return nil, &OrderLineError{BookID: line.ProductID, Err: ErrNotForSale}
+		}
+		if book.Stock < line.Quantity {
+			return nil, &OrderLineError{BookID: line.ProductID, Err: ErrInsufficientStock}
+		}
+		unitPrice, _, err := rates.Convert(*book.Price, book.Currency, currency)
+		if err != nil {
+			return nil, &OrderLineError{BookID: line.ProductID, Err: err}
+		}
+		order.Products = append(order.Products, models.OrderProduct{
+			ProductID: line.ProductID,
+			Title:     book.Title,
+			Quantity:  line.Quantity,
+			UnitPrice: unitPrice,
+			LineTotal: unitPrice * int64(line.Quantity),
+		})
+		order.Total += unitPrice * int64(line.Quantity)
+	}
+
+	if err := tx.Omit(clause.Associations).Create(&order).Error; err != nil {
+		return nil, err
+	}
+	for i := range order.Products {
+		order.Products[i].OrderID = order.ID
+	}
+	if err := tx.Omit(clause.Associations).Create(&order.Products).Error; err != nil {
+		return nil, err
+	}
+	change := models.OrderStatusChange{OrderID: order.ID, To: models.OrderPending, Username: username}
+	if err := tx.Create(&change).Error; err != nil {
+		return nil, err
+	}
+	order.History = []models.OrderStatusChange{change}
+	orderID := uint(order.ID)
+	for _, line := range order.Products {
+		if _, err := AdjustStock(tx, uint(line.ProductID), -line.Quantity, StockOrder, username, "", &orderID); err != nil {
+			return nil, &OrderLineError{BookID: line.ProductID, Err: err}
+		}
+	}
+	return &order, nil
+}
+
+// orderLineProducts загружает книги строк заказа в поле OrderProduct.Product. Строки заказа
+// ссылаются на книги, поэтому товар читается из books, включая удаленные книги: название
+// и описание текущие, цена — текущая цена каталога в валюте книги, производитель — издатель.
+func orderLineProducts(db *gorm.DB) *gorm.DB {
+	return db.Table("books").
+		Select(`books.id, books.title AS name, books.description,
+			COALESCE(books.category_id, 0) AS category_id, COALESCE(books.price, 0) AS price, books.currency,
+			COALESCE(publishers.name, '') AS manufacturer,
+			COALESCE((SELECT AVG(rating) FROM reviews WHERE reviews.product_id = books.id), 0) AS rating`).
+		Joins("LEFT JOIN publishers ON publishers.id = books.publisher_id")
+}
+
+// WithOrderDetails добавляет к запросу заказов загрузку строк с книгами и истории статусов.
+func WithOrderDetails(db *gorm.DB) *gorm.DB {
+	return db.Preload("Products", func(tx *gorm.DB) *gorm.DB { return tx.Order("product_id asc") }).
+		Preload("Products.Product", orderLineProducts).
+		Preload("History", func(tx *gorm.DB) *gorm.DB { return tx.Order("created_at asc, id asc") })
+}
@@ -0,0 +1,154 @@
+package services
+
+import (
+	"Projectmugen/internal/models"
+	"database/sql/driver"
+	"errors"
+	"strings"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+func testOrderBooks() []Book {
+	rub, eur := int64(100000), int64(1000)
+	return []Book{
+		{ID: 1, Title: "Война и мир", Price: &rub, Currency: "RUB", Stock: 5, Version: 1},
+		{ID: 2, Title: "Faust", Price: &eur, Currency: "EUR", Stock: 1, Version: 1},
+		{ID: 3, Title: "Черновик", Currency: "RUB", Stock: 10, Version: 1},
+	}
+}
+
+// assertNothingWritten проверяет, что неудачный заказ не изменил ни остатки, ни таблицы.
+func assertNothingWritten(t *testing.T, f *fakeDB) {
+	t.Helper()
+	for _, book := range testOrderBooks() {
+		if got := f.book(book.ID); got.Stock != book.Stock || got.Version != book.Version {
+			t.Errorf("book %d: stock %d, version %d; want %d, %d", book.ID, got.Stock, got.Version, book.Stock, book.Version)
+		}
+	}
+	for _, table := range []string{"orders", "order_products", "order_status_changes", "stock_movements"} {
+		if rows := f.rows(table); len(rows) != 0 {
+			t.Errorf("%s: %d rows written", table, len(rows))
+		}
+	}
+}
+
+func assertLineError(t *testing.T, err error, bookID int, want error) {
+	t.Helper()
+	var lineErr *OrderLineError
+	if !errors.As(err, &lineErr) || lineErr.BookID != bookID || !errors.Is(err, want) {
+		t.Fatalf("got %v, want book %d: %v", err, bookID, want)
+	}
+}
+
+func TestPlaceOrderUnknownBook(t *testing.T) {
+	f := newFakeDB(t, testOrderBooks()...)
+	_, err := PlaceOrder("alice", []models.ProductInOrder{{ProductID: 1, Quantity: 1}, {ProductID: 42, Quantity: 1}}, "RUB", testRates())
+	assertLineError(t, err, 42, gorm.ErrRecordNotFound)
+	assertNothingWritten(t, f)
+}
+
+func TestPlaceOrderNotForSale(t *testing.T) {
+	f := newFakeDB(t, testOrderBooks()...)
+	_, err := PlaceOrder("alice", []models.ProductInOrder{{ProductID: 3, Quantity: 1}}, "RUB", testRates())
+	assertLineError(t, err, 3, ErrNotForSale)
+	assertNothingWritten(t, f)
+}
+
+func TestPlaceOrderQuantityOutOfRange(t *testing.T) {
+	tests := []struct {
+		name     string
+		products []models.ProductInOrder
+		bookID   int
+	}{
+		{"zero", []models.ProductInOrder{{ProductID: 1, Quantity: 0}}, 1},
+		{"negative", []models.ProductInOrder{{ProductID: 1, Quantity: 1}, {ProductID: 2, Quantity: -1}}, 2},
+		{"above maximum", []models.ProductInOrder{{ProductID: 1, Quantity: OrderMaxQuantity + 1}}, 1},
+		// Повторяющиеся строки складываются до проверки максимума
+		{"repeated lines above maximum", []models.ProductInOrder{{ProductID: 1, Quantity: OrderMaxQuantity}, {ProductID: 1, Quantity: 1}}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFakeDB(t, testOrderBooks()...)
+			_, err := PlaceOrder("alice", tt.products, "RUB", testRates())
+			assertLineError(t, err, tt.bookID, ErrInvalidQuantity)
+			assertNothingWritten(t, f)
+		})
+	}
+
+	f := newFakeDB(t, testOrderBooks()...)
+	if _, err := PlaceOrder("alice", nil, "RUB", testRates()); !errors.Is(err, ErrEmptyOrder) {
+		t.Errorf("empty order: got %v, want ErrEmptyOrder", err)
+	}
+	assertNothingWritten(t, f)
+}
+
+func TestPlaceOrderInsufficientStock(t *testing.T) {
+	f := newFakeDB(t, testOrderBooks()...)
+	_, err := PlaceOrder("alice", []models.ProductInOrder{{ProductID: 1, Quantity: 2}, {ProductID: 2, Quantity: 2}}, "RUB", testRates())
+	assertLineError(t, err, 2, ErrInsufficientStock)
+	assertNothingWritten(t, f)
+}
+
+// TestPlaceOrderRollback проверяет, что ошибка после записи заказа и списания первой
+// книги откатывает всю транзакцию.
+func TestPlaceOrderRollback(t *testing.T) {
+	f := newFakeDB(t, testOrderBooks()...)
+	failure := errors.New("disk full")
+	f.fail = func(query string, args []driver.Value) error {
+		if strings.HasPrefix(query, `INSERT INTO "stock_movements"`) && args[0] == int64(2) {
+			return failure
+		}
+		return nil
+	}
+	_, err := PlaceOrder("alice", []models.ProductInOrder{{ProductID: 1, Quantity: 2}, {ProductID: 2, Quantity: 1}}, "RUB", testRates())
+	assertLineError(t, err, 2, failure)
+	assertNothingWritten(t, f)
+}
+
+func TestPlaceOrderTotalsInRequestedCurrency(t *testing.T) {
+	f := newFakeDB(t, testOrderBooks()...)
+	order, err := PlaceOrder("alice", []models.ProductInOrder{{ProductID: 2, Quantity: 1}, {ProductID: 1, Quantity: 2}}, "USD", testRates())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 1000,00 RUB = 11,11 USD -> 11,00; 10,00 EUR = 10,94 USD -> 11,00 (шаг USD — целые доллары)
+	want := []models.OrderProduct{
+		{OrderID: order.ID, ProductID: 1, Title: "Война и мир", Quantity: 2, UnitPrice: 1100, LineTotal: 2200},
+		{OrderID: order.ID, ProductID: 2, Title: "Faust", Quantity: 1, UnitPrice: 1100, LineTotal: 1100},
+	}
+	if order.Currency != "USD" || order.Total != 3300 || order.Status != models.OrderPending {
+		t.Errorf("order = %s %d %s, want USD 3300 pending", order.Currency, order.Total, order.Status)
+	}
+	if rate, _ := testRates().Rate(DefaultCurrency, "USD"); order.ExchangeRate != rate {
+		t.Errorf("exchange rate = %v, want %v", order.ExchangeRate, rate)
+	}
+	if len(order.Products) != len(want) {
+		t.Fatalf("got %d lines, want %d", len(order.Products), len(want))
+	}
+	for i, line := range order.Products {
+		if line != want[i] {
+			t.Errorf("line %d = %+v, want %+v", i, line, want[i])
+		}
+	}
+
+	// Цены зафиксированы в строках заказа, остатки списаны через журнал склада
+	orders, lines := f.rows("orders"), f.rows("order_products")
+	if len(orders) != 1 || orders[0]["total"] != int64(3300) || orders[0]["currency"] != "USD" {
+		t.Errorf("stored orders = %v", orders)
+	}
+	if len(lines) != 2 || lines[0]["unit_price"] != int64(1100) || lines[1]["line_total"] != int64(1100) {
+		t.Errorf("stored lines = %v", lines)
+	}
+	if got := f.book(1).Stock; got != 3 {
+		t.Errorf("book 1 stock = %d, want 3", got)
+	}
+	if got := f.book(2).Stock; got != 0 {
+		t.Errorf("book 2 stock = %d, want 0", got)
+	}
+	if movements := f.rows("stock_movements"); len(movements) != 2 {
+		t.Errorf("got %d stock movements, want 2", len(movements))
+	}
+}
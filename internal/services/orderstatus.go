@@ -0,0 +1,181 @@
+package services
+
+import (
+	"Projectmugen/internal/models"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrInvalidTransition   = errors.New("invalid order status transition")
+	ErrTransitionForbidden = errors.New("order status change is not allowed")
+)
+
+// Кто может выполнить переход статуса: владелец заказа или сотрудник магазина (роль admin).
+const (
+	orderOwner = "owner"
+	orderStaff = "admin"
+)
+
+// VoidedOrderStatuses — статусы заказов, которые не считаются покупками в рекомендациях.
+var VoidedOrderStatuses = []string{models.OrderCancelled, models.OrderRefunded}
+
+// orderTransitions — допустимые переходы статусов заказа и кто может их выполнить.
+// Отмененный и возвращенный заказы больше не меняются.
+var orderTransitions = map[string]map[string][]string{
+	models.OrderPending: {
+		models.OrderPaid:      {orderStaff},
+		models.OrderCancelled: {orderOwner, orderStaff},
+	},
+	models.OrderPaid: {
+		models.OrderPacked:   {orderStaff},
+		models.OrderRefunded: {orderStaff},
+	},
+	models.OrderPacked: {
+		models.OrderShipped:  {orderStaff},
+		models.OrderRefunded: {orderStaff},
+	},
+	models.OrderShipped: {
+		models.OrderDelivered: {orderStaff},
+	},
+	models.OrderDelivered: {
+		models.OrderRefunded: {orderStaff},
+	},
+}
+
+// orderStatusColumns — поля времени перехода в каждый статус.
+var orderStatusColumns = map[string]string{
+	models.OrderPaid:      "paid_at",
+	models.OrderPacked:    "packed_at",
+	models.OrderShipped:   "shipped_at",
+	models.OrderDelivered: "delivered_at",
+	models.OrderCancelled: "cancelled_at",
+	models.OrderRefunded:  "refunded_at",
+}
+
+// IsOrderStatus сообщает, что status — известный статус заказа.
+func IsOrderStatus(status string) bool {
+	_, ok := orderStatusColumns[status]
+	return ok || status == models.OrderPending
+}
+
+// canTransition сообщает, может ли пользователь с ролью role перевести заказ в статус to.
+// Владелец заказа выполняет только переходы, разрешенные владельцу, даже если он сотрудник.
+func canTransition(order *models.Order, to, username, role string) (bool, error) {
+	actors, ok := orderTransitions[order.Status][to]
+	if !ok {
+		return false, fmt.Errorf("%w: %s -> %s", ErrInvalidTransition, order.Status, to)
+	}
+	if order.Username == username {
+		return slices.Contains(actors, orderOwner), nil
+	}
+	return role == orderStaff && slices.Contains(actors, orderStaff), nil
+}
+
+// TransitionOrder переводит заказ в статус to от имени пользователя username с ролью role,
+// проверяя, что переход допустим и доступен пользователю. Время перехода и запись истории
+// сохраняются в той же транзакции. При оплате покупатель получает право скачивать электронные
+// версии купленных книг. При отмене или возврате заказа, который еще не отправлен, книги
+// возвращаются на склад; право на скачивание купленных по заказу электронных версий отзывается. Чужие заказы для пользователя без роли admin не существуют (gorm.ErrRecordNotFound).
+func TransitionOrder(orderID int, to, username, role, note string) (*models.Order, error) {
+	var order models.Order
+	err := Db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Preload("Products").
+			First(&order, orderID).Error
+		if err != nil {
+			return err
+		}
+		if order.Username != username && role != orderStaff {
+			return gorm.ErrRecordNotFound
+		}
+		allowed, err := canTransition(&order, to, username, role)
+		if err != nil {
+			return err
+		}
+		if !allowed {
+			return ErrTransitionForbidden
+		}
+
+		from := order.Status
+		now := time.Now()
+		err = tx.Model(&order).Updates(map[string]interface{}{"status": to, orderStatusColumns[to]: now}).Error
+		if err != nil {
+			return err
+		}
+		change := models.OrderStatusChange{OrderID: order.ID, From: from, To: to, Username: username, Note: note}
+		if err := tx.Create(&change).Error; err != nil {
+			return err
+		}
+
+		id := uint(order.ID)
+		effects := transitionEffects(from, to)
+		if effects.grant && len(order.Products) > 0 {
+			if err := tx.Create(purchaseEntitlements(&order)).Error; err != nil {
+				return err
+			}
+		}
+		// Мягко удаленные книги тоже возвращаются на склад, окончательно удаленные пропускаются
+		if effects.restock {
+			for _, line := range order.Products {
+				_, err := AdjustStock(tx.Unscoped(), uint(line.ProductID), line.Quantity, StockOrderUndo, username, note, &id)
+				if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+					return err
+				}
+			}
+		}
+		if effects.revoke {
+			return tx.Where("order_id = ? AND source = ?", id, EntitlementPurchase).Delete(&BookEntitlement{}).Error
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	var updated models.Order
+	err = WithOrderDetails(Db).First(&updated, orderID).Error
+	if err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+// orderEffects — побочные эффекты перехода статуса заказа.
+type orderEffects struct {
+	grant   bool // выдать право на скачивание купленных книг
+	restock bool // вернуть книги на склад
+	revoke  bool // отозвать право на скачивание, выданное по заказу
+}
+
+// transitionEffects возвращает побочные эффекты перехода from -> to: оплата выдает право на
+// скачивание, отмена и возврат отзывают его, а книги неполученного заказа возвращаются на склад.
+func transitionEffects(from, to string) orderEffects {
+	voided := slices.Contains(VoidedOrderStatuses, to)
+	return orderEffects{
+		grant:   to == models.OrderPaid,
+		restock: voided && from != models.OrderDelivered,
+		revoke:  voided,
+	}
+}
+
+// purchaseEntitlements возвращает права на скачивание книг оплаченного заказа для его владельца.
+func purchaseEntitlements(order *models.Order) []BookEntitlement {
+	id := uint(order.ID)
+	entitlements := make([]BookEntitlement, len(order.Products))
+	for i, line := range order.Products {
+		entitlements[i] = BookEntitlement{Username: order.Username, BookID: uint(line.ProductID), Source: EntitlementPurchase, OrderID: &id}
+	}
+	return entitlements
+}
+
+// OrderHistory возвращает историю статусов заказа от первой записи к последней.
+func OrderHistory(orderID int) ([]models.OrderStatusChange, error) {
+	history := []models.OrderStatusChange{}
+	err := Db.Where("order_id = ?", orderID).Order("created_at asc, id asc").Find(&history).Error
+	return history, err
+}
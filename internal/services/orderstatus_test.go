@@ -0,0 +1,124 @@
+package services
+
+import (
+	"Projectmugen/internal/models"
+	"errors"
+	"testing"
+)
+
+var allOrderStatuses = []string{
+	models.OrderPending, models.OrderPaid, models.OrderPacked, models.OrderShipped,
+	models.OrderDelivered, models.OrderCancelled, models.OrderRefunded,
+}
+
+func TestCanTransition(t *testing.T) {
+	// Для каждого допустимого перехода — кто может его выполнить
+	type allowed struct{ owner, staff bool }
+	valid := map[[2]string]allowed{
+		{models.OrderPending, models.OrderPaid}:       {staff: true},
+		{models.OrderPending, models.OrderCancelled}:  {owner: true, staff: true},
+		{models.OrderPaid, models.OrderPacked}:        {staff: true},
+		{models.OrderPaid, models.OrderRefunded}:      {staff: true},
+		{models.OrderPacked, models.OrderShipped}:     {staff: true},
+		{models.OrderPacked, models.OrderRefunded}:    {staff: true},
+		{models.OrderShipped, models.OrderDelivered}:  {staff: true},
+		{models.OrderDelivered, models.OrderRefunded}: {staff: true},
+	}
+	actors := []struct {
+		name, username, role string
+		want                 func(allowed) bool
+	}{
+		{"owner", "alice", "user", func(a allowed) bool { return a.owner }},
+		{"staff", "bob", "admin", func(a allowed) bool { return a.staff }},
+		// Сотрудник со своим заказом действует как владелец
+		{"staff owner", "alice", "admin", func(a allowed) bool { return a.owner }},
+		{"stranger", "carol", "user", func(allowed) bool { return false }},
+	}
+
+	for _, from := range allOrderStatuses {
+		for _, to := range allOrderStatuses {
+			rule, ok := valid[[2]string{from, to}]
+			for _, actor := range actors {
+				order := &models.Order{Username: "alice", Status: from}
+				got, err := canTransition(order, to, actor.username, actor.role)
+				if !ok {
+					if !errors.Is(err, ErrInvalidTransition) {
+						t.Errorf("%s -> %s by %s: got %v, want ErrInvalidTransition", from, to, actor.name, err)
+					}
+					continue
+				}
+				if err != nil {
+					t.Errorf("%s -> %s by %s: unexpected error %v", from, to, actor.name, err)
+					continue
+				}
+				if want := actor.want(rule); got != want {
+					t.Errorf("%s -> %s by %s: got %v, want %v", from, to, actor.name, got, want)
+				}
+			}
+		}
+	}
+}
+
+func TestFinalOrderStatuses(t *testing.T) {
+	for _, status := range VoidedOrderStatuses {
+		if next := orderTransitions[status]; len(next) != 0 {
+			t.Errorf("%s must be final, has transitions %v", status, next)
+		}
+	}
+}
+
+func TestIsOrderStatus(t *testing.T) {
+	for _, status := range allOrderStatuses {
+		if !IsOrderStatus(status) {
+			t.Errorf("IsOrderStatus(%q) = false", status)
+		}
+		if _, ok := orderStatusColumns[status]; !ok && status != models.OrderPending {
+			t.Errorf("%s has no timestamp column", status)
+		}
+	}
+	for _, status := range []string{"", "PAID", "returned"} {
+		if IsOrderStatus(status) {
+			t.Errorf("IsOrderStatus(%q) = true", status)
+		}
+	}
+}
+
+func TestTransitionEffects(t *testing.T) {
+	tests := []struct {
+		from, to string
+		want     orderEffects
+	}{
+		{models.OrderPending, models.OrderPaid, orderEffects{grant: true}},
+		{models.OrderPending, models.OrderCancelled, orderEffects{restock: true, revoke: true}},
+		{models.OrderPaid, models.OrderPacked, orderEffects{}},
+		{models.OrderPaid, models.OrderRefunded, orderEffects{restock: true, revoke: true}},
+		{models.OrderPacked, models.OrderShipped, orderEffects{}},
+		{models.OrderPacked, models.OrderRefunded, orderEffects{restock: true, revoke: true}},
+		{models.OrderShipped, models.OrderDelivered, orderEffects{}},
+		// Полученные книги на склад не возвращаются
+		{models.OrderDelivered, models.OrderRefunded, orderEffects{revoke: true}},
+	}
+	for _, tt := range tests {
+		if got := transitionEffects(tt.from, tt.to); got != tt.want {
+			t.Errorf("%s -> %s: got %+v, want %+v", tt.from, tt.to, got, tt.want)
+		}
+	}
+}
+
+func TestPurchaseEntitlements(t *testing.T) {
+	order := &models.Order{ID: 42, Username: "alice", Products: []models.OrderProduct{
+		{ProductID: 1, Quantity: 2},
+		{ProductID: 7, Quantity: 1},
+	}}
+	got := purchaseEntitlements(order)
+	if len(got) != 2 {
+		t.Fatalf("got %d entitlements, want 2", len(got))
+	}
+	for i, entitlement := range got {
+		if entitlement.Username != "alice" || entitlement.Source != EntitlementPurchase ||
+			entitlement.BookID != uint(order.Products[i].ProductID) ||
+			entitlement.OrderID == nil || *entitlement.OrderID != 42 || entitlement.ExpiresAt != nil {
+			t.Errorf("entitlement %d = %+v", i, entitlement)
+		}
+	}
+}
@@ -0,0 +1,367 @@
+package services
+
+import (
+	"Projectmugen/internal/utils"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Сигналы интереса пользователя к книге, на которых строятся персональные рекомендации.
+const (
+	SignalBought   = "bought"
+	SignalWishlist = "wishlist"
+	SignalLiked    = "liked"    // отзыв с оценкой 4 и выше
+	SignalDisliked = "disliked" // отзыв с оценкой 2 и ниже
+	SignalReviewed = "reviewed"
+)
+
+// ReasonPopular — рекомендация для пользователя без истории: популярные книги в наличии.
+const ReasonPopular = "popular"
+
+// Вес сигнала: покупка говорит об интересе сильнее, чем список желаний; плохой отзыв отталкивает.
+var signalWeights = map[string]float64{
+	SignalBought:   3,
+	SignalWishlist: 2,
+	SignalLiked:    2,
+	SignalReviewed: 0.5,
+	SignalDisliked: -1,
+}
+
+// Вклад сходства по содержанию относительно совместных покупок.
+const (
+	sameAuthorWeight   = 0.5
+	sameCategoryWeight = 0.2
+)
+
+// UserRecommendationsInterval — период пересчета персональных рекомендаций.
+var UserRecommendationsInterval = utils.GetEnvDuration("USER_RECOMMENDATIONS_INTERVAL", time.Hour)
+
+// UserRecommendationsPerUser — сколько рекомендаций сохраняется для каждого пользователя.
+var UserRecommendationsPerUser = utils.GetEnvInt("USER_RECOMMENDATIONS_PER_USER", 50)
+
+// UserRecommendation — предвычисленная персональная рекомендация. BecauseBookID указывает
+// на книгу из истории пользователя, сильнее всего повлиявшую на рекомендацию, а Signal —
+// на то, как пользователь с ней связан (купил, добавил в желаемое, оценил).
+type UserRecommendation struct {
+	Username      string    `gorm:"primaryKey" json:"-"`
+	BookID        uint      `gorm:"primaryKey" json:"book_id"`
+	Score         float64   `json:"score"`
+	Reason        string    `json:"reason"`
+	Signal        string    `json:"signal"`
+	BecauseBookID *uint     `json:"because_book_id,omitempty"`
+	ComputedAt    time.Time `json:"computed_at"`
+}
+
+type userSignal struct {
+	Username string
+	BookID   uint
+	Kind     string
+}
+
+type candidateScore struct {
+	score        float64
+	reason       string
+	signal       string
+	because      uint
+	contribution float64
+}
+
+func (c *candidateScore) add(value float64, reason, signal string, because uint) {
+	c.score += value
+	// Объяснение берем от сигнала с наибольшим положительным вкладом
+	if value > c.contribution {
+		c.contribution = value
+		c.reason, c.signal, c.because = reason, signal, because
+	}
+}
+
+// RecomputeUserRecommendations пересчитывает персональные рекомендации всех пользователей,
+// у которых есть заказы, список желаний или отзывы. Книги ранжируются по совместным
+// покупкам с книгами из истории пользователя (also_boughts) и по общим авторам и рубрикам.
+// Книги, с которыми пользователь уже взаимодействовал, не рекомендуются.
+func RecomputeUserRecommendations() (int, error) {
+	var signals []userSignal
+	err := Db.Raw(`
+		SELECT o.username, op.product_id AS book_id, ? AS kind
+			FROM order_products op JOIN orders o ON o.id = op.order_id
+			WHERE o.username <> '' AND o.status NOT IN ?
+		UNION SELECT username, book_id, ? FROM wishlist_items
+		UNION SELECT username, product_id,
+			CASE WHEN rating >= 4 THEN ? WHEN rating <= 2 THEN ? ELSE ? END
+			FROM reviews WHERE username <> ''`,
+		SignalBought, VoidedOrderStatuses, SignalWishlist, SignalLiked, SignalDisliked, SignalReviewed).
+		Scan(&signals).Error
+	if err != nil {
+		return 0, err
+	}
+
+	byUser := make(map[string][]userSignal)
+	seedSet := make(map[uint]bool)
+	for _, s := range signals {
+		byUser[s.Username] = append(byUser[s.Username], s)
+		seedSet[s.BookID] = true
+	}
+	seeds := make([]uint, 0, len(seedSet))
+	for id := range seedSet {
+		seeds = append(seeds, id)
+	}
+
+	related, byAuthor, byCategory, err := loadSimilarBooks(seeds)
+	if err != nil {
+		return 0, err
+	}
+
+	now := time.Now()
+	var rows []UserRecommendation
+	for username, userSignals := range byUser {
+		seen := make(map[uint]bool, len(userSignals))
+		for _, s := range userSignals {
+			seen[s.BookID] = true
+		}
+
+		candidates := make(map[uint]*candidateScore)
+		score := func(bookID uint, value float64, reason string, s userSignal) {
+			if seen[bookID] {
+				return
+			}
+			c, ok := candidates[bookID]
+			if !ok {
+				c = &candidateScore{}
+				candidates[bookID] = c
+			}
+			c.add(value, reason, s.Kind, s.BookID)
+		}
+		for _, s := range userSignals {
+			weight := signalWeights[s.Kind]
+			for _, pair := range related[s.BookID] {
+				score(pair.RelatedID, weight*pair.Score, ReasonAlsoBought, s)
+			}
+			for _, id := range byAuthor[s.BookID] {
+				score(id, weight*sameAuthorWeight, ReasonSameAuthor, s)
+			}
+			for _, id := range byCategory[s.BookID] {
+				score(id, weight*sameCategoryWeight, ReasonSameCategory, s)
+			}
+		}
+
+		ranked := make([]UserRecommendation, 0, len(candidates))
+		for bookID, c := range candidates {
+			if c.score <= 0 || c.reason == "" {
+				continue
+			}
+			because := c.because
+			ranked = append(ranked, UserRecommendation{
+				Username:      username,
+				BookID:        bookID,
+				Score:         c.score,
+				Reason:        c.reason,
+				Signal:        c.signal,
+				BecauseBookID: &because,
+				ComputedAt:    now,
+			})
+		}
+		sort.Slice(ranked, func(i, j int) bool {
+			if ranked[i].Score != ranked[j].Score {
+				return ranked[i].Score > ranked[j].Score
+			}
+			return ranked[i].BookID < ranked[j].BookID
+		})
+		if len(ranked) > UserRecommendationsPerUser {
+			ranked = ranked[:UserRecommendationsPerUser]
+		}
+		rows = append(rows, ranked...)
+	}
+
+	err = Db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("DELETE FROM user_recommendations").Error; err != nil {
+			return err
+		}
+		if len(rows) == 0 {
+			return nil
+		}
+		return tx.CreateInBatches(rows, 500).Error
+	})
+	return len(byUser), err
+}
+
+// loadSimilarBooks загружает для книг seeds пары совместных покупок, книги тех же авторов
+// и книги тех же рубрик. Удаленные книги в кандидаты не попадают.
+func loadSimilarBooks(seeds []uint) (map[uint][]AlsoBought, map[uint][]uint, map[uint][]uint, error) {
+	related := make(map[uint][]AlsoBought)
+	byAuthor := make(map[uint][]uint)
+	byCategory := make(map[uint][]uint)
+	if len(seeds) == 0 {
+		return related, byAuthor, byCategory, nil
+	}
+
+	var pairs []AlsoBought
+	err := Db.Joins("JOIN books ON books.id = also_boughts.related_id AND books.deleted_at IS NULL").
+		Where("also_boughts.book_id IN ?", seeds).Find(&pairs).Error
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	for _, pair := range pairs {
+		related[pair.BookID] = append(related[pair.BookID], pair)
+	}
+
+	type link struct {
+		SeedID  uint
+		OtherID uint
+	}
+	var authorLinks []link
+	err = Db.Raw(`
+		SELECT DISTINCT own.book_id AS seed_id, other.book_id AS other_id
+		FROM book_authors own
+		JOIN book_authors other ON other.author_id = own.author_id AND other.book_id <> own.book_id
+		JOIN books ON books.id = other.book_id AND books.deleted_at IS NULL
+		WHERE own.book_id IN ?`, seeds).Scan(&authorLinks).Error
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	for _, l := range authorLinks {
+		byAuthor[l.SeedID] = append(byAuthor[l.SeedID], l.OtherID)
+	}
+
+	// Рубрики бывают большими, поэтому от каждой берем ограниченное число книг, начиная с тех, что в наличии
+	var categoryLinks []link
+	err = Db.Raw(`
+		SELECT seed.id AS seed_id, other.id AS other_id
+		FROM books seed
+		JOIN LATERAL (
+			SELECT id FROM books
+			WHERE category_id = seed.category_id AND id <> seed.id AND deleted_at IS NULL
+			ORDER BY stock > 0 DESC, id DESC LIMIT 100
+		) other ON true
+		WHERE seed.id IN ? AND seed.category_id IS NOT NULL`, seeds).Scan(&categoryLinks).Error
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	for _, l := range categoryLinks {
+		byCategory[l.SeedID] = append(byCategory[l.SeedID], l.OtherID)
+	}
+	return related, byAuthor, byCategory, nil
+}
+
+// StartUserRecommendationsJob запускает фоновый пересчет персональных рекомендаций
+// с периодом UserRecommendationsInterval.
+func StartUserRecommendationsJob() {
+	go func() {
+		ticker := time.NewTicker(UserRecommendationsInterval)
+		defer ticker.Stop()
+		for {
+			users, err := RecomputeUserRecommendations()
+			if err != nil {
+				log.Println("user recommendations:", err)
+			} else {
+				log.Printf("user recommendations: computed for %d users", users)
+			}
+			<-ticker.C
+		}
+	}()
+}
+
+// PopularBooks возвращает книги в наличии, которые чаще всего заказывают, — рекомендации
+// для пользователей, по которым еще нечего посчитать. Книги из exclude пропускаются.
+func PopularBooks(limit int, exclude []uint) ([]Book, error) {
+	query := Db.Model(&Book{}).
+		Joins(`LEFT JOIN (SELECT op.product_id, COUNT(DISTINCT op.order_id) AS orders FROM order_products op
+			JOIN orders o ON o.id = op.order_id WHERE o.status NOT IN ? GROUP BY op.product_id) sales ON sales.product_id = books.id`, VoidedOrderStatuses).
+		Where("books.stock > 0")
+	if len(exclude) > 0 {
+		query = query.Where("books.id NOT IN ?", exclude)
+	}
+	var books []Book
+	err := query.Preload("Cover").
+		Order("COALESCE(sales.orders, 0) DESC, books.id DESC").
+		Limit(limit).
+		Find(&books).Error
+	return books, err
+}
+
+// UserRecommendations возвращает сохраненные персональные рекомендации пользователя с объяснениями.
+// Если для пользователя еще ничего не посчитано, возвращаются популярные книги.
+func UserRecommendations(username string, limit int) ([]Recommendation, error) {
+	var rows []UserRecommendation
+	err := Db.Joins("JOIN books ON books.id = user_recommendations.book_id AND books.deleted_at IS NULL").
+		Where("user_recommendations.username = ?", username).
+		Order("score desc, book_id asc").
+		Limit(limit).
+		Find(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	recommendations := []Recommendation{}
+	if len(rows) == 0 {
+		// Как и в основном пути, книги, с которыми пользователь уже взаимодействовал, пропускаются
+		var seen []uint
+		err := Db.Raw(`
+			SELECT op.product_id FROM order_products op JOIN orders o ON o.id = op.order_id
+				WHERE o.username = ? AND o.status NOT IN ?
+			UNION SELECT book_id FROM wishlist_items WHERE username = ?
+			UNION SELECT product_id FROM reviews WHERE username = ?`,
+			username, VoidedOrderStatuses, username, username).Scan(&seen).Error
+		if err != nil {
+			return nil, err
+		}
+		books, err := PopularBooks(limit, seen)
+		if err != nil {
+			return nil, err
+		}
+		for _, book := range books {
+			recommendations = append(recommendations, Recommendation{Book: book, Reason: ReasonPopular, Explanation: "Popular with other readers"})
+		}
+		return recommendations, nil
+	}
+
+	ids := make([]uint, 0, len(rows)*2)
+	for _, row := range rows {
+		ids = append(ids, row.BookID)
+		if row.BecauseBookID != nil {
+			ids = append(ids, *row.BecauseBookID)
+		}
+	}
+	var books []Book
+	if err := Db.Unscoped().Preload("Cover").Where("id IN ?", ids).Find(&books).Error; err != nil {
+		return nil, err
+	}
+	byID := make(map[uint]Book, len(books))
+	for _, book := range books {
+		byID[book.ID] = book
+	}
+
+	for _, row := range rows {
+		recommendation := Recommendation{Book: byID[row.BookID], Reason: row.Reason, Score: row.Score}
+		if row.BecauseBookID != nil {
+			if because, ok := byID[*row.BecauseBookID]; ok {
+				recommendation.Because = &BookRef{ID: because.ID, Title: because.Title}
+				recommendation.Explanation = explainRecommendation(row.Reason, row.Signal, because.Title)
+			}
+		}
+		recommendations = append(recommendations, recommendation)
+	}
+	return recommendations, nil
+}
+
+// explainRecommendation формирует текст объяснения вида «because you bought X».
+func explainRecommendation(reason, signal, title string) string {
+	switch reason {
+	case ReasonSameAuthor:
+		return fmt.Sprintf("By the author of %q", title)
+	case ReasonSameCategory:
+		return fmt.Sprintf("Similar to %q", title)
+	}
+	switch signal {
+	case SignalBought:
+		return fmt.Sprintf("Because you bought %q", title)
+	case SignalWishlist:
+		return fmt.Sprintf("Because %q is in your wishlist", title)
+	case SignalLiked:
+		return fmt.Sprintf("Because you liked %q", title)
+	}
+	return fmt.Sprintf("Because you were interested in %q", title)
+}
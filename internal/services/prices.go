@@ -0,0 +1,141 @@
+package services
+
+import (
+	"Projectmugen/internal/utils"
+	"errors"
+	"log"
+	"regexp"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Виды записей в истории цен.
+const (
+	PriceRegular = "regular" // базовая цена, действует до появления следующей базовой цены
+	PriceSale    = "sale"    // распродажа: на время действия перекрывает базовую цену
+)
+
+var (
+	ErrInvalidPrice   = errors.New("invalid price")
+	ErrPriceImmutable = errors.New("only scheduled prices can be removed")
+)
+
+// DefaultCurrency — валюта цены, если она не указана явно.
+var DefaultCurrency = strings.ToUpper(utils.GetEnv("PRICE_DEFAULT_CURRENCY", "RUB"))
+
+// PriceSchedulerInterval — период применения запланированных цен и окончания распродаж.
+var PriceSchedulerInterval = utils.GetEnvDuration("PRICE_SCHEDULER_INTERVAL", time.Minute)
+
+var currencyCode = regexp.MustCompile(`^[A-Z]{3}$`)
+
+// BookPrice — запись истории цен книги. Суммы хранятся целым числом в минимальных
+// единицах валюты (копейки, тиыны, центы), чтобы избежать ошибок округления.
+type BookPrice struct {
+	ID        uint       `gorm:"primaryKey" json:"id"`
+	BookID    uint       `gorm:"index;not null" json:"book_id"`
+	Kind      string     `gorm:"not null;default:regular" json:"kind"`
+	Amount    int64      `gorm:"not null" json:"amount"`
+	Currency  string     `gorm:"size:3;not null" json:"currency"`
+	StartsAt  time.Time  `gorm:"index;not null" json:"starts_at"`
+	EndsAt    *time.Time `json:"ends_at,omitempty"`
+	Username  string     `json:"username"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// NormalizeCurrency приводит код валюты к верхнему регистру и проверяет его формат (ISO 4217).
+// Пустой код заменяется на DefaultCurrency.
+func NormalizeCurrency(code string) (string, error) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if code == "" {
+		return DefaultCurrency, nil
+	}
+	if !currencyCode.MatchString(code) {
+		return "", ErrInvalidPrice
+	}
+	return code, nil
+}
+
+// ValidatePrice проверяет запись перед сохранением: сумма неотрицательна, распродажа
+// ограничена по времени, а окончание действия позже начала.
+func ValidatePrice(price *BookPrice) error {
+	if price.Amount < 0 {
+		return ErrInvalidPrice
+	}
+	if price.Kind != PriceRegular && price.Kind != PriceSale {
+		return ErrInvalidPrice
+	}
+	if price.Kind == PriceSale && price.EndsAt == nil {
+		return ErrInvalidPrice
+	}
+	if price.EndsAt != nil && !price.EndsAt.After(price.StartsAt) {
+		return ErrInvalidPrice
+	}
+	currency, err := NormalizeCurrency(price.Currency)
+	if err != nil {
+		return err
+	}
+	price.Currency = currency
+	return nil
+}
+
+// ApplyBookPrices пересчитывает действующие цены книг на момент now и сохраняет их в books.
+// Действует последняя начавшаяся базовая цена; активная распродажа перекрывает ее.
+// Если ids не переданы, пересчитываются все книги. У книг, цена которых изменилась, увеличивается
+// версия, чтобы закэшированные по ETag представления устарели. Возвращает количество таких книг.
+func ApplyBookPrices(tx *gorm.DB, now time.Time, ids ...uint) (int64, error) {
+	scope := "b.id IN (SELECT book_id FROM book_prices) OR b.price IS NOT NULL"
+	args := []interface{}{now, now, now, now}
+	if len(ids) > 0 {
+		scope = "b.id IN ?"
+		args = append(args, ids)
+	}
+
+	result := tx.Exec(`
+		UPDATE books SET price = cur.price, regular_price = cur.regular_price,
+			currency = cur.currency, on_sale = cur.on_sale, version = books.version + 1
+		FROM (
+			SELECT b.id,
+				COALESCE(s.amount, r.amount) AS price,
+				r.amount AS regular_price,
+				COALESCE(s.currency, r.currency, '') AS currency,
+				s.amount IS NOT NULL AS on_sale
+			FROM books b
+			LEFT JOIN LATERAL (
+				SELECT amount, currency FROM book_prices
+				WHERE book_id = b.id AND kind = 'regular' AND starts_at <= ? AND (ends_at IS NULL OR ends_at > ?)
+				ORDER BY starts_at DESC, id DESC LIMIT 1
+			) r ON true
+			LEFT JOIN LATERAL (
+				SELECT amount, currency FROM book_prices
+				WHERE book_id = b.id AND kind = 'sale' AND starts_at <= ? AND ends_at > ?
+				ORDER BY starts_at DESC, id DESC LIMIT 1
+			) s ON true
+			WHERE `+scope+`
+		) cur
+		WHERE books.id = cur.id AND (
+			books.price IS DISTINCT FROM cur.price OR
+			books.regular_price IS DISTINCT FROM cur.regular_price OR
+			books.currency IS DISTINCT FROM cur.currency OR
+			books.on_sale IS DISTINCT FROM cur.on_sale
+		)`, args...)
+	return result.RowsAffected, result.Error
+}
+
+// StartPriceScheduler запускает фоновое применение запланированных цен с периодом PriceSchedulerInterval.
+func StartPriceScheduler() {
+	go func() {
+		ticker := time.NewTicker(PriceSchedulerInterval)
+		defer ticker.Stop()
+		for {
+			changed, err := ApplyBookPrices(Db, time.Now())
+			if err != nil {
+				log.Println("price scheduler:", err)
+			} else if changed > 0 {
+				log.Printf("price scheduler: updated prices of %d books", changed)
+			}
+			<-ticker.C
+		}
+	}()
+}
@@ -0,0 +1,35 @@
+package services
+
+import "gorm.io/gorm"
+
+type Publisher struct {
+	ID      uint   `gorm:"primaryKey" json:"id"`
+	Name    string `gorm:"uniqueIndex;not null" json:"name"`
+	Country string `json:"country"`
+	Website string `json:"website"`
+	Books   []Book `gorm:"foreignKey:PublisherID" json:"books,omitempty"`
+}
+
+// migrateLegacyPublisher переносит числовую колонку books.publisher в ссылку на таблицу publishers.
+// Для каждого встреченного номера создается издатель с тем же идентификатором, после чего
+// старая колонка удаляется. Повторный запуск ничего не делает.
+func migrateLegacyPublisher(db *gorm.DB) error {
+	if !db.Migrator().HasColumn(&Book{}, "publisher") {
+		return nil
+	}
+	return db.Transaction(func(tx *gorm.DB) error {
+		statements := []string{
+			`INSERT INTO publishers (id, name)
+				SELECT DISTINCT publisher, 'Publisher #' || publisher FROM books WHERE publisher <> 0
+				ON CONFLICT DO NOTHING`,
+			`UPDATE books SET publisher_id = publisher WHERE publisher <> 0`,
+			`SELECT setval(pg_get_serial_sequence('publishers', 'id'), COALESCE((SELECT MAX(id) FROM publishers), 0) + 1, false)`,
+		}
+		for _, statement := range statements {
+			if err := tx.Exec(statement).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Migrator().DropColumn(&Book{}, "publisher")
+	})
+}
@@ -0,0 +1,189 @@
+package services
+
+import (
+	"Projectmugen/internal/utils"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Причины, по которым книга попала в рекомендации.
+const (
+	ReasonAlsoBought   = "also_bought"   // книги покупали вместе
+	ReasonSameAuthor   = "same_author"   // у книг общий автор
+	ReasonSameCategory = "same_category" // книги из одной рубрики
+)
+
+// AlsoBoughtInterval — период пересчета рекомендаций «с этой книгой покупают».
+var AlsoBoughtInterval = utils.GetEnvDuration("ALSO_BOUGHT_INTERVAL", time.Hour)
+
+// AlsoBoughtMinOrders — сколько заказов должны содержать пару книг, чтобы она попала в рекомендации.
+var AlsoBoughtMinOrders = utils.GetEnvInt("ALSO_BOUGHT_MIN_ORDERS", 2)
+
+// AlsoBoughtPerBook — сколько связанных книг сохраняется для каждой книги.
+var AlsoBoughtPerBook = utils.GetEnvInt("ALSO_BOUGHT_PER_BOOK", 50)
+
+// AlsoBought — предвычисленная пара книг, которые покупают вместе.
+// Score — косинусная мера: число общих заказов, деленное на корень из произведения
+// числа заказов каждой книги, чтобы бестселлеры не попадали в рекомендации ко всему подряд.
+type AlsoBought struct {
+	BookID     uint      `gorm:"primaryKey" json:"book_id"`
+	RelatedID  uint      `gorm:"primaryKey" json:"related_id"`
+	Orders     int       `json:"orders"`
+	Score      float64   `json:"score"`
+	ComputedAt time.Time `json:"computed_at"`
+}
+
+// Recommendation — рекомендованная книга с причиной рекомендации. Для персональных
+// рекомендаций Because указывает книгу из истории пользователя, а Explanation — готовый текст объяснения.
+type Recommendation struct {
+	Book        Book     `json:"book"`
+	Reason      string   `json:"reason"`
+	Score       float64  `json:"score"`
+	Because     *BookRef `json:"because,omitempty"`
+	Explanation string   `json:"explanation,omitempty"`
+}
+
+// BookRef — краткая ссылка на книгу.
+type BookRef struct {
+	ID    uint   `json:"id"`
+	Title string `json:"title"`
+}
+
+// RecomputeAlsoBought пересчитывает таблицу also_boughts по строкам заказов, кроме отмененных и возвращенных.
+// Пересчет выполняется в одной транзакции, поэтому читатели видят либо старые, либо новые данные.
+func RecomputeAlsoBought() (int64, error) {
+	var inserted int64
+	err := Db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("DELETE FROM also_boughts").Error; err != nil {
+			return err
+		}
+		result := tx.Exec(`
+			WITH sold AS (
+				SELECT op.order_id, op.product_id FROM order_products op
+				JOIN orders o ON o.id = op.order_id WHERE o.status NOT IN ?
+			), counts AS (
+				SELECT product_id, COUNT(DISTINCT order_id) AS n FROM sold GROUP BY product_id
+			), pairs AS (
+				SELECT a.product_id AS book_id, b.product_id AS related_id, COUNT(DISTINCT a.order_id) AS orders
+				FROM sold a
+				JOIN sold b ON b.order_id = a.order_id AND b.product_id <> a.product_id
+				GROUP BY a.product_id, b.product_id
+				HAVING COUNT(DISTINCT a.order_id) >= ?
+			), ranked AS (
+				SELECT p.book_id, p.related_id, p.orders,
+					p.orders / sqrt(ca.n::float * cb.n) AS score,
+					row_number() OVER (PARTITION BY p.book_id ORDER BY p.orders / sqrt(ca.n::float * cb.n) DESC, p.related_id) AS rank
+				FROM pairs p
+				JOIN counts ca ON ca.product_id = p.book_id
+				JOIN counts cb ON cb.product_id = p.related_id
+			)
+			INSERT INTO also_boughts (book_id, related_id, orders, score, computed_at)
+			SELECT book_id, related_id, orders, score, ? FROM ranked WHERE rank <= ?`,
+			VoidedOrderStatuses, AlsoBoughtMinOrders, time.Now(), AlsoBoughtPerBook)
+		inserted = result.RowsAffected
+		return result.Error
+	})
+	return inserted, err
+}
+
+// StartAlsoBoughtJob запускает фоновый пересчет рекомендаций с периодом AlsoBoughtInterval.
+func StartAlsoBoughtJob() {
+	go func() {
+		ticker := time.NewTicker(AlsoBoughtInterval)
+		defer ticker.Stop()
+		for {
+			pairs, err := RecomputeAlsoBought()
+			if err != nil {
+				log.Println("also bought:", err)
+			} else {
+				log.Printf("also bought: computed %d book pairs", pairs)
+			}
+			<-ticker.C
+		}
+	}()
+}
+
+// AlsoBoughtFor возвращает до limit книг, которые покупают вместе с книгой book.
+// Если данных о заказах мало, список дополняется книгами того же автора, а затем той же рубрики;
+// книги в наличии в дополнении идут первыми.
+func AlsoBoughtFor(book *Book, limit int) ([]Recommendation, error) {
+	recommendations := []Recommendation{}
+	seen := map[uint]bool{book.ID: true}
+
+	var pairs []AlsoBought
+	err := Db.Joins("JOIN books ON books.id = also_boughts.related_id AND books.deleted_at IS NULL").
+		Where("also_boughts.book_id = ?", book.ID).
+		Order("score desc, related_id asc").
+		Limit(limit).
+		Find(&pairs).Error
+	if err != nil {
+		return nil, err
+	}
+	if len(pairs) > 0 {
+		ids := make([]uint, len(pairs))
+		for i, pair := range pairs {
+			ids[i] = pair.RelatedID
+		}
+		var books []Book
+		if err := Db.Preload("Cover").Where("id IN ?", ids).Find(&books).Error; err != nil {
+			return nil, err
+		}
+		byID := make(map[uint]Book, len(books))
+		for _, b := range books {
+			byID[b.ID] = b
+		}
+		for _, pair := range pairs {
+			if b, ok := byID[pair.RelatedID]; ok {
+				recommendations = append(recommendations, Recommendation{Book: b, Reason: ReasonAlsoBought, Score: pair.Score})
+				seen[b.ID] = true
+			}
+		}
+	}
+
+	fallbacks := []struct {
+		reason string
+		scope  func(*gorm.DB) *gorm.DB
+	}{
+		{ReasonSameAuthor, func(db *gorm.DB) *gorm.DB {
+			return db.Where(`id IN (
+				SELECT other.book_id FROM book_authors own
+				JOIN book_authors other ON other.author_id = own.author_id
+				WHERE own.book_id = ?)`, book.ID)
+		}},
+		{ReasonSameCategory, func(db *gorm.DB) *gorm.DB {
+			if book.CategoryID == nil {
+				return nil
+			}
+			return db.Where("category_id = ?", *book.CategoryID)
+		}},
+	}
+	for _, fallback := range fallbacks {
+		if len(recommendations) >= limit {
+			break
+		}
+		query := fallback.scope(Db.Model(&Book{}))
+		if query == nil {
+			continue
+		}
+		ids := make([]uint, 0, len(seen))
+		for id := range seen {
+			ids = append(ids, id)
+		}
+		var books []Book
+		err := query.Preload("Cover").
+			Where("id NOT IN ?", ids).
+			Order("stock > 0 desc, id desc").
+			Limit(limit - len(recommendations)).
+			Find(&books).Error
+		if err != nil {
+			return nil, err
+		}
+		for _, b := range books {
+			recommendations = append(recommendations, Recommendation{Book: b, Reason: fallback.reason})
+			seen[b.ID] = true
+		}
+	}
+	return recommendations, nil
+}
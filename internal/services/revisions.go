@@ -0,0 +1,241 @@
+package services
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Действия, после которых сохраняется ревизия книги.
+const (
+	RevisionCreate   = "create"
+	RevisionUpdate   = "update"
+	RevisionDelete   = "delete"
+	RevisionRestore  = "restore"
+	RevisionRollback = "rollback"
+)
+
+// FieldChange — изменение одного поля книги.
+type FieldChange struct {
+	Field string      `json:"field"`
+	Old   interface{} `json:"old"`
+	New   interface{} `json:"new"`
+}
+
+// BookRevision хранит состояние книги после изменения и список измененных полей.
+type BookRevision struct {
+	ID        uint                   `gorm:"primaryKey" json:"id"`
+	BookID    uint                   `gorm:"index;not null" json:"book_id"`
+	Action    string                 `json:"action"`
+	Username  string                 `json:"username"`
+	CreatedAt time.Time              `json:"created_at"`
+	Changes   []FieldChange          `gorm:"serializer:json" json:"changes"`
+	Snapshot  map[string]interface{} `gorm:"serializer:json" json:"snapshot"`
+}
+
+// BookSnapshot возвращает значения версионируемых полей книги в виде колонка → значение.
+// Ключи совпадают и с именами колонок, и с JSON-тегами Book: снимок можно применить через
+// Updates, а сохраненный в базе снимок — разобрать обратно в Book (см. ApplyBookSnapshot).
+func BookSnapshot(book *Book) map[string]interface{} {
+	if book == nil {
+		return map[string]interface{}{}
+	}
+	return map[string]interface{}{
+		"title":         book.Title,
+		"author":        book.Author,
+		"year":          book.Year,
+		"description":   book.Description,
+		"language":      book.Language,
+		"publisher_id":  book.PublisherID,
+		"category_id":   book.CategoryID,
+		"work_id":       book.WorkID,
+		"series_id":     book.SeriesID,
+		"series_volume": book.SeriesVolume,
+		"isbn13":        book.ISBN13,
+		"isbn10":        book.ISBN10,
+	}
+}
+
+// ApplyBookSnapshot возвращает значения версионируемых полей из сохраненного снимка
+// в типизированном виде, пригодном для Updates.
+func ApplyBookSnapshot(snapshot map[string]interface{}) (map[string]interface{}, error) {
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return nil, err
+	}
+	var book Book
+	if err := json.Unmarshal(data, &book); err != nil {
+		return nil, err
+	}
+	return BookSnapshot(&book), nil
+}
+
+// RecordBookRevision сохраняет ревизию книги. before равен nil при создании,
+// after — при удалении; в этом случае снимком служит последнее состояние книги.
+func RecordBookRevision(tx *gorm.DB, action, username string, before, after *Book) error {
+	old, current := BookSnapshot(before), BookSnapshot(after)
+
+	revision := BookRevision{
+		Action:   action,
+		Username: username,
+		Changes:  diffSnapshots(old, current),
+		Snapshot: current,
+	}
+	switch {
+	case after != nil:
+		revision.BookID = after.ID
+	case before != nil:
+		revision.BookID = before.ID
+		revision.Snapshot = old
+	}
+	if action == RevisionUpdate && len(revision.Changes) == 0 {
+		return nil
+	}
+	return tx.Create(&revision).Error
+}
+
+// bookAuthorRef — участник книги в списке изменений ревизии.
+type bookAuthorRef struct {
+	AuthorID uint   `json:"author_id"`
+	Role     string `json:"role"`
+}
+
+// RecordBookAuthorsRevision сохраняет ревизию замены участников книги: before и after —
+// связи до и после изменения в порядке position. Участники не входят в снимок (см. BookSnapshot),
+// поэтому изменение видно в истории, но откат к ревизии их не восстанавливает.
+func RecordBookAuthorsRevision(tx *gorm.DB, username string, book *Book, before, after []BookAuthor) error {
+	refs := func(links []BookAuthor) []bookAuthorRef {
+		result := make([]bookAuthorRef, len(links))
+		for i, link := range links {
+			result[i] = bookAuthorRef{AuthorID: link.AuthorID, Role: link.Role}
+		}
+		return result
+	}
+	old, current := refs(before), refs(after)
+	if reflect.DeepEqual(old, current) {
+		return nil
+	}
+	return tx.Create(&BookRevision{
+		BookID:   book.ID,
+		Action:   RevisionUpdate,
+		Username: username,
+		Changes:  []FieldChange{{Field: "authors", Old: old, New: current}},
+		Snapshot: BookSnapshot(book),
+	}).Error
+}
+
+// bookFileRef — файл книги в списке изменений ревизии.
+type bookFileRef struct {
+	Format string `json:"format"`
+	Size   int64  `json:"size"`
+}
+
+// RecordBookFileRevision сохраняет ревизию загрузки, замены или удаления файла книги:
+// before и after равны nil, если файла формата не было до или нет после изменения.
+// Файлы не входят в снимок, поэтому откат к ревизии их не восстанавливает.
+func RecordBookFileRevision(tx *gorm.DB, username string, bookID uint, before, after *BookFile) error {
+	ref := func(file *BookFile) *bookFileRef {
+		if file == nil {
+			return nil
+		}
+		return &bookFileRef{Format: file.Format, Size: file.Size}
+	}
+	var book Book
+	if err := tx.First(&book, bookID).Error; err != nil {
+		return err
+	}
+	return tx.Create(&BookRevision{
+		BookID:   book.ID,
+		Action:   RevisionUpdate,
+		Username: username,
+		Changes:  []FieldChange{{Field: "files", Old: ref(before), New: ref(after)}},
+		Snapshot: BookSnapshot(&book),
+	}).Error
+}
+
+// DetachBooks обнуляет ссылку column (publisher_id, category_id, work_id, series_id) у всех книг,
+// включая удаленные, которые ссылаются на запись id, увеличивает их версии и сохраняет
+// ревизии. Вызывается в транзакции перед удалением записи, чтобы изменение книг
+// не обходило историю и проверку версий.
+func DetachBooks(tx *gorm.DB, column string, id uint, username string) error {
+	var before []Book
+	if err := tx.Unscoped().Where(column+" = ?", id).Order("id").Find(&before).Error; err != nil {
+		return err
+	}
+	if len(before) == 0 {
+		return nil
+	}
+	ids := make([]uint, len(before))
+	for i, book := range before {
+		ids[i] = book.ID
+	}
+	err := tx.Unscoped().Model(&Book{}).Where("id IN ?", ids).Updates(map[string]interface{}{
+		column:    nil,
+		"version": gorm.Expr("version + 1"),
+	}).Error
+	if err != nil {
+		return err
+	}
+
+	var after []Book
+	if err := tx.Unscoped().Where("id IN ?", ids).Order("id").Find(&after).Error; err != nil {
+		return err
+	}
+	for i := range after {
+		if err := RecordBookRevision(tx, RevisionUpdate, username, &before[i], &after[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func diffSnapshots(old, current map[string]interface{}) []FieldChange {
+	changes := []FieldChange{}
+	for _, field := range snapshotFields(old, current) {
+		oldValue, newValue := normalizeValue(old[field]), normalizeValue(current[field])
+		if !reflect.DeepEqual(oldValue, newValue) {
+			changes = append(changes, FieldChange{Field: field, Old: oldValue, New: newValue})
+		}
+	}
+	return changes
+}
+
+// snapshotFields возвращает объединение ключей снимков в алфавитном порядке.
+func snapshotFields(snapshots ...map[string]interface{}) []string {
+	seen := map[string]bool{}
+	var fields []string
+	for _, snapshot := range snapshots {
+		for field := range snapshot {
+			if !seen[field] {
+				seen[field] = true
+				fields = append(fields, field)
+			}
+		}
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+// normalizeValue приводит значение к виду, который получится после сохранения в JSON,
+// чтобы сравнение не зависело от того, прочитан снимок из базы или построен из структуры.
+func normalizeValue(v interface{}) interface{} {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return v
+	}
+	var out interface{}
+	json.Unmarshal(data, &out)
+	return out
+}
+
+// backfillBookCreatedAt заполняет дату появления книг, добавленных до появления колонки
+// created_at, по первой ревизии книги.
+func backfillBookCreatedAt(db *gorm.DB) error {
+	return db.Exec(`
+		UPDATE books SET created_at = first.created_at
+		FROM (SELECT book_id, MIN(created_at) AS created_at FROM book_revisions GROUP BY book_id) first
+		WHERE first.book_id = books.id AND books.created_at IS NULL`).Error
+}
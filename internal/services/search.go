@@ -0,0 +1,185 @@
+package services
+
+import (
+	"Projectmugen/internal/utils"
+	"context"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Типы подсказок поиска.
+const (
+	SuggestTitle    = "title"
+	SuggestAuthor   = "author"
+	SuggestCategory = "category"
+)
+
+// TrigramAvailable сообщает, установлено ли расширение pg_trgm. Без него поиск
+// ограничивается совпадением по префиксу.
+var TrigramAvailable bool
+
+// SuggestCacheTTL — время жизни подсказок в кэше.
+var SuggestCacheTTL = utils.GetEnvDuration("SUGGEST_CACHE_TTL", 30*time.Second)
+
+// SuggestTimeout ограничивает время запроса подсказок к базе.
+var SuggestTimeout = utils.GetEnvDuration("SUGGEST_TIMEOUT", 200*time.Millisecond)
+
+const suggestCacheMaxEntries = 10000
+
+// Suggestion — подсказка поиска: название книги, автор или рубрика.
+type Suggestion struct {
+	Type  string  `json:"type"`
+	ID    uint    `json:"id"`
+	Text  string  `json:"text"`
+	Score float64 `json:"-"`
+}
+
+// ensureSearchIndexes подключает pg_trgm и создает триграммные индексы для поиска.
+// Установка расширения может требовать прав суперпользователя, поэтому ошибка не фатальна.
+func ensureSearchIndexes(db *gorm.DB) {
+	if err := db.Exec("CREATE EXTENSION IF NOT EXISTS pg_trgm").Error; err != nil {
+		log.Println("search: pg_trgm is not available, fuzzy search is disabled:", err)
+		return
+	}
+	statements := []string{
+		"CREATE INDEX IF NOT EXISTS idx_books_title_trgm ON books USING gin (title gin_trgm_ops)",
+		"CREATE INDEX IF NOT EXISTS idx_books_author_trgm ON books USING gin (author gin_trgm_ops)",
+		"CREATE INDEX IF NOT EXISTS idx_authors_name_trgm ON authors USING gin (name gin_trgm_ops)",
+		"CREATE INDEX IF NOT EXISTS idx_categories_name_trgm ON categories USING gin (name gin_trgm_ops)",
+	}
+	for _, statement := range statements {
+		if err := db.Exec(statement).Error; err != nil {
+			log.Println("search:", err)
+			return
+		}
+	}
+	TrigramAvailable = true
+}
+
+// EscapeLike экранирует спецсимволы шаблона LIKE.
+func EscapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+type suggestCacheEntry struct {
+	suggestions []Suggestion
+	expires     time.Time
+}
+
+var (
+	suggestCacheMu sync.Mutex
+	suggestCache   = make(map[string]suggestCacheEntry)
+)
+
+// Suggest возвращает до limit подсказок для строки q. Совпадения с началом слова идут первыми,
+// затем похожие по триграммам. Результаты кэшируются на SuggestCacheTTL.
+func Suggest(ctx context.Context, q string, limit int) ([]Suggestion, error) {
+	q = strings.TrimSpace(q)
+	key := strings.ToLower(q) + "\x00" + strconv.Itoa(limit)
+
+	suggestCacheMu.Lock()
+	entry, ok := suggestCache[key]
+	suggestCacheMu.Unlock()
+	if ok && time.Now().Before(entry.expires) {
+		return entry.suggestions, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, SuggestTimeout)
+	defer cancel()
+
+	prefix := EscapeLike(q) + "%"
+	wordPrefix := "% " + prefix
+	// Совпадение с началом строки дает 2, с началом слова — 1; к этому прибавляется триграммное сходство
+	source := func(kind, table, column, extra string) string {
+		match := column + " ILIKE @prefix OR " + column + " ILIKE @word"
+		score := "CASE WHEN " + column + " ILIKE @prefix THEN 2 WHEN " + column + " ILIKE @word THEN 1 ELSE 0 END"
+		if TrigramAvailable {
+			match += " OR " + column + " % @q"
+			score += " + similarity(" + column + ", @q)"
+		}
+		return "(SELECT '" + kind + "' AS type, id, " + column + " AS text, " + score + " AS score FROM " + table +
+			" WHERE (" + match + ")" + extra + " ORDER BY score DESC LIMIT @limit)"
+	}
+	query := source(SuggestTitle, "books", "title", " AND deleted_at IS NULL") +
+		" UNION ALL " + source(SuggestAuthor, "authors", "name", "") +
+		" UNION ALL " + source(SuggestCategory, "categories", "name", "") +
+		" ORDER BY score DESC, text ASC LIMIT @limit"
+
+	suggestions := []Suggestion{}
+	err := Db.WithContext(ctx).Raw(query, map[string]interface{}{
+		"prefix": prefix,
+		"word":   wordPrefix,
+		"q":      q,
+		"limit":  limit,
+	}).Scan(&suggestions).Error
+	if err != nil {
+		return nil, err
+	}
+
+	suggestCacheMu.Lock()
+	if len(suggestCache) >= suggestCacheMaxEntries {
+		suggestCache = make(map[string]suggestCacheEntry)
+	}
+	suggestCache[key] = suggestCacheEntry{suggestions: suggestions, expires: time.Now().Add(SuggestCacheTTL)}
+	suggestCacheMu.Unlock()
+	return suggestions, nil
+}
+
+// SearchSimilarityThreshold — минимальное сходство по словам (word_similarity), при котором
+// книга находится по названию или автору с опечаткой.
+var SearchSimilarityThreshold = utils.GetEnvFloat("SEARCH_SIMILARITY_THRESHOLD", 0.4)
+
+// searchConnParams возвращает параметры подключения, задающие порог оператора <% для
+// каждого соединения пула: в отличие от вызова word_similarity, оператор использует
+// триграммные индексы.
+func searchConnParams() string {
+	return " pg_trgm.word_similarity_threshold=" + strconv.FormatFloat(SearchSimilarityThreshold, 'f', -1, 64)
+}
+
+// SearchSparseResults — при таком или меньшем числе найденных книг в ответ добавляется «возможно, вы имели в виду».
+var SearchSparseResults = int64(utils.GetEnvInt("SEARCH_SPARSE_RESULTS", 2))
+
+// DidYouMean возвращает известное название книги или имя автора, ближе всего похожее на один из
+// поисковых запросов terms, либо пустую строку. Без pg_trgm подсказки не подбираются.
+func DidYouMean(terms ...string) (string, error) {
+	if !TrigramAvailable {
+		return "", nil
+	}
+	var best struct {
+		Text  string
+		Score float64
+	}
+	for _, term := range terms {
+		term = strings.TrimSpace(term)
+		if term == "" {
+			continue
+		}
+		var candidate struct {
+			Text  string
+			Score float64
+		}
+		err := Db.Raw(`
+			SELECT text, score FROM (
+				SELECT title AS text, GREATEST(similarity(title, @q), word_similarity(@q, title)) AS score
+				FROM books WHERE deleted_at IS NULL AND (title % @q OR @q <% title)
+				UNION ALL
+				SELECT name, GREATEST(similarity(name, @q), word_similarity(@q, name))
+				FROM authors WHERE name % @q OR @q <% name
+			) s
+			WHERE lower(text) <> lower(@q)
+			ORDER BY score DESC, text ASC LIMIT 1`,
+			map[string]interface{}{"q": term}).Scan(&candidate).Error
+		if err != nil {
+			return "", err
+		}
+		if candidate.Score > best.Score {
+			best = candidate
+		}
+	}
+	return best.Text, nil
+}
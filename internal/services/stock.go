@@ -0,0 +1,106 @@
+package services
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Причины движения остатков.
+const (
+	StockReceiving  = "receiving"  // поступление от поставщика
+	StockDamage     = "damage"     // списание брака
+	StockCorrection = "correction" // корректировка по инвентаризации
+	StockOrder      = "order"      // продажа по заказу
+	StockOrderUndo  = "order_cancel"
+)
+
+var (
+	ErrInsufficientStock = errors.New("insufficient stock")
+	ErrInvalidAdjustment = errors.New("invalid stock adjustment")
+	ErrImmutableLedger   = errors.New("stock movements are immutable")
+)
+
+// StockMovement — запись журнала движения остатков. Журнал только дополняется:
+// изменить или удалить запись нельзя, исправления оформляются новой корректировкой.
+type StockMovement struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	BookID    uint      `gorm:"index;not null" json:"book_id"`
+	Delta     int       `gorm:"not null" json:"delta"`
+	Balance   int       `gorm:"not null" json:"balance"` // Остаток после движения
+	Reason    string    `gorm:"not null" json:"reason"`
+	Note      string    `json:"note"`
+	OrderID   *uint     `gorm:"index" json:"order_id,omitempty"`
+	Username  string    `json:"username"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (m *StockMovement) BeforeUpdate(tx *gorm.DB) error {
+	return ErrImmutableLedger
+}
+
+func (m *StockMovement) BeforeDelete(tx *gorm.DB) error {
+	return ErrImmutableLedger
+}
+
+// ValidManualAdjustment проверяет ручную корректировку: поступление только увеличивает
+// остаток, списание только уменьшает, корректировка может менять его в обе стороны.
+func ValidManualAdjustment(reason string, delta int) bool {
+	switch reason {
+	case StockReceiving:
+		return delta > 0
+	case StockDamage:
+		return delta < 0
+	case StockCorrection:
+		return delta != 0
+	}
+	return false
+}
+
+// AdjustStock атомарно изменяет остаток книги на delta и записывает движение в журнал.
+// Остаток не может стать отрицательным: в этом случае возвращается ErrInsufficientStock.
+// Версия книги увеличивается, чтобы ETag ранее полученной книги перестал совпадать.
+// Должна вызываться внутри транзакции, чтобы изменение и запись журнала фиксировались вместе.
+func AdjustStock(tx *gorm.DB, bookID uint, delta int, reason, username, note string, orderID *uint) (*StockMovement, error) {
+	if delta == 0 {
+		return nil, ErrInvalidAdjustment
+	}
+
+	result := tx.Model(&Book{}).
+		Where("id = ? AND stock + ? >= 0", bookID, delta).
+		UpdateColumns(map[string]interface{}{
+			"stock":   gorm.Expr("stock + ?", delta),
+			"version": gorm.Expr("version + 1"),
+		})
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		var count int64
+		tx.Model(&Book{}).Where("id = ?", bookID).Count(&count)
+		if count == 0 {
+			return nil, gorm.ErrRecordNotFound
+		}
+		return nil, ErrInsufficientStock
+	}
+
+	var balance int
+	if err := tx.Model(&Book{}).Where("id = ?", bookID).Select("stock").Scan(&balance).Error; err != nil {
+		return nil, err
+	}
+
+	movement := StockMovement{
+		BookID:   bookID,
+		Delta:    delta,
+		Balance:  balance,
+		Reason:   reason,
+		Note:     note,
+		OrderID:  orderID,
+		Username: username,
+	}
+	if err := tx.Create(&movement).Error; err != nil {
+		return nil, err
+	}
+	return &movement, nil
+}
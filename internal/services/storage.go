@@ -0,0 +1,52 @@
+package services
+
+import (
+	"Projectmugen/internal/storage"
+	"Projectmugen/internal/utils"
+	"fmt"
+	"log"
+)
+
+// Storage — публичное хранилище файлов приложения (обложки); настраивается в InitStorage.
+var Storage storage.Storage
+
+// PrivateStorage — закрытое хранилище файлов книг. Его объекты не раздаются напрямую,
+// а отдаются только через подписанные ссылки на скачивание.
+var PrivateStorage storage.Storage
+
+// InitStorage создает хранилища файлов по переменным окружения.
+// STORAGE_DRIVER=local (по умолчанию) хранит файлы на диске, STORAGE_DRIVER=s3 — в S3-совместимых бакетах.
+func InitStorage() {
+	var err error
+	switch driver := utils.GetEnv("STORAGE_DRIVER", "local"); driver {
+	case "local":
+		Storage, err = storage.NewLocalStorage(
+			utils.GetEnv("STORAGE_LOCAL_ROOT", "./uploads"),
+			utils.GetEnv("STORAGE_LOCAL_URL_PREFIX", "/media"),
+		)
+		if err == nil {
+			// Каталог закрытого хранилища не должен совпадать с раздаваемым STORAGE_LOCAL_ROOT
+			PrivateStorage, err = storage.NewLocalStorage(utils.GetEnv("STORAGE_PRIVATE_ROOT", "./private"), "")
+		}
+	case "s3":
+		cfg := storage.S3Config{
+			Endpoint:  utils.GetEnv("S3_ENDPOINT", "http://localhost:9000"),
+			Region:    utils.GetEnv("S3_REGION", "us-east-1"),
+			Bucket:    utils.GetEnv("S3_BUCKET", "books"),
+			AccessKey: utils.GetEnv("S3_ACCESS_KEY", "minioadmin"),
+			SecretKey: utils.GetEnv("S3_SECRET_KEY", "minioadmin"),
+			PublicURL: utils.GetEnv("S3_PUBLIC_URL", ""),
+		}
+		Storage, err = storage.NewS3Storage(cfg)
+		if err == nil {
+			cfg.Bucket = utils.GetEnv("S3_PRIVATE_BUCKET", "books-private")
+			cfg.PublicURL = ""
+			PrivateStorage, err = storage.NewS3Storage(cfg)
+		}
+	default:
+		err = fmt.Errorf("unknown storage driver %q", driver)
+	}
+	if err != nil {
+		log.Fatal("Failed to initialize storage:", err)
+	}
+}
@@ -0,0 +1,151 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Режимы фильтрации книг по нескольким тегам.
+const (
+	TagModeAll = "all" // у книги есть все указанные теги
+	TagModeAny = "any" // у книги есть хотя бы один из тегов
+)
+
+const tagMaxLength = 64
+
+var ErrInvalidTag = errors.New("tag must be 1-64 characters long")
+
+// Tag — свободная метка книги («classic», «school-curriculum»). Название хранится в
+// нормализованном виде (см. NormalizeTag) и уникально.
+type Tag struct {
+	ID   uint   `gorm:"primaryKey" json:"id"`
+	Name string `gorm:"uniqueIndex;not null;size:64" json:"name"`
+}
+
+// BookTag — связь книги с тегом; таблица связи для Book.Tags.
+type BookTag struct {
+	BookID    uint `gorm:"primaryKey"`
+	TagID     uint `gorm:"primaryKey;index"`
+	CreatedAt time.Time
+}
+
+// TagCount — тег с числом книг для облака тегов.
+type TagCount struct {
+	ID    uint   `json:"id"`
+	Name  string `json:"name"`
+	Count int64  `json:"count"`
+}
+
+// NormalizeTag приводит тег к каноническому виду: нижний регистр, пробелы внутри
+// заменены дефисами, так что «School Curriculum» и «school-curriculum» — один тег.
+func NormalizeTag(name string) (string, error) {
+	name = strings.Join(strings.Fields(strings.ToLower(name)), "-")
+	if name == "" || utf8.RuneCountInString(name) > tagMaxLength {
+		return "", ErrInvalidTag
+	}
+	return name, nil
+}
+
+// NormalizeTags нормализует теги и убирает повторы; значения через запятую разбираются
+// как отдельные теги. Некорректные теги пропускаются.
+func NormalizeTags(names []string) []string {
+	var result []string
+	seen := make(map[string]bool)
+	for _, value := range names {
+		for _, name := range strings.Split(value, ",") {
+			tag, err := NormalizeTag(name)
+			if err != nil || seen[tag] {
+				continue
+			}
+			seen[tag] = true
+			result = append(result, tag)
+		}
+	}
+	return result
+}
+
+// FindOrCreateTags возвращает теги с нормализованными названиями names, создавая недостающие.
+func FindOrCreateTags(tx *gorm.DB, names []string) ([]Tag, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+	tags := make([]Tag, len(names))
+	for i, name := range names {
+		tags[i] = Tag{Name: name}
+	}
+	if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&tags).Error; err != nil {
+		return nil, err
+	}
+	tags = nil
+	if err := tx.Where("name IN ?", names).Order("name asc").Find(&tags).Error; err != nil {
+		return nil, err
+	}
+	return tags, nil
+}
+
+// AttachTags добавляет книге теги, создавая новые по необходимости. Уже назначенные теги не дублируются.
+func AttachTags(tx *gorm.DB, bookID uint, names []string) error {
+	tags, err := FindOrCreateTags(tx, names)
+	if err != nil || len(tags) == 0 {
+		return err
+	}
+	links := make([]BookTag, len(tags))
+	for i, tag := range tags {
+		links[i] = BookTag{BookID: bookID, TagID: tag.ID}
+	}
+	return tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&links).Error
+}
+
+// BumpTaggedBooks увеличивает версии всех книг с тегом tagID, включая удаленные:
+// переименование или удаление тега меняет их представление.
+func BumpTaggedBooks(tx *gorm.DB, tagID uint) error {
+	return tx.Exec("UPDATE books SET version = version + 1 WHERE id IN (SELECT book_id FROM book_tags WHERE tag_id = ?)", tagID).Error
+}
+
+// MergeTags переносит все книги с тега sourceID на тег targetID и удаляет исходный тег.
+// Версии книг с исходным тегом увеличиваются.
+func MergeTags(sourceID, targetID uint) error {
+	return Db.Transaction(func(tx *gorm.DB) error {
+		if err := BumpTaggedBooks(tx, sourceID); err != nil {
+			return err
+		}
+		err := tx.Exec(`
+			INSERT INTO book_tags (book_id, tag_id, created_at)
+			SELECT book_id, ?, created_at FROM book_tags WHERE tag_id = ?
+			ON CONFLICT DO NOTHING`, targetID, sourceID).Error
+		if err != nil {
+			return err
+		}
+		if err := tx.Where("tag_id = ?", sourceID).Delete(&BookTag{}).Error; err != nil {
+			return err
+		}
+		result := tx.Delete(&Tag{}, sourceID)
+		if result.Error == nil && result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return result.Error
+	})
+}
+
+// TagCloud возвращает теги с числом неудаленных книг, от самых популярных. Теги, у которых
+// книг меньше minCount, не попадают в результат; limit <= 0 снимает ограничение.
+func TagCloud(limit int, minCount int64) ([]TagCount, error) {
+	cloud := []TagCount{}
+	query := Db.Table("tags").
+		Select("tags.id, tags.name, COUNT(books.id) AS count").
+		Joins("LEFT JOIN book_tags ON book_tags.tag_id = tags.id").
+		Joins("LEFT JOIN books ON books.id = book_tags.book_id AND books.deleted_at IS NULL").
+		Group("tags.id, tags.name").
+		Having("COUNT(books.id) >= ?", minCount).
+		Order("count desc, tags.name asc")
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	err := query.Scan(&cloud).Error
+	return cloud, err
+}
@@ -0,0 +1,134 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"golang.org/x/text/language"
+)
+
+var ErrInvalidLanguage = errors.New("invalid language tag")
+
+// BookTranslation — перевод метаданных книги на один язык. Оригинальные название и описание
+// хранятся в самой книге, ее язык — в Book.Language.
+type BookTranslation struct {
+	BookID      uint      `gorm:"primaryKey" json:"book_id"`
+	Locale      string    `gorm:"primaryKey;size:16" json:"locale"`
+	Title       string    `gorm:"not null" json:"title"`
+	Description string    `json:"description"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// NormalizeLanguage приводит языковой тег BCP 47 к каноническому виду («EN_us» → «en-US»).
+// Пустая строка остается пустой: язык книги не указан.
+func NormalizeLanguage(tag string) (string, error) {
+	tag = strings.TrimSpace(tag)
+	if tag == "" {
+		return "", nil
+	}
+	parsed, err := language.Parse(tag)
+	if err != nil {
+		return "", ErrInvalidLanguage
+	}
+	if _, confidence := parsed.Base(); confidence != language.Exact {
+		return "", ErrInvalidLanguage
+	}
+	return parsed.String(), nil
+}
+
+// ParseLanguagePreferences разбирает значение Accept-Language в список языков по убыванию веса.
+// «*» и нераспознанные значения пропускаются.
+func ParseLanguagePreferences(header string) []language.Tag {
+	tags, _, err := language.ParseAcceptLanguage(header)
+	if err != nil {
+		return nil
+	}
+	preferences := tags[:0]
+	for _, tag := range tags {
+		if base, confidence := tag.Base(); confidence != language.Exact || base.String() == "mul" {
+			continue
+		}
+		preferences = append(preferences, tag)
+	}
+	return preferences
+}
+
+// sameBase сообщает, что теги относятся к одному языку (en-US и en-GB).
+func sameBase(a, b language.Tag) bool {
+	baseA, _ := a.Base()
+	baseB, _ := b.Base()
+	return baseA == baseB
+}
+
+// bestTranslation выбирает перевод для первого из предпочитаемых языков, на котором есть
+// оригинал или перевод. Точное совпадение тега важнее перевода на язык без региона, а тот —
+// перевода на другой региональный вариант того же языка. Возвращает nil,
+// если лучше всего подходит оригинал или подходящих переводов нет.
+func bestTranslation(book *Book, translations []BookTranslation, preferences []language.Tag) *BookTranslation {
+	original, originalErr := language.Parse(book.Language)
+	for _, preference := range preferences {
+		if originalErr == nil && book.Language != "" && (original == preference || sameBase(original, preference)) {
+			return nil
+		}
+		var generic, regional *BookTranslation
+		for i := range translations {
+			locale, err := language.Parse(translations[i].Locale)
+			if err != nil || !sameBase(locale, preference) {
+				continue
+			}
+			base, _ := locale.Base()
+			switch {
+			case locale == preference:
+				return &translations[i]
+			case locale.String() == base.String():
+				generic = &translations[i]
+			case regional == nil:
+				regional = &translations[i]
+			}
+		}
+		if generic != nil {
+			return generic
+		}
+		if regional != nil {
+			return regional
+		}
+	}
+	return nil
+}
+
+// LocalizeBooks подставляет в книги название и описание на лучшем из предпочитаемых языков.
+// У переведенных книг Locale указывает язык перевода, OriginalTitle — исходное название.
+// Книги без подходящего перевода остаются на языке оригинала.
+func LocalizeBooks(books []*Book, preferences []language.Tag) error {
+	if len(books) == 0 || len(preferences) == 0 {
+		return nil
+	}
+	ids := make([]uint, len(books))
+	for i, book := range books {
+		ids[i] = book.ID
+	}
+	var translations []BookTranslation
+	if err := Db.Where("book_id IN ?", ids).Find(&translations).Error; err != nil {
+		return err
+	}
+	byBook := make(map[uint][]BookTranslation)
+	for _, translation := range translations {
+		byBook[translation.BookID] = append(byBook[translation.BookID], translation)
+	}
+
+	for _, book := range books {
+		book.Locale = book.Language
+		translation := bestTranslation(book, byBook[book.ID], preferences)
+		if translation == nil {
+			continue
+		}
+		book.OriginalTitle = book.Title
+		book.Title = translation.Title
+		if translation.Description != "" {
+			book.Description = translation.Description
+		}
+		book.Locale = translation.Locale
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package services
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNormalizeLanguage(t *testing.T) {
+	tests := []struct {
+		tag, want string
+		err       error
+	}{
+		{"", "", nil},
+		{"  ", "", nil},
+		{"ru", "ru", nil},
+		{"EN_us", "en-US", nil},
+		{"zh-hant-tw", "zh-Hant-TW", nil},
+		{"xx-invalid-tag-", "", ErrInvalidLanguage},
+		{"und", "", ErrInvalidLanguage},
+	}
+	for _, tt := range tests {
+		got, err := NormalizeLanguage(tt.tag)
+		if !errors.Is(err, tt.err) || got != tt.want {
+			t.Errorf("NormalizeLanguage(%q) = %q, %v; want %q, %v", tt.tag, got, err, tt.want, tt.err)
+		}
+	}
+}
+
+func TestParseLanguagePreferences(t *testing.T) {
+	got := ParseLanguagePreferences("de;q=0.5, *;q=0.1, en-US, fr;q=0.8")
+	want := []string{"en-US", "fr", "de"}
+	if len(got) != len(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i].String() != want[i] {
+			t.Errorf("preference %d = %s, want %s", i, got[i], want[i])
+		}
+	}
+	if got := ParseLanguagePreferences("not a;;header="); got != nil {
+		t.Errorf("invalid header: got %v, want nil", got)
+	}
+}
+
+func TestBestTranslation(t *testing.T) {
+	translations := []BookTranslation{
+		{Locale: "en-GB", Title: "en-GB"},
+		{Locale: "en", Title: "en"},
+		{Locale: "pt-BR", Title: "pt-BR"},
+		{Locale: "de", Title: "de"},
+	}
+	tests := []struct {
+		name     string
+		language string
+		accept   string
+		want     string // пустая строка — оригинал
+	}{
+		{"exact regional match", "ru", "en-GB", "en-GB"},
+		{"generic over other region", "ru", "en-US", "en"},
+		{"other region when no generic", "ru", "pt-PT", "pt-BR"},
+		{"original language wins", "ru", "ru-RU, en", ""},
+		{"original earlier in preferences", "en", "en-GB", ""},
+		{"first preference with a match", "ru", "ja, de, en", "de"},
+		{"no matching translation", "ru", "ja", ""},
+		{"original language unknown", "", "en-GB", "en-GB"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			book := &Book{Language: tt.language}
+			got := bestTranslation(book, translations, ParseLanguagePreferences(tt.accept))
+			switch {
+			case tt.want == "" && got != nil:
+				t.Errorf("got %s, want original", got.Locale)
+			case tt.want != "" && got == nil:
+				t.Errorf("got original, want %s", tt.want)
+			case got != nil && got.Locale != tt.want:
+				t.Errorf("got %s, want %s", got.Locale, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package services
+
+import (
+	"Projectmugen/internal/utils"
+	"context"
+	"log"
+	"time"
+)
+
+// TrashRetention — срок хранения мягко удаленных книг перед окончательным удалением.
+var TrashRetention = utils.GetEnvDuration("BOOK_TRASH_RETENTION", 30*24*time.Hour)
+
+// TrashPurgeInterval — период запуска очистки корзины.
+var TrashPurgeInterval = utils.GetEnvDuration("BOOK_TRASH_PURGE_INTERVAL", time.Hour)
+
+// PurgeDeletedBooks окончательно удаляет книги, находящиеся в корзине дольше retention,
+// вместе с файлами их обложек и электронными версиями. Возвращает количество удаленных книг.
+func PurgeDeletedBooks(ctx context.Context, retention time.Duration) (int64, error) {
+	cutoff := time.Now().Add(-retention)
+
+	var books []Book
+	err := Db.Unscoped().Preload("Cover").Preload("Files").
+		Where("deleted_at IS NOT NULL AND deleted_at < ?", cutoff).
+		Find(&books).Error
+	if err != nil || len(books) == 0 {
+		return 0, err
+	}
+
+	ids := make([]uint, len(books))
+	for i, book := range books {
+		ids[i] = book.ID
+	}
+	result := Db.Unscoped().Delete(&Book{}, ids)
+	if result.Error != nil {
+		return 0, result.Error
+	}
+
+	for _, book := range books {
+		if book.Cover != nil {
+			deleteObjects(ctx, Storage, book.Cover.keys())
+		}
+		for _, file := range book.Files {
+			deleteObjects(ctx, PrivateStorage, []string{file.Key})
+		}
+	}
+	return result.RowsAffected, nil
+}
+
+// StartTrashPurger запускает фоновую очистку корзины с периодом TrashPurgeInterval.
+func StartTrashPurger() {
+	go func() {
+		ticker := time.NewTicker(TrashPurgeInterval)
+		defer ticker.Stop()
+		for {
+			purged, err := PurgeDeletedBooks(context.Background(), TrashRetention)
+			if err != nil {
+				log.Println("trash purge:", err)
+			} else if purged > 0 {
+				log.Printf("trash purge: removed %d books", purged)
+			}
+			<-ticker.C
+		}
+	}()
+}
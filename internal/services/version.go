@@ -0,0 +1,71 @@
+package services
+
+import (
+	"Projectmugen/internal/utils"
+	"errors"
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// ErrVersionConflict возвращается, если книгу изменили после того, как клиент прочитал ее версию.
+var ErrVersionConflict = errors.New("book version conflict")
+
+// RequireIfMatch определяет, обязателен ли заголовок If-Match для PUT и DELETE книг.
+var RequireIfMatch = utils.GetEnvBool("BOOK_REQUIRE_IF_MATCH", true)
+
+// BookETag возвращает сильный ETag книги, построенный по ее идентификатору и версии.
+// Части variant отличают разные представления одной версии (например, цены в другой валюте).
+func BookETag(book *Book, variant ...string) string {
+	tag := fmt.Sprintf("book-%d-v%d", book.ID, book.Version)
+	for _, part := range variant {
+		if part != "" {
+			tag += "-" + part
+		}
+	}
+	return `"` + tag + `"`
+}
+
+// BookVersionMatches проверяет If-Match для изменения книги: ETag любого представления
+// текущей версии подходит, «*» совпадает с любым ETag.
+func BookVersionMatches(header string, book *Book) bool {
+	base := strings.Trim(BookETag(book), `"`)
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.Trim(strings.TrimPrefix(strings.TrimSpace(candidate), "W/"), `"`)
+		if candidate == "*" || candidate == base || strings.HasPrefix(candidate, base+"-") {
+			return true
+		}
+	}
+	return false
+}
+
+// ETagMatches проверяет, содержит ли значение If-Match / If-None-Match указанный ETag.
+// Слабые валидаторы сравниваются по значению, «*» совпадает с любым ETag.
+func ETagMatches(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// BumpBookVersion увеличивает версию книги. Если expected не равен нулю, обновление
+// выполняется только при совпадении текущей версии, иначе возвращается ErrVersionConflict.
+// Вызов блокирует строку до конца транзакции, поэтому его стоит делать первым.
+func BumpBookVersion(tx *gorm.DB, id, expected uint) error {
+	query := tx.Model(&Book{}).Where("id = ?", id)
+	if expected != 0 {
+		query = query.Where("version = ?", expected)
+	}
+	result := query.UpdateColumn("version", gorm.Expr("version + 1"))
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrVersionConflict
+	}
+	return nil
+}
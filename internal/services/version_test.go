@@ -0,0 +1,36 @@
+package services
+
+import "testing"
+
+func TestBookETag(t *testing.T) {
+	book := &Book{ID: 7, Version: 3}
+	if got := BookETag(book); got != `"book-7-v3"` {
+		t.Errorf("BookETag = %s", got)
+	}
+	if got := BookETag(book, "EUR1015", ""); got != `"book-7-v3-EUR1015"` {
+		t.Errorf("BookETag with variant = %s", got)
+	}
+}
+
+func TestBookVersionMatches(t *testing.T) {
+	book := &Book{ID: 7, Version: 3}
+	tests := []struct {
+		header string
+		want   bool
+	}{
+		{`"book-7-v3"`, true},
+		{`"book-7-v3-EUR1015"`, true},
+		{`W/"book-7-v3-EUR1015"`, true},
+		{`"book-7-v2", "book-7-v3-USD1100"`, true},
+		{`*`, true},
+		{`"book-7-v2"`, false},
+		{`"book-7-v31"`, false},
+		{`"book-7-v31-EUR1015"`, false},
+		{`"book-17-v3"`, false},
+	}
+	for _, tt := range tests {
+		if got := BookVersionMatches(tt.header, book); got != tt.want {
+			t.Errorf("BookVersionMatches(%s) = %v, want %v", tt.header, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,11 @@
+package services
+
+import "time"
+
+// WishlistItem — книга в списке желаний пользователя.
+type WishlistItem struct {
+	Username  string    `gorm:"primaryKey" json:"-"`
+	BookID    uint      `gorm:"primaryKey" json:"book_id"`
+	Book      *Book     `gorm:"constraint:OnDelete:CASCADE" json:"book,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,46 @@
+package services
+
+import "time"
+
+// Work — произведение, объединяющее его издания: переиздания, переводы, разные форматы.
+// Каждое издание — отдельная книга со своим ISBN, ценой и остатком.
+type Work struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	Title       string    `gorm:"not null" json:"title"`
+	Author      string    `json:"author"`
+	Description string    `json:"description"`
+	Editions    []Book    `gorm:"foreignKey:WorkID" json:"editions,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// Series — книжная серия или цикл. Порядок книг в серии задает Book.SeriesVolume.
+type Series struct {
+	ID          uint   `gorm:"primaryKey" json:"id"`
+	Name        string `gorm:"uniqueIndex;not null" json:"name"`
+	Description string `json:"description"`
+	Books       []Book `gorm:"foreignKey:SeriesID" json:"books,omitempty"`
+}
+
+// EditionCounts возвращает число неудаленных изданий для каждого из произведений workIDs.
+func EditionCounts(workIDs []uint) (map[uint]int, error) {
+	counts := make(map[uint]int, len(workIDs))
+	if len(workIDs) == 0 {
+		return counts, nil
+	}
+	var rows []struct {
+		WorkID uint
+		Count  int
+	}
+	err := Db.Model(&Book{}).
+		Select("work_id, COUNT(*) AS count").
+		Where("work_id IN ?", workIDs).
+		Group("work_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		counts[row.WorkID] = row.Count
+	}
+	return counts, nil
+}
@@ -0,0 +1,122 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func newTestLocalStorage(t *testing.T) *LocalStorage {
+	t.Helper()
+	s, err := NewLocalStorage(filepath.Join(t.TempDir(), "uploads"), "/uploads/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestLocalStoragePath(t *testing.T) {
+	s := newTestLocalStorage(t)
+	tests := map[string]string{
+		"covers/12/original.jpg":  "covers/12/original.jpg",
+		"/covers/12/original.jpg": "covers/12/original.jpg",
+		"../../etc/passwd":        "etc/passwd",
+		"/etc/passwd":             "etc/passwd",
+		"covers/../../secret":     "secret",
+		"covers/./12//a.jpg":      "covers/12/a.jpg",
+	}
+	for key, want := range tests {
+		got, err := s.path(key)
+		if err != nil {
+			t.Errorf("path(%q): %v", key, err)
+			continue
+		}
+		if got != filepath.Join(s.Root, filepath.FromSlash(want)) {
+			t.Errorf("path(%q) = %s, want %s inside root", key, got, want)
+		}
+		if !strings.HasPrefix(got, s.Root+string(filepath.Separator)) {
+			t.Errorf("path(%q) = %s escapes root %s", key, got, s.Root)
+		}
+	}
+	for _, key := range []string{"", "/", "..", "../..", "a/.."} {
+		if _, err := s.path(key); err == nil {
+			t.Errorf("path(%q): expected error for empty key", key)
+		}
+	}
+}
+
+func TestLocalStorageRoundTrip(t *testing.T) {
+	s := newTestLocalStorage(t)
+	ctx := context.Background()
+
+	if err := s.Put(ctx, "files/1/book.epub", strings.NewReader("first"), -1, "application/epub+zip"); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	// Повторная запись заменяет объект
+	if err := s.Put(ctx, "files/1/book.epub", strings.NewReader("second"), 6, ""); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	r, err := s.Get(ctx, "files/1/book.epub")
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	data, _ := io.ReadAll(r)
+	r.Close()
+	if string(data) != "second" {
+		t.Errorf("Get = %q, want %q", data, "second")
+	}
+
+	if err := s.Delete(ctx, "files/1/book.epub"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := s.Get(ctx, "files/1/book.epub"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get after Delete: got %v, want ErrNotFound", err)
+	}
+	if err := s.Delete(ctx, "files/1/book.epub"); err != nil {
+		t.Errorf("Delete of missing object: %v", err)
+	}
+}
+
+func TestLocalStorageTraversalStaysInRoot(t *testing.T) {
+	s := newTestLocalStorage(t)
+	ctx := context.Background()
+	if err := s.Put(ctx, "../outside.txt", strings.NewReader("x"), 1, ""); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(filepath.Dir(s.Root), "outside.txt")); !os.IsNotExist(err) {
+		t.Errorf("object written outside root: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(s.Root, "outside.txt")); err != nil {
+		t.Errorf("object not written inside root: %v", err)
+	}
+}
+
+type failingReader struct{}
+
+func (failingReader) Read([]byte) (int, error) { return 0, errors.New("read failed") }
+
+func TestLocalStoragePutFailureLeavesNothing(t *testing.T) {
+	s := newTestLocalStorage(t)
+	ctx := context.Background()
+	if err := s.Put(ctx, "files/1/book.pdf", io.MultiReader(strings.NewReader("part"), failingReader{}), -1, ""); err == nil {
+		t.Fatal("expected error")
+	}
+	if _, err := s.Get(ctx, "files/1/book.pdf"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("partial object is visible: %v", err)
+	}
+	entries, _ := os.ReadDir(filepath.Join(s.Root, "files", "1"))
+	if len(entries) != 0 {
+		t.Errorf("temporary files left behind: %v", entries)
+	}
+}
+
+func TestLocalStorageURL(t *testing.T) {
+	s := newTestLocalStorage(t)
+	if got := s.URL("/covers/1.jpg"); got != "/uploads/covers/1.jpg" {
+		t.Errorf("URL = %s", got)
+	}
+}
@@ -0,0 +1,23 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+)
+
+// ErrNotFound возвращается, если объект с указанным ключом отсутствует.
+var ErrNotFound = errors.New("object not found")
+
+// Storage — хранилище бинарных объектов (обложки, файлы книг). Ключи имеют вид
+// относительного пути с разделителем «/», например "covers/12/original.jpg".
+type Storage interface {
+	// Put сохраняет объект. size может быть -1, если размер заранее неизвестен.
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	// Get открывает объект на чтение. Вызывающий обязан закрыть результат.
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete удаляет объект. Удаление отсутствующего объекта не считается ошибкой.
+	Delete(ctx context.Context, key string) error
+	// URL возвращает публичный адрес объекта.
+	URL(key string) string
+}
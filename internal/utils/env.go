@@ -0,0 +1,47 @@
+package utils
+
+import (
+	"os"
+	"strconv"
+	"time"
+)
+
+// GetEnv возвращает значение переменной окружения или значение по умолчанию, если она не задана.
+func GetEnv(key, fallback string) string {
+	if value, ok := os.LookupEnv(key); ok && value != "" {
+		return value
+	}
+	return fallback
+}
+
+// GetEnvInt возвращает целочисленную переменную окружения или значение по умолчанию.
+func GetEnvInt(key string, fallback int) int {
+	if value, err := strconv.Atoi(os.Getenv(key)); err == nil {
+		return value
+	}
+	return fallback
+}
+
+// GetEnvBool возвращает логическую переменную окружения или значение по умолчанию.
+func GetEnvBool(key string, fallback bool) bool {
+	if value, err := strconv.ParseBool(os.Getenv(key)); err == nil {
+		return value
+	}
+	return fallback
+}
+
+// GetEnvDuration возвращает длительность из переменной окружения (например, "720h") или значение по умолчанию.
+func GetEnvDuration(key string, fallback time.Duration) time.Duration {
+	if value, err := time.ParseDuration(os.Getenv(key)); err == nil {
+		return value
+	}
+	return fallback
+}
+
+// GetEnvFloat возвращает вещественную переменную окружения или значение по умолчанию.
+func GetEnvFloat(key string, fallback float64) float64 {
+	if value, err := strconv.ParseFloat(os.Getenv(key), 64); err == nil {
+		return value
+	}
+	return fallback
+}
@@ -4,6 +4,7 @@ import (
 	_ "Projectmugen/docs"
 	"Projectmugen/internal/controllers"
 	"Projectmugen/internal/services"
+	"Projectmugen/internal/storage"
 
 	"github.com/gin-gonic/gin"
 	httpSwagger "github.com/swaggo/http-swagger"
@@ -15,14 +16,42 @@ import (
 
 func main() {
 	services.InitDB()
+	services.InitStorage()
+	services.StartTrashPurger()
+	services.StartPriceScheduler()
+	services.StartAlsoBoughtJob()
+	services.StartUserRecommendationsJob()
+	services.StartCartPurger()
 	router := gin.Default()
 
+	// Локальное хранилище раздает загруженные файлы само
+	if local, ok := services.Storage.(*storage.LocalStorage); ok {
+		router.Static(local.URLPrefix, local.Root)
+	}
+
 	router.GET("/swagger/*any", gin.WrapF(httpSwagger.WrapHandler))
 
 	router.POST("/login", controllers.Login)
 	router.POST("/register", controllers.Register)
 	router.POST("/refresh", controllers.Refresh)
 
+	// Ссылки на скачивание подписаны и проверяются без токена авторизации
+	router.GET("/downloads/:fileId", controllers.DownloadBookFile)
+
+	// Анонимная корзина находится по токену из заголовка X-Cart-Token
+	guest := router.Group("/guest")
+	{
+		guest.GET("/cart", controllers.GetCart)
+
+		guest.DELETE("/cart", controllers.ClearCart)
+
+		guest.POST("/cart/items", controllers.AddCartItem)
+
+		guest.PUT("/cart/items/:bookId", controllers.UpdateCartItem)
+
+		guest.DELETE("/cart/items/:bookId", controllers.RemoveCartItem)
+	}
+
 	protected := router.Group("/")
 	protected.Use(controllers.AuthMiddleware())
 	{
@@ -32,16 +61,182 @@ func main() {
 
 		protected.GET("/books/year-range", controllers.GetBooksByYearRange)
 
+		protected.GET("/books/suggest", controllers.SuggestBooks)
+
+		protected.GET("/books/export", controllers.ExportBooks)
+
+		protected.GET("/books/isbn/:isbn", controllers.GetBookByISBN)
+
+		protected.GET("/books/trash", controllers.RoleMiddleware("admin"), controllers.GetDeletedBooks)
+
+		protected.POST("/books/:id/restore", controllers.RoleMiddleware("admin"), controllers.RestoreBook)
+
 		protected.GET("/books/count-by-author", controllers.CountBooksByAuthor)
 
-		protected.POST("/books/publisher", controllers.UpdateBooksPublisher)
+		protected.POST("/orders", controllers.CreateOrder)
+
+		protected.GET("/orders/:id", controllers.GetOrder)
+
+		protected.POST("/orders/:id/cancel", controllers.CancelOrder)
+
+		protected.POST("/orders/:id/status", controllers.RoleMiddleware("admin"), controllers.UpdateOrderStatus)
+
+		protected.GET("/orders/:id/history", controllers.GetOrderHistory)
+
+		protected.GET("/cart", controllers.GetCart)
+
+		protected.DELETE("/cart", controllers.ClearCart)
+
+		protected.POST("/cart/items", controllers.AddCartItem)
+
+		protected.PUT("/cart/items/:bookId", controllers.UpdateCartItem)
+
+		protected.DELETE("/cart/items/:bookId", controllers.RemoveCartItem)
+
+		protected.POST("/cart/checkout", controllers.CheckoutCart)
+
+		protected.GET("/stats/books/by-year", controllers.GetBooksByPeriodStats)
+
+		protected.GET("/stats/books/by-publisher", controllers.GetBooksByPublisherStats)
+
+		protected.GET("/stats/books/by-category", controllers.GetBooksByCategoryStats)
+
+		protected.GET("/stats/books/new-arrivals", controllers.GetNewArrivalsStats)
+
+		protected.GET("/stats/authors/ratings", controllers.GetAuthorRatingStats)
+
+		protected.POST("/books/publisher", controllers.RoleMiddleware("admin"), controllers.UpdateBooksPublisher)
 
 		protected.POST("/books", controllers.RoleMiddleware("admin"), controllers.CreateBook)
 
+		protected.POST("/books/import", controllers.RoleMiddleware("admin"), controllers.ImportBooks)
+
 		protected.PUT("/books/:id", controllers.RoleMiddleware("admin"), controllers.UpdateBook)
 
+		protected.PATCH("/books/:id", controllers.RoleMiddleware("admin"), controllers.PatchBook)
+
 		protected.DELETE("/books/:id", controllers.RoleMiddleware("admin"), controllers.DeleteBook)
 
+		protected.GET("/books/:id/history", controllers.RoleMiddleware("admin"), controllers.GetBookHistory)
+
+		protected.POST("/books/:id/history/:revision/rollback", controllers.RoleMiddleware("admin"), controllers.RollbackBook)
+
+		protected.POST("/books/:id/stock", controllers.RoleMiddleware("admin"), controllers.AdjustBookStock)
+
+		protected.GET("/books/:id/stock/movements", controllers.RoleMiddleware("admin"), controllers.GetStockMovements)
+
+		protected.GET("/books/:id/prices", controllers.GetBookPrices)
+
+		protected.POST("/books/:id/prices", controllers.RoleMiddleware("admin"), controllers.CreateBookPrice)
+
+		protected.DELETE("/books/:id/prices/:priceId", controllers.RoleMiddleware("admin"), controllers.DeleteBookPrice)
+
+		protected.POST("/books/:id/files", controllers.RoleMiddleware("admin"), controllers.UploadBookFile)
+
+		protected.DELETE("/books/:id/files/:fileId", controllers.RoleMiddleware("admin"), controllers.DeleteBookFile)
+
+		protected.GET("/books/:id/files/:fileId/link", controllers.GetBookDownloadLink)
+
+		protected.GET("/books/:id/downloads", controllers.RoleMiddleware("admin"), controllers.GetBookDownloadStats)
+
+		protected.POST("/books/:id/entitlements", controllers.RoleMiddleware("admin"), controllers.GrantBookAccess)
+
+		protected.DELETE("/books/:id/entitlements/:username", controllers.RoleMiddleware("admin"), controllers.RevokeBookAccess)
+
+		protected.GET("/books/:id/also-bought", controllers.GetAlsoBought)
+
+		protected.PUT("/books/:id/authors", controllers.RoleMiddleware("admin"), controllers.SetBookAuthors)
+
+		protected.POST("/books/:id/cover", controllers.RoleMiddleware("admin"), controllers.UploadBookCover)
+
+		protected.DELETE("/books/:id/cover", controllers.RoleMiddleware("admin"), controllers.DeleteBookCover)
+
+		protected.GET("/me/recommendations", controllers.GetMyRecommendations)
+
+		protected.GET("/me/orders", controllers.GetMyOrders)
+
+		protected.GET("/admin/orders", controllers.RoleMiddleware("admin"), controllers.GetAdminOrders)
+
+		protected.GET("/me/wishlist", controllers.GetWishlist)
+
+		protected.PUT("/me/wishlist/:bookId", controllers.AddToWishlist)
+
+		protected.DELETE("/me/wishlist/:bookId", controllers.RemoveFromWishlist)
+
+		protected.GET("/categories", controllers.GetCategories)
+
+		protected.POST("/categories", controllers.RoleMiddleware("admin"), controllers.CreateCategory)
+
+		protected.PUT("/categories/:id", controllers.RoleMiddleware("admin"), controllers.UpdateCategory)
+
+		protected.DELETE("/categories/:id", controllers.RoleMiddleware("admin"), controllers.DeleteCategory)
+
+		protected.GET("/works", controllers.GetWorks)
+
+		protected.POST("/works", controllers.RoleMiddleware("admin"), controllers.CreateWork)
+
+		protected.PUT("/works/:id", controllers.RoleMiddleware("admin"), controllers.UpdateWork)
+
+		protected.DELETE("/works/:id", controllers.RoleMiddleware("admin"), controllers.DeleteWork)
+
+		protected.GET("/works/:id/editions", controllers.GetWorkEditions)
+
+		protected.GET("/series", controllers.GetSeries)
+
+		protected.POST("/series", controllers.RoleMiddleware("admin"), controllers.CreateSeries)
+
+		protected.PUT("/series/:id", controllers.RoleMiddleware("admin"), controllers.UpdateSeries)
+
+		protected.DELETE("/series/:id", controllers.RoleMiddleware("admin"), controllers.DeleteSeries)
+
+		protected.GET("/series/:id/books", controllers.GetSeriesBooks)
+
+		protected.POST("/books/:id/tags", controllers.RoleMiddleware("admin"), controllers.AddBookTags)
+
+		protected.DELETE("/books/:id/tags/:tag", controllers.RoleMiddleware("admin"), controllers.RemoveBookTag)
+
+		protected.GET("/books/:id/translations", controllers.GetBookTranslations)
+
+		protected.PUT("/books/:id/translations/:locale", controllers.RoleMiddleware("admin"), controllers.SetBookTranslation)
+
+		protected.DELETE("/books/:id/translations/:locale", controllers.RoleMiddleware("admin"), controllers.DeleteBookTranslation)
+
+		protected.GET("/tags", controllers.GetTagCloud)
+
+		protected.PUT("/tags/:id", controllers.RoleMiddleware("admin"), controllers.RenameTag)
+
+		protected.POST("/tags/:id/merge", controllers.RoleMiddleware("admin"), controllers.MergeTag)
+
+		protected.DELETE("/tags/:id", controllers.RoleMiddleware("admin"), controllers.DeleteTag)
+
+		protected.GET("/currencies", controllers.GetCurrencyRates)
+
+		protected.PUT("/currencies/:code", controllers.RoleMiddleware("admin"), controllers.SetCurrencyRate)
+
+		protected.DELETE("/currencies/:code", controllers.RoleMiddleware("admin"), controllers.DeleteCurrencyRate)
+
+		protected.POST("/currencies/import", controllers.RoleMiddleware("admin"), controllers.ImportCurrencyRates)
+
+		protected.GET("/authors", controllers.GetAuthors)
+
+		protected.GET("/authors/:id", controllers.GetAuthorByID)
+
+		protected.POST("/authors", controllers.RoleMiddleware("admin"), controllers.CreateAuthor)
+
+		protected.PUT("/authors/:id", controllers.RoleMiddleware("admin"), controllers.UpdateAuthor)
+
+		protected.DELETE("/authors/:id", controllers.RoleMiddleware("admin"), controllers.DeleteAuthor)
+
+		protected.GET("/publishers", controllers.GetPublishers)
+
+		protected.GET("/publishers/:id", controllers.GetPublisherByID)
+
+		protected.POST("/publishers", controllers.RoleMiddleware("admin"), controllers.CreatePublisher)
+
+		protected.PUT("/publishers/:id", controllers.RoleMiddleware("admin"), controllers.UpdatePublisher)
+
+		protected.DELETE("/publishers/:id", controllers.RoleMiddleware("admin"), controllers.DeletePublisher)
+
 	}
 	router.Run(":8080")
 }